@@ -0,0 +1,233 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEngineRunning is returned by Run when the run loop is already started
+var ErrEngineRunning = errors.New("Engine run loop is already running")
+
+// commandQueueSize is the buffer size of the channel returned by Commands
+const commandQueueSize = 1024
+
+// Command is the mutating engine command executed by the run loop, see Run.
+// Implemented by PlaceCommand, CancelCommand and ReplaceCommand
+type Command interface {
+	execute(run context.Context, e commandEngine)
+
+	// reject replies with the error without executing the command
+	reject(err error)
+}
+
+// commandEngine executes commands, it's implemented by Engine and
+// FencedEngine
+type commandEngine interface {
+	PlaceOrderReport(context.Context, EventListener, Order) (Report, error)
+	CancelOrderByID(context.Context, EventListener, string) error
+	ReplaceOrder(context.Context, EventListener, Order, Order) ([]Fill, error)
+	FindOrder(string) (Order, error)
+}
+
+// PlaceCommand places the order as PlaceOrderReport does
+type PlaceCommand struct {
+	Ctx      context.Context
+	Listener EventListener
+	Order    Order
+
+	// Reply receives the result, nil skips it
+	Reply chan<- PlaceResult
+}
+
+// PlaceResult is the result of PlaceCommand
+type PlaceResult struct {
+	Report Report
+	Err    error
+}
+
+func (c PlaceCommand) execute(run context.Context, e commandEngine) {
+	r, err := e.PlaceOrderReport(commandContext(c.Ctx), c.Listener, c.Order)
+	if c.Reply != nil {
+		select {
+		case c.Reply <- PlaceResult{Report: r, Err: err}:
+		case <-run.Done():
+		}
+	}
+}
+
+func (c PlaceCommand) reject(err error) {
+	if c.Reply != nil {
+		select {
+		case c.Reply <- PlaceResult{Err: err}:
+		default:
+		}
+	}
+}
+
+// CancelCommand cancels resting order with given ID as CancelOrderByID does
+type CancelCommand struct {
+	Ctx      context.Context
+	Listener EventListener
+	OrderID  string
+
+	// Reply receives the result, nil skips it
+	Reply chan<- error
+}
+
+func (c CancelCommand) execute(run context.Context, e commandEngine) {
+	err := e.CancelOrderByID(commandContext(c.Ctx), c.Listener, c.OrderID)
+	if c.Reply != nil {
+		select {
+		case c.Reply <- err:
+		case <-run.Done():
+		}
+	}
+}
+
+func (c CancelCommand) reject(err error) {
+	if c.Reply != nil {
+		select {
+		case c.Reply <- err:
+		default:
+		}
+	}
+}
+
+// ReplaceCommand replaces resting order with given ID by the order as
+// ReplaceOrder does
+type ReplaceCommand struct {
+	Ctx      context.Context
+	Listener EventListener
+	OrderID  string
+	Order    Order
+
+	// Reply receives the result, nil skips it
+	Reply chan<- ReplaceResult
+}
+
+// ReplaceResult is the result of ReplaceCommand
+type ReplaceResult struct {
+	Fills []Fill
+	Err   error
+}
+
+func (c ReplaceCommand) execute(run context.Context, e commandEngine) {
+	var (
+		fills []Fill
+		ctx   = commandContext(c.Ctx)
+	)
+
+	o, err := e.FindOrder(c.OrderID)
+	if err == nil {
+		fills, err = e.ReplaceOrder(ctx, c.Listener, o, c.Order)
+	}
+
+	if c.Reply != nil {
+		select {
+		case c.Reply <- ReplaceResult{Fills: fills, Err: err}:
+		case <-run.Done():
+		}
+	}
+}
+
+func (c ReplaceCommand) reject(err error) {
+	if c.Reply != nil {
+		select {
+		case c.Reply <- ReplaceResult{Err: err}:
+		default:
+		}
+	}
+}
+
+func commandContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// Commands returns the channel consumed by Run
+func (e *Engine) Commands() chan<- Command {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return e.commandQueue()
+}
+
+func (e *Engine) commandQueue() chan Command {
+	if e.commands == nil {
+		e.commands = make(chan Command, commandQueueSize)
+	}
+	return e.commands
+}
+
+// Run executes commands sent to the Commands channel one by one until the
+// context is done or the engine is closed. The single writer makes the order
+// of executions equal to the order of commands in the channel, so the
+// channel could be fed by replicated log. Read-only methods may be called
+// concurrently, mutating methods called directly bypass the ordering.
+// Commands left in the channel when Run returns are rejected with ctx.Err()
+// or ErrEngineClosed. Rejections are not waited for, so reply channels
+// should be buffered
+func (e *Engine) Run(ctx context.Context) error {
+	return e.run(ctx, e)
+}
+
+// run executes commands by the target wrapping the engine
+func (e *Engine) run(ctx context.Context, target commandEngine) error {
+	e.m.Lock()
+	if e.closed {
+		e.m.Unlock()
+		return ErrEngineClosed
+	}
+
+	if e.running {
+		e.m.Unlock()
+		return ErrEngineRunning
+	}
+
+	e.running = true
+	commands := e.commandQueue()
+	done := e.closing()
+	e.m.Unlock()
+
+	defer func() {
+		e.m.Lock()
+		e.running = false
+		e.m.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			rejectCommands(commands, ctx.Err())
+			return ctx.Err()
+
+		case <-done:
+			rejectCommands(commands, ErrEngineClosed)
+			return nil
+
+		case cmd := <-commands:
+			// The command may be selected after cancellation
+			if err := ctx.Err(); err != nil {
+				cmd.reject(err)
+				rejectCommands(commands, err)
+				return err
+			}
+
+			cmd.execute(ctx, target)
+		}
+	}
+}
+
+// rejectCommands rejects commands queued in the channel with the error
+func rejectCommands(commands chan Command, err error) {
+	for {
+		select {
+		case cmd := <-commands:
+			cmd.reject(err)
+		default:
+			return
+		}
+	}
+}
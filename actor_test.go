@@ -0,0 +1,106 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine      = NewEngine(asset1, asset2)
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan error)
+		places      = make(chan PlaceResult, 3)
+		replaces    = make(chan ReplaceResult, 1)
+		cancels     = make(chan error, 1)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 20)
+
+	go func() { done <- engine.Run(ctx) }()
+
+	commands := engine.Commands()
+	commands <- PlaceCommand{Order: newOrder("1", wallet1, true, 2, 10), Reply: places}
+	commands <- PlaceCommand{Order: newOrder("2", wallet1, true, 1, 11)}
+	commands <- ReplaceCommand{OrderID: "2", Order: newOrder("3", wallet1, true, 1, 12), Reply: replaces}
+	commands <- PlaceCommand{Order: newOrder("4", wallet2, false, 1, 10), Reply: places}
+	commands <- CancelCommand{OrderID: "1", Reply: cancels}
+	commands <- CancelCommand{OrderID: "1", Reply: cancels}
+
+	if r := <-places; r.Err != nil || r.Report.Status != StatusPlaced {
+		t.Fatal("order must be placed", r)
+	}
+
+	if r := <-replaces; r.Err != nil || len(r.Fills) != 0 {
+		t.Fatal("order must be replaced", r)
+	}
+
+	if r := <-places; r.Err != nil || r.Report.Status != StatusFilled {
+		t.Fatal("order must be filled", r)
+	}
+
+	if err := <-cancels; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-cancels; err != ErrOrderNotFound {
+		t.Fatal("canceled order must not be found", err)
+	}
+
+	if err := engine.Run(ctx); err != ErrEngineRunning {
+		t.Fatal("the second run loop must be rejected", err)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatal("run loop must stop on context cancellation", err)
+	}
+
+	if orders := engine.Orders(); len(orders) != 1 || orders[0].ID() != "3" {
+		t.Fatal("invalid order book", orders)
+	}
+}
+
+func TestRunRejectQueued(t *testing.T) {
+	var (
+		wallet      = newWallet()
+		engine      = NewEngine("apples", "dollars")
+		ctx, cancel = context.WithCancel(context.Background())
+		places      = make(chan PlaceResult, 1)
+		replaces    = make(chan ReplaceResult, 1)
+		cancels     = make(chan error, 1)
+	)
+
+	updateWalletBalance(wallet, "apples", 2)
+
+	commands := engine.Commands()
+	commands <- PlaceCommand{Order: newOrder("1", wallet, true, 1, 10), Reply: places}
+	commands <- ReplaceCommand{OrderID: "1", Order: newOrder("2", wallet, true, 1, 11), Reply: replaces}
+	commands <- CancelCommand{OrderID: "1", Reply: cancels}
+
+	// Commands queued on cancellation are replied without execution
+	cancel()
+	if err := engine.Run(ctx); err != context.Canceled {
+		t.Fatal("run loop must stop on context cancellation", err)
+	}
+
+	if r := <-places; r.Err != context.Canceled {
+		t.Fatal("queued place must be rejected", r)
+	}
+
+	if r := <-replaces; r.Err != context.Canceled {
+		t.Fatal("queued replace must be rejected", r)
+	}
+
+	if err := <-cancels; err != context.Canceled {
+		t.Fatal("queued cancel must be rejected", err)
+	}
+
+	if orders := engine.Orders(); len(orders) != 0 {
+		t.Fatal("rejected commands must not be executed", orders)
+	}
+}
@@ -0,0 +1,304 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidOp is returned by Apply for ops of unknown type or without the order
+var ErrInvalidOp = errors.New("Invalid op")
+
+// Op types
+const (
+	OpPlace   = "place"
+	OpCancel  = "cancel"
+	OpReplace = "replace"
+)
+
+// Op is the serializable command applied by Apply, e.g. the entry of the
+// replicated log committed by the consensus
+type Op struct {
+	Type string `json:"type"`
+
+	// ID is the resting order ID for OpCancel and OpReplace
+	ID string `json:"id,omitempty"`
+
+	// Order is the new order for OpPlace and OpReplace
+	Order *OpOrder `json:"order,omitempty"`
+
+	// Time is the event time of the op, the engine clock is used if zero.
+	// Set it by the leader, so replicas agree on time based decisions
+	Time time.Time `json:"time,omitempty"`
+}
+
+// OpOrder describes the order of the op. Values are rendered by the engine
+// Formatter and parsed by the OrderFactory
+type OpOrder struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id,omitempty"`
+	Sell     bool   `json:"sell"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// EventType identifies the event returned by Apply
+type EventType string
+
+// Event types, named after EventListener methods and its extensions
+const (
+	EventIncomingPartial     EventType = "incoming_partial"
+	EventIncomingDone        EventType = "incoming_done"
+	EventIncomingPlaced      EventType = "incoming_placed"
+	EventIncomingCanceled    EventType = "incoming_canceled"
+	EventExistingPartial     EventType = "existing_partial"
+	EventExistingDone        EventType = "existing_done"
+	EventExistingCanceled    EventType = "existing_canceled"
+	EventExistingExpired     EventType = "existing_expired"
+	EventExistingUnderfunded EventType = "existing_underfunded"
+	EventExistingReduced     EventType = "existing_reduced"
+	EventRejected            EventType = "rejected"
+	EventBalance             EventType = "balance"
+	EventInOrder             EventType = "in_order"
+	EventTrade               EventType = "trade"
+	EventFee                 EventType = "fee"
+	EventRebate              EventType = "rebate"
+	EventState               EventType = "state"
+	EventProtection          EventType = "protection"
+)
+
+// Event is the engine event returned by Apply. Only fields related to the
+// event type are set: Order and Volume for order events, Value for the
+// reduced quantity, Wallet, Asset and Value for balance and rebate events,
+// From and To for the trading state change. Orders are read-only views taken
+// at the emission time, see ViewOf
+type Event struct {
+	Type EventType
+
+	Order  Order
+	Volume Volume
+	Trade  Trade
+	Fee    Fee
+
+	Wallet Wallet
+	Asset  Asset
+	Value  Value
+
+	From TradingState
+	To   TradingState
+
+	Err error
+}
+
+// Apply executes the op and returns emitted events in the emission order
+// instead of delivering them to the listener, so the engine could be used as
+// the deterministic state machine behind the consensus or the sequencer.
+// Orders are created by the factory. The error is the command error, e.g.
+// ErrOrderNotFound or ErrInsufficientFunds, events of the rejected command
+// are returned too
+func (e *Engine) Apply(ctx context.Context, factory OrderFactory, op Op) ([]Event, error) {
+	if !op.Time.IsZero() {
+		ctx = WithEventTime(ctx, op.Time)
+	}
+
+	var (
+		o        Order
+		err      error
+		recorder eventRecorder
+	)
+
+	if op.Order != nil {
+		if o, err = (*journalOrder)(op.Order).order(ctx, factory); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case op.Type == OpPlace && o != nil:
+		err = e.PlaceOrder(ctx, &recorder, o)
+
+	case op.Type == OpCancel:
+		err = e.CancelOrderByID(ctx, &recorder, op.ID)
+
+	case op.Type == OpReplace && o != nil:
+		var resting Order
+		if resting, err = e.FindOrder(op.ID); err == nil {
+			_, err = e.ReplaceOrder(ctx, &recorder, resting, o)
+		}
+
+	default:
+		return nil, ErrInvalidOp
+	}
+
+	return recorder.events, err
+}
+
+// eventRecorder is the EventListener collecting events for Apply and
+// PlaceOrders. Contexts of events are kept for the delivery by flush
+type eventRecorder struct {
+	events   []Event
+	contexts []context.Context
+}
+
+// add records the event. Orders are copied, live orders are changed by
+// following matching before the event is read
+func (r *eventRecorder) add(ctx context.Context, ev Event) {
+	if ev.Order != nil {
+		ev.Order = ViewOf(ev.Order)
+	}
+
+	if ev.Trade.MakerOrder != nil {
+		ev.Trade.MakerOrder = ViewOf(ev.Trade.MakerOrder)
+	}
+
+	if ev.Trade.TakerOrder != nil {
+		ev.Trade.TakerOrder = ViewOf(ev.Trade.TakerOrder)
+	}
+
+	r.events = append(r.events, ev)
+	r.contexts = append(r.contexts, ctx)
+}
+
+// flush delivers collected events to the listener in the emission order.
+// Events of optional extensions are delivered if the listener implements them
+func (r *eventRecorder) flush(listener EventListener) {
+	for i, ev := range r.events {
+		r.deliver(r.contexts[i], listener, ev)
+	}
+
+	r.events, r.contexts = nil, nil
+}
+
+func (r *eventRecorder) deliver(ctx context.Context, listener EventListener, ev Event) {
+	switch ev.Type {
+	case EventIncomingPartial:
+		listener.OnIncomingOrderPartial(ctx, ev.Order, ev.Volume)
+	case EventIncomingDone:
+		listener.OnIncomingOrderDone(ctx, ev.Order, ev.Volume)
+	case EventIncomingPlaced:
+		listener.OnIncomingOrderPlaced(ctx, ev.Order)
+	case EventExistingPartial:
+		listener.OnExistingOrderPartial(ctx, ev.Order, ev.Volume)
+	case EventExistingDone:
+		listener.OnExistingOrderDone(ctx, ev.Order, ev.Volume)
+	case EventExistingCanceled:
+		listener.OnExistingOrderCanceled(ctx, ev.Order)
+	case EventBalance:
+		listener.OnBalanceChanged(ctx, ev.Wallet, ev.Asset, ev.Value)
+	case EventInOrder:
+		listener.OnInOrderChanged(ctx, ev.Wallet, ev.Asset, ev.Value)
+
+	case EventIncomingCanceled:
+		if l, ok := listener.(IncomingCanceledListener); ok {
+			l.OnIncomingOrderCanceled(ctx, ev.Order)
+		}
+	case EventExistingExpired:
+		if l, ok := listener.(ExpiredListener); ok {
+			l.OnExistingOrderExpired(ctx, ev.Order)
+		}
+	case EventExistingUnderfunded:
+		if l, ok := listener.(UnderfundedListener); ok {
+			l.OnExistingOrderUnderfunded(ctx, ev.Order)
+		}
+	case EventExistingReduced:
+		if l, ok := listener.(ReducedListener); ok {
+			l.OnExistingOrderReduced(ctx, ev.Order, ev.Value)
+		}
+	case EventRejected:
+		if l, ok := listener.(RejectedListener); ok {
+			l.OnOrderRejected(ctx, ev.Order, ev.Err)
+		}
+	case EventTrade:
+		if l, ok := listener.(TradeListener); ok {
+			l.OnTrade(ctx, ev.Trade)
+		}
+	case EventFee:
+		if l, ok := listener.(FeeListener); ok {
+			l.OnFeeCharged(ctx, ev.Order, ev.Fee)
+		}
+	case EventRebate:
+		if l, ok := listener.(RebateListener); ok {
+			l.OnRebatePaid(ctx, ev.Order, ev.Asset, ev.Value)
+		}
+	case EventState:
+		if l, ok := listener.(StateListener); ok {
+			l.OnTradingStateChanged(ctx, ev.From, ev.To)
+		}
+	case EventProtection:
+		if l, ok := listener.(ProtectionListener); ok {
+			l.OnMakerProtectionTriggered(ctx, ev.Wallet)
+		}
+	}
+}
+
+func (r *eventRecorder) OnIncomingOrderPartial(ctx context.Context, o Order, v Volume) {
+	r.add(ctx, Event{Type: EventIncomingPartial, Order: o, Volume: v})
+}
+
+func (r *eventRecorder) OnIncomingOrderDone(ctx context.Context, o Order, v Volume) {
+	r.add(ctx, Event{Type: EventIncomingDone, Order: o, Volume: v})
+}
+
+func (r *eventRecorder) OnIncomingOrderPlaced(ctx context.Context, o Order) {
+	r.add(ctx, Event{Type: EventIncomingPlaced, Order: o})
+}
+
+func (r *eventRecorder) OnIncomingOrderCanceled(ctx context.Context, o Order) {
+	r.add(ctx, Event{Type: EventIncomingCanceled, Order: o})
+}
+
+func (r *eventRecorder) OnExistingOrderPartial(ctx context.Context, o Order, v Volume) {
+	r.add(ctx, Event{Type: EventExistingPartial, Order: o, Volume: v})
+}
+
+func (r *eventRecorder) OnExistingOrderDone(ctx context.Context, o Order, v Volume) {
+	r.add(ctx, Event{Type: EventExistingDone, Order: o, Volume: v})
+}
+
+func (r *eventRecorder) OnExistingOrderCanceled(ctx context.Context, o Order) {
+	r.add(ctx, Event{Type: EventExistingCanceled, Order: o})
+}
+
+func (r *eventRecorder) OnExistingOrderExpired(ctx context.Context, o Order) {
+	r.add(ctx, Event{Type: EventExistingExpired, Order: o})
+}
+
+func (r *eventRecorder) OnExistingOrderUnderfunded(ctx context.Context, o Order) {
+	r.add(ctx, Event{Type: EventExistingUnderfunded, Order: o})
+}
+
+func (r *eventRecorder) OnExistingOrderReduced(ctx context.Context, o Order, reduced Value) {
+	r.add(ctx, Event{Type: EventExistingReduced, Order: o, Value: reduced})
+}
+
+func (r *eventRecorder) OnOrderRejected(ctx context.Context, o Order, err error) {
+	r.add(ctx, Event{Type: EventRejected, Order: o, Err: err})
+}
+
+func (r *eventRecorder) OnBalanceChanged(ctx context.Context, w Wallet, a Asset, v Value) {
+	r.add(ctx, Event{Type: EventBalance, Wallet: w, Asset: a, Value: v})
+}
+
+func (r *eventRecorder) OnInOrderChanged(ctx context.Context, w Wallet, a Asset, v Value) {
+	r.add(ctx, Event{Type: EventInOrder, Wallet: w, Asset: a, Value: v})
+}
+
+func (r *eventRecorder) OnTrade(ctx context.Context, t Trade) {
+	r.add(ctx, Event{Type: EventTrade, Trade: t})
+}
+
+func (r *eventRecorder) OnFeeCharged(ctx context.Context, o Order, f Fee) {
+	r.add(ctx, Event{Type: EventFee, Order: o, Fee: f})
+}
+
+func (r *eventRecorder) OnRebatePaid(ctx context.Context, o Order, a Asset, v Value) {
+	r.add(ctx, Event{Type: EventRebate, Order: o, Wallet: o.Owner(), Asset: a, Value: v})
+}
+
+func (r *eventRecorder) OnTradingStateChanged(ctx context.Context, from, to TradingState) {
+	r.add(ctx, Event{Type: EventState, From: from, To: to})
+}
+
+func (r *eventRecorder) OnMakerProtectionTriggered(ctx context.Context, w Wallet) {
+	r.add(ctx, Event{Type: EventProtection, Wallet: w})
+}
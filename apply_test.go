@@ -0,0 +1,138 @@
+package fastme
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		at             = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		ops            = []string{
+			`{"type":"place","order":{"id":"1","sell":true,"price":"10","quantity":"2"},"time":"2020-01-01T00:00:00Z"}`,
+			`{"type":"place","order":{"id":"2","sell":true,"price":"11","quantity":"1"}}`,
+			`{"type":"replace","id":"2","order":{"id":"3","sell":true,"price":"12","quantity":"1"}}`,
+			`{"type":"place","order":{"id":"4","sell":false,"price":"10","quantity":"1"},"time":"2020-01-01T00:00:00Z"}`,
+			`{"type":"cancel","id":"1"}`,
+		}
+	)
+
+	run := func() (*Engine, [][]Event) {
+		wallet1, wallet2 := newWallet(), newWallet()
+		updateWalletBalance(wallet1, asset1, 10)
+		updateWalletBalance(wallet2, asset2, 100)
+
+		var (
+			engine  = NewEngine(asset1, asset2)
+			factory = &tOrderFactory{owners: map[string]*tWallet{
+				"1": wallet1, "2": wallet1, "3": wallet1, "4": wallet2,
+			}}
+			events [][]Event
+		)
+
+		for _, data := range ops {
+			var op Op
+			assertErr(t, json.Unmarshal([]byte(data), &op))
+
+			evs, err := engine.Apply(context.Background(), factory, op)
+			assertErr(t, err)
+			events = append(events, evs)
+		}
+
+		return engine, events
+	}
+
+	engine1, events1 := run()
+	engine2, events2 := run()
+
+	if engine1.StateHash() != engine2.StateHash() {
+		t.Fatal("replicas must not diverge")
+	}
+
+	for i := range events1 {
+		if len(events1[i]) != len(events2[i]) {
+			t.Fatal("replicas must return the same events", events1[i], events2[i])
+		}
+
+		for j := range events1[i] {
+			if events1[i][j].Type != events2[i][j].Type {
+				t.Fatal("events must be returned in the same order", events1[i], events2[i])
+			}
+		}
+	}
+
+	var trade *Trade
+	for _, ev := range events1[3] {
+		if ev.Type == EventTrade {
+			trade = &ev.Trade
+		}
+	}
+
+	if trade == nil || trade.MakerOrder.ID() != "1" || !trade.Timestamp.Equal(at) {
+		t.Fatal("trade must be returned at the op time", events1[3])
+	}
+
+	if evs := events1[4]; len(evs) == 0 || evs[len(evs)-1].Type != EventExistingCanceled {
+		t.Fatal("cancellation must be returned", evs)
+	}
+
+	if _, err := engine1.Apply(context.Background(), nil, Op{Type: OpCancel, ID: "1"}); err != ErrOrderNotFound {
+		t.Fatal("command error must be returned", err)
+	}
+
+	if _, err := engine1.Apply(context.Background(), nil, Op{Type: OpPlace}); !errors.Is(err, ErrInvalidOp) {
+		t.Fatal("op without order must be rejected", err)
+	}
+}
+
+func TestApplyEventOrders(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+		factory          = &tOrderFactory{owners: map[string]*tWallet{"1": wallet1, "2": wallet2}}
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	placed, err := engine.Apply(context.Background(), factory, Op{
+		Type:  OpPlace,
+		Order: &OpOrder{ID: "1", Sell: true, Price: "10", Quantity: "3"},
+	})
+	assertErr(t, err)
+
+	matched, err := engine.Apply(context.Background(), factory, Op{
+		Type:  OpPlace,
+		Order: &OpOrder{ID: "2", Price: "10", Quantity: "2"},
+	})
+	assertErr(t, err)
+
+	// Events keep quantities of the emission time after the order is matched
+	quantity := func(evs []Event, typ EventType) Value {
+		for _, ev := range evs {
+			if ev.Type == typ {
+				return ev.Order.Quantity()
+			}
+		}
+		return nil
+	}
+
+	if q := quantity(placed, EventIncomingPlaced); q != tFloat64(3) {
+		t.Fatal("placed order must keep its quantity", q)
+	}
+
+	if q := quantity(matched, EventExistingPartial); q != tFloat64(1) {
+		t.Fatal("partially filled order must keep its remainder", q)
+	}
+
+	for _, ev := range matched {
+		if ev.Type == EventTrade && ev.Trade.MakerOrder.Quantity() != tFloat64(1) {
+			t.Fatal("trade must keep the maker remainder", ev.Trade)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package fastme
+
+import (
+	"container/list"
+	"context"
+	"time"
+)
+
+// Archive configures retention of completed orders. Orders leaving the order
+// book (filled or canceled) are kept in the archive to answer what happened
+// to them. Zero fields disable the corresponding limit
+type Archive struct {
+	// Size is the maximum number of archived orders, the oldest are evicted first
+	Size int
+
+	// TTL is the maximum age of archived orders
+	TTL time.Duration
+}
+
+// ArchivedOrder describes completed order
+type ArchivedOrder struct {
+	Order Order
+
+	// Status is StatusFilled or StatusCanceled
+	Status OrderStatus
+
+	// At is the completion time
+	At time.Time
+}
+
+type archive struct {
+	Archive
+
+	entries *list.List               // *list.Element.Value.(ArchivedOrder), oldest first
+	byID    map[string]*list.Element // OrderID() -> *list.Element
+}
+
+// SetArchive enables retention of completed orders with given limits. Passing
+// nil disables the archive and drops archived orders
+func (e *Engine) SetArchive(a *Archive) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if a == nil {
+		e.archived = nil
+		return
+	}
+
+	if e.archived == nil {
+		e.archived = &archive{
+			entries: list.New(),
+			byID:    make(map[string]*list.Element),
+		}
+	}
+
+	e.archived.Archive = *a
+	e.archived.evict(e.now())
+}
+
+// FindArchived returns completed order by given ID or ErrOrderNotFound if
+// the order is not archived
+func (e *Engine) FindArchived(id string) (ArchivedOrder, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.archived == nil {
+		return ArchivedOrder{}, ErrOrderNotFound
+	}
+
+	e.archived.evict(e.now())
+
+	el, ok := e.archived.byID[id]
+	if !ok {
+		return ArchivedOrder{}, ErrOrderNotFound
+	}
+
+	return el.Value.(ArchivedOrder), nil
+}
+
+// ArchivedOrders returns completed orders of the wallet, oldest first
+func (e *Engine) ArchivedOrders(w Wallet) (orders []ArchivedOrder) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.archived == nil {
+		return nil
+	}
+
+	e.archived.evict(e.now())
+
+	for el := e.archived.entries.Front(); el != nil; el = el.Next() {
+		if a := el.Value.(ArchivedOrder); a.Order.Owner() == w {
+			orders = append(orders, a)
+		}
+	}
+
+	return
+}
+
+// archive puts completed order to the archive if enabled
+func (e *Engine) archive(ctx context.Context, o Order, status OrderStatus) {
+	if e.archived == nil {
+		return
+	}
+
+	now := e.eventTime(ctx)
+
+	if el, ok := e.archived.byID[o.ID()]; ok {
+		e.archived.entries.Remove(el)
+	}
+
+	e.archived.byID[o.ID()] = e.archived.entries.PushBack(ArchivedOrder{
+		Order:  o,
+		Status: status,
+		At:     now,
+	})
+
+	e.archived.evict(now)
+}
+
+// evict removes orders exceeding size and age limits
+func (a *archive) evict(now time.Time) {
+	for el := a.entries.Front(); el != nil; el = a.entries.Front() {
+		entry := el.Value.(ArchivedOrder)
+
+		if (a.Size <= 0 || a.entries.Len() <= a.Size) &&
+			(a.TTL <= 0 || now.Sub(entry.At) <= a.TTL) {
+			return
+		}
+
+		a.entries.Remove(el)
+		delete(a.byID, entry.Order.ID())
+	}
+}
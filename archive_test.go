@@ -0,0 +1,72 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	var (
+		processor        = newEventListener()
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	engine.SetArchive(&Archive{Size: 3})
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet2, false, 1, 10)))
+	assertErr(t, engine.CancelOrderByID(context.Background(), processor, "2"))
+
+	if _, err := engine.FindOrder("1"); err != ErrOrderNotFound {
+		t.Fatal("filled order must leave the order book")
+	}
+
+	a, err := engine.FindArchived("1")
+	assertErr(t, err)
+
+	if a.Order.ID() != "1" || a.Status != StatusFilled || a.At.IsZero() {
+		t.Fatal("invalid archived order", a)
+	}
+
+	if a, _ := engine.FindArchived("2"); a.Status != StatusCanceled {
+		t.Fatal("invalid archived order", a)
+	}
+
+	if orders := engine.ArchivedOrders(wallet1); len(orders) != 2 ||
+		orders[0].Order.ID() != "1" ||
+		orders[1].Order.ID() != "2" {
+		t.Fatal("invalid archived orders", orders)
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet1, true, 1, 20)))
+	assertErr(t, engine.CancelOrderByID(context.Background(), processor, "4"))
+
+	if _, err := engine.FindArchived("1"); err != ErrOrderNotFound {
+		t.Fatal("the oldest order must be evicted")
+	}
+
+	if orders := engine.ArchivedOrders(wallet2); len(orders) != 1 || orders[0].Order.ID() != "3" {
+		t.Fatal("invalid archived orders", orders)
+	}
+
+	engine.SetArchive(&Archive{TTL: time.Nanosecond})
+	time.Sleep(time.Millisecond)
+
+	if _, err := engine.FindArchived("4"); err != ErrOrderNotFound {
+		t.Fatal("expired order must be evicted")
+	}
+
+	engine.SetArchive(nil)
+
+	if _, err := engine.FindArchived("4"); err != ErrOrderNotFound {
+		t.Fatal("archive must be disabled")
+	}
+}
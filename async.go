@@ -0,0 +1,207 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrListenerOverflow is reported by AsyncListener when the event is dropped
+// due to OverflowError policy
+var ErrListenerOverflow = errors.New("Listener queue overflow")
+
+// OverflowPolicy defines AsyncListener behavior when the queue is full
+type OverflowPolicy uint8
+
+// Overflow policies
+const (
+	// OverflowBlock blocks the engine until the listener catches up
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest queued event to free the space
+	OverflowDropOldest
+
+	// OverflowError drops the new event and reports ErrListenerOverflow by Err
+	OverflowError
+)
+
+// AsyncListener delivers events to the wrapped listener on a separate
+// goroutine through the bounded queue, so slow listeners don't stall
+// matching. Optional EventListener extensions are forwarded as ListenerMux
+// does. Orders are delivered as is, their quantities may already be changed
+// by the engine at delivery time, use Volume values of the events instead
+type AsyncListener struct {
+	mux    *ListenerMux
+	policy OverflowPolicy
+	events chan func()
+	done   chan struct{}
+
+	err     error
+	dropped uint64
+	m       sync.Mutex
+}
+
+// NewAsyncListener starts delivery of events to the listener with the queue
+// of given size. Close must be called to stop delivery
+func NewAsyncListener(l EventListener, size int, policy OverflowPolicy) *AsyncListener {
+	a := &AsyncListener{
+		mux:    NewListenerMux(l),
+		policy: policy,
+		events: make(chan func(), size),
+		done:   make(chan struct{}),
+	}
+
+	go a.run()
+	return a
+}
+
+// Close delivers queued events and stops the delivery goroutine. The listener
+// must not be passed to the engine after Close
+func (a *AsyncListener) Close() {
+	close(a.events)
+	<-a.done
+}
+
+// Err returns ErrListenerOverflow if events have been dropped under
+// OverflowError policy
+func (a *AsyncListener) Err() error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	return a.err
+}
+
+// Dropped returns number of events dropped due to queue overflow
+func (a *AsyncListener) Dropped() uint64 {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	return a.dropped
+}
+
+func (a *AsyncListener) run() {
+	defer close(a.done)
+
+	for event := range a.events {
+		event()
+	}
+}
+
+func (a *AsyncListener) enqueue(event func()) {
+	if a.policy == OverflowBlock {
+		a.events <- event
+		return
+	}
+
+	for {
+		select {
+		case a.events <- event:
+			return
+		default:
+		}
+
+		a.m.Lock()
+		a.dropped++
+		if a.policy == OverflowError {
+			a.err = ErrListenerOverflow
+			a.m.Unlock()
+			return
+		}
+		a.m.Unlock()
+
+		select {
+		case <-a.events:
+		default:
+		}
+	}
+}
+
+// OnIncomingOrderPartial queues event for delivery
+func (a *AsyncListener) OnIncomingOrderPartial(ctx context.Context, o Order, v Volume) {
+	a.enqueue(func() { a.mux.OnIncomingOrderPartial(ctx, o, v) })
+}
+
+// OnIncomingOrderDone queues event for delivery
+func (a *AsyncListener) OnIncomingOrderDone(ctx context.Context, o Order, v Volume) {
+	a.enqueue(func() { a.mux.OnIncomingOrderDone(ctx, o, v) })
+}
+
+// OnIncomingOrderPlaced queues event for delivery
+func (a *AsyncListener) OnIncomingOrderPlaced(ctx context.Context, o Order) {
+	a.enqueue(func() { a.mux.OnIncomingOrderPlaced(ctx, o) })
+}
+
+// OnExistingOrderPartial queues event for delivery
+func (a *AsyncListener) OnExistingOrderPartial(ctx context.Context, o Order, v Volume) {
+	a.enqueue(func() { a.mux.OnExistingOrderPartial(ctx, o, v) })
+}
+
+// OnExistingOrderDone queues event for delivery
+func (a *AsyncListener) OnExistingOrderDone(ctx context.Context, o Order, v Volume) {
+	a.enqueue(func() { a.mux.OnExistingOrderDone(ctx, o, v) })
+}
+
+// OnExistingOrderCanceled queues event for delivery
+func (a *AsyncListener) OnExistingOrderCanceled(ctx context.Context, o Order) {
+	a.enqueue(func() { a.mux.OnExistingOrderCanceled(ctx, o) })
+}
+
+// OnBalanceChanged queues event for delivery
+func (a *AsyncListener) OnBalanceChanged(ctx context.Context, w Wallet, as Asset, v Value) {
+	a.enqueue(func() { a.mux.OnBalanceChanged(ctx, w, as, v) })
+}
+
+// OnInOrderChanged queues event for delivery
+func (a *AsyncListener) OnInOrderChanged(ctx context.Context, w Wallet, as Asset, v Value) {
+	a.enqueue(func() { a.mux.OnInOrderChanged(ctx, w, as, v) })
+}
+
+// OnIncomingOrderCanceled queues event for delivery
+func (a *AsyncListener) OnIncomingOrderCanceled(ctx context.Context, o Order) {
+	a.enqueue(func() { a.mux.OnIncomingOrderCanceled(ctx, o) })
+}
+
+// OnExistingOrderExpired queues event for delivery
+func (a *AsyncListener) OnExistingOrderExpired(ctx context.Context, o Order) {
+	a.enqueue(func() { a.mux.OnExistingOrderExpired(ctx, o) })
+}
+
+// OnExistingOrderUnderfunded queues event for delivery
+func (a *AsyncListener) OnExistingOrderUnderfunded(ctx context.Context, o Order) {
+	a.enqueue(func() { a.mux.OnExistingOrderUnderfunded(ctx, o) })
+}
+
+// OnExistingOrderReduced queues event for delivery
+func (a *AsyncListener) OnExistingOrderReduced(ctx context.Context, o Order, reduced Value) {
+	a.enqueue(func() { a.mux.OnExistingOrderReduced(ctx, o, reduced) })
+}
+
+// OnTradingStateChanged queues event for delivery
+func (a *AsyncListener) OnTradingStateChanged(ctx context.Context, from, to TradingState) {
+	a.enqueue(func() { a.mux.OnTradingStateChanged(ctx, from, to) })
+}
+
+// OnMakerProtectionTriggered queues event for delivery
+func (a *AsyncListener) OnMakerProtectionTriggered(ctx context.Context, w Wallet) {
+	a.enqueue(func() { a.mux.OnMakerProtectionTriggered(ctx, w) })
+}
+
+// OnOrderRejected queues event for delivery
+func (a *AsyncListener) OnOrderRejected(ctx context.Context, o Order, err error) {
+	a.enqueue(func() { a.mux.OnOrderRejected(ctx, o, err) })
+}
+
+// OnFeeCharged queues event for delivery
+func (a *AsyncListener) OnFeeCharged(ctx context.Context, o Order, f Fee) {
+	a.enqueue(func() { a.mux.OnFeeCharged(ctx, o, f) })
+}
+
+// OnRebatePaid queues event for delivery
+func (a *AsyncListener) OnRebatePaid(ctx context.Context, o Order, as Asset, v Value) {
+	a.enqueue(func() { a.mux.OnRebatePaid(ctx, o, as, v) })
+}
+
+// OnTrade queues event for delivery
+func (a *AsyncListener) OnTrade(ctx context.Context, t Trade) {
+	a.enqueue(func() { a.mux.OnTrade(ctx, t) })
+}
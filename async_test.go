@@ -0,0 +1,82 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tGateListener struct {
+	*tTradeListener
+	gate chan struct{}
+}
+
+func (t *tGateListener) OnTrade(ctx context.Context, tr Trade) {
+	<-t.gate
+	t.tTradeListener.OnTrade(ctx, tr)
+}
+
+func TestAsyncListener(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine   = NewEngine(asset1, asset2)
+		listener = &tTradeListener{tEventListener: newEventListener()}
+		async    = NewAsyncListener(listener, 1, OverflowBlock)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+	updateWalletBalance(wallet2, asset2, 20)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), async, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), async, newOrder("2", wallet2, false, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), async, newOrder("3", wallet2, false, 1, 10)))
+
+	async.Close()
+
+	if len(listener.trades) != 2 || listener.done != 3 || async.Dropped() != 0 {
+		t.Fatal("all events must be delivered")
+	}
+}
+
+func TestAsyncListenerOverflow(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropOldest, OverflowError} {
+		var (
+			listener = &tGateListener{
+				tTradeListener: &tTradeListener{tEventListener: newEventListener()},
+				gate:           make(chan struct{}),
+			}
+			async = NewAsyncListener(listener, 2, policy)
+		)
+
+		// The first event blocks the delivery goroutine
+		async.OnTrade(context.Background(), Trade{ID: 1})
+		for i := uint64(2); i <= 5 && async.Dropped() == 0; i++ {
+			async.OnTrade(context.Background(), Trade{ID: i})
+		}
+
+		if async.Dropped() == 0 {
+			t.Fatal("events must be dropped on overflow")
+		}
+
+		close(listener.gate)
+		async.Close()
+
+		var (
+			trades = listener.trades
+			last   = trades[len(trades)-1].ID
+		)
+
+		switch policy {
+		case OverflowDropOldest:
+			if async.Err() != nil || last != uint64(len(trades))+async.Dropped() {
+				t.Fatal("the newest events must be kept", trades)
+			}
+
+		case OverflowError:
+			if async.Err() != ErrListenerOverflow || last == uint64(len(trades))+async.Dropped() {
+				t.Fatal("the newest event must be dropped", trades)
+			}
+		}
+	}
+}
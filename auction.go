@@ -0,0 +1,259 @@
+package fastme
+
+import (
+	"container/list"
+	"context"
+)
+
+// ----------------------------------------------------------
+// Call auction implementation
+// ----------------------------------------------------------
+
+// AuctionOnlyOrder is an optional Order extension. Auction-only (good till
+// cross) orders are accepted during the auction phase only and expire when
+// the engine leaves the StateAuction state
+type AuctionOnlyOrder interface {
+	AuctionOnly() bool
+}
+
+// ExpiredListener is an optional EventListener extension informing about
+// expired orders. OnExistingOrderCanceled is called if it's not implemented
+type ExpiredListener interface {
+	OnExistingOrderExpired(context.Context, Order)
+}
+
+// Uncross finishes the auction phase. It calculates the equilibrium price
+// maximizing executable volume and matches all crossing orders at that
+// single price. The engine returns to the StateOpen trading state afterwards.
+// Returns nil price if the order book is not crossed
+func (e *Engine) Uncross(
+	ctx context.Context,
+	listener EventListener,
+) (price Value, total Volume, err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	if err := e.unusable(); err != nil {
+		return nil, total, err
+	}
+
+	if e.state != StateAuction {
+		return nil, total, ErrNoAuction
+	}
+
+	if err = e.record(ctx, journalUncross, "", nil, nil); err != nil {
+		return nil, total, err
+	}
+
+	listener = e.listenerOf(listener)
+
+	if e.feeHandler == nil {
+		e.feeHandler = emptyFeeHandlerValue
+	}
+
+	price, total = e.uncrossBook(ctx, listener)
+	e.setState(ctx, listener, StateOpen)
+	return price, total, nil
+}
+
+func (e *Engine) uncrossBook(
+	ctx context.Context,
+	listener EventListener,
+) (price Value, total Volume) {
+	price = e.equilibriumPrice()
+	if price != nil {
+		total = e.uncross(ctx, listener, price)
+	}
+	return
+}
+
+// equilibriumPrice returns the price with maximum executable volume. When
+// several prices execute the same volume the one with minimum imbalance
+// between demand and supply wins, then the lowest one
+func (e *Engine) equilibriumPrice() Value {
+	var (
+		asks = e.asks.ascending()
+		bids = e.bids.ascending()
+
+		demand Value // bids volume with price >= candidate
+		supply Value // asks volume with price <= candidate
+
+		bestPrice     Value
+		bestVolume    Value
+		bestImbalance Value
+	)
+
+	for _, q := range bids {
+		demand = q.volume.Add(demand)
+	}
+
+	for i, j := 0, 0; i < len(asks) || j < len(bids); {
+		var candidate Value
+		switch {
+		case j == len(bids) ||
+			(i < len(asks) && asks[i].price.Cmp(bids[j].price) <= 0):
+			candidate = asks[i].price
+		default:
+			candidate = bids[j].price
+		}
+
+		for i < len(asks) && asks[i].price.Cmp(candidate) == 0 {
+			supply = asks[i].volume.Add(supply)
+			i++
+		}
+
+		if demand != nil && supply != nil {
+			volume := minValue(demand, supply)
+			imbalance := absValue(demand.Sub(supply))
+
+			if volume.Sign() > 0 &&
+				(bestVolume == nil ||
+					volume.Cmp(bestVolume) > 0 ||
+					(volume.Cmp(bestVolume) == 0 &&
+						imbalance.Cmp(bestImbalance) < 0)) {
+				bestPrice = candidate
+				bestVolume = volume
+				bestImbalance = imbalance
+			}
+		}
+
+		for j < len(bids) && bids[j].price.Cmp(candidate) == 0 {
+			demand = demand.Sub(bids[j].volume)
+			j++
+		}
+	}
+
+	return bestPrice
+}
+
+// uncross matches crossing orders at the given price
+func (e *Engine) uncross(
+	ctx context.Context,
+	listener EventListener,
+	price Value,
+) (total Volume) {
+	for {
+		var (
+			bidsQueue = e.bids.maxPrice()
+			asksQueue = e.asks.minPrice()
+		)
+
+		if bidsQueue == nil ||
+			asksQueue == nil ||
+			bidsQueue.price.Cmp(price) < 0 ||
+			asksQueue.price.Cmp(price) > 0 {
+			return
+		}
+
+		var (
+			bidEl = bidsQueue.orders.Front()
+			askEl = asksQueue.orders.Front()
+			bid   = bidEl.Value.(Order)
+			ask   = askEl.Value.(Order)
+
+			bidQty   = bid.Quantity()
+			askQty   = ask.Quantity()
+			quantity = minValue(bidQty, askQty)
+
+			volume = Volume{
+				Price:    quantity.Mul(price),
+				Quantity: quantity,
+			}
+		)
+
+		total.Price = volume.Price.Add(total.Price)
+		total.Quantity = volume.Quantity.Add(total.Quantity)
+
+		e.fillResting(ctx, bidsQueue, bidEl, bidQty.Sub(quantity))
+		e.fillResting(ctx, asksQueue, askEl, askQty.Sub(quantity))
+
+		e.updateBalance(ctx, listener, bid, volume, true)
+		e.updateBalance(ctx, listener, ask, volume, true)
+
+		// Bid reserved more than it pays at equilibrium price
+		if refund := bid.Price().Sub(price).Mul(quantity); refund.Sign() > 0 {
+			e.release(ctx, listener, bid.Owner(), e.quote, refund)
+		}
+
+		e.notifyResting(ctx, listener, bid, volume)
+		e.notifyResting(ctx, listener, ask, volume)
+		e.trade(ctx, listener, ask, bid, price, quantity)
+	}
+}
+
+// fillResting sets new quantity of the resting order and removes it from
+// the order book when it is done
+func (e *Engine) fillResting(
+	ctx context.Context,
+	q *queue,
+	el *list.Element,
+	qty Value,
+) {
+	if qty.Sign() > 0 {
+		q.updateQuantity(ctx, el, qty)
+		return
+	}
+
+	o := el.Value.(Order)
+	e.pull(ctx, o)
+	o.UpdateQuantity(qty)
+	e.archive(ctx, o, StatusFilled)
+}
+
+func (e *Engine) notifyResting(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+	v Volume,
+) {
+	if o.Quantity().Sign() > 0 {
+		listener.OnExistingOrderPartial(ctx, o, v)
+	} else {
+		listener.OnExistingOrderDone(ctx, o, v)
+	}
+}
+
+// expireAuctionOnly cancels all resting auction-only orders
+func (e *Engine) expireAuctionOnly(ctx context.Context, listener EventListener) {
+	var expired []Order
+	for _, s := range []*side{e.asks, e.bids} {
+		for _, q := range s.ascending() {
+			for el := q.orders.Front(); el != nil; el = el.Next() {
+				if o := el.Value.(Order); isAuctionOnly(o) {
+					expired = append(expired, o)
+				}
+			}
+		}
+	}
+
+	for _, o := range expired {
+		e.cancel(ctx, listener, o)
+		if l, ok := listener.(ExpiredListener); ok {
+			l.OnExistingOrderExpired(ctx, o)
+		} else {
+			listener.OnExistingOrderCanceled(ctx, o)
+		}
+	}
+}
+
+func isAuctionOnly(o Order) bool {
+	a, ok := o.(AuctionOnlyOrder)
+	return ok && a.AuctionOnly()
+}
+
+func minValue(a, b Value) Value {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+func absValue(v Value) Value {
+	if v.Sign() < 0 {
+		return v.Sub(v).Sub(v)
+	}
+	return v
+}
@@ -0,0 +1,153 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAuctionUncross(t *testing.T) {
+	var (
+		processor      = newEventListener()
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+
+		wallet1, wallet2 = newWallet(), newWallet()
+		wallet3, wallet4 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 5)
+	updateWalletBalance(wallet2, asset1, 3)
+	updateWalletBalance(wallet3, asset2, 52)
+	updateWalletBalance(wallet4, asset2, 48)
+
+	assertErr(t, engine.SetTradingState(context.Background(), nil, StateAuction))
+
+	if err := engine.PlaceOrder(
+		context.Background(),
+		processor,
+		newOrder("market", wallet3, false, 1, 0),
+	); err != ErrAuctionMarketOrder {
+		t.Fatal("market order must be rejected during auction")
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 5, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet2, true, 3, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet3, false, 4, 13)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet4, false, 4, 12)))
+
+	if len(engine.Orders()) != 4 {
+		t.Fatal("orders must not be matched during auction")
+	}
+
+	price, volume, err := engine.Uncross(context.Background(), processor)
+	assertErr(t, err)
+
+	if price.(tFloat64) != 12 ||
+		volume.Quantity.(tFloat64) != 8 ||
+		volume.Price.(tFloat64) != 96 {
+		t.Fatal("invalid equilibrium", price, volume)
+	}
+
+	if len(engine.Orders()) != 0 {
+		t.Fatal("invalid result")
+	}
+
+	if walletBalance(wallet1, asset2) != 60 ||
+		walletBalance(wallet2, asset2) != 36 ||
+		walletBalance(wallet3, asset1) != 4 ||
+		walletBalance(wallet3, asset2) != 4 ||
+		walletBalance(wallet4, asset1) != 4 ||
+		walletBalance(wallet4, asset2) != 0 ||
+		// -------------
+		walletInOrder(wallet1, asset1) != 0 ||
+		walletInOrder(wallet2, asset1) != 0 ||
+		walletInOrder(wallet3, asset2) != 0 ||
+		walletInOrder(wallet4, asset2) != 0 {
+		t.Fatal("invalid result")
+	}
+
+	if _, _, err := engine.Uncross(context.Background(), processor); err != ErrNoAuction {
+		t.Fatal("auction must be finished")
+	}
+}
+
+func TestAuctionUncrossNotCrossed(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 1)
+	updateWalletBalance(wallet2, asset2, 10)
+
+	assertErr(t, engine.SetTradingState(context.Background(), nil, StateAuction))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 20)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet2, false, 1, 10)))
+
+	price, _, err := engine.Uncross(context.Background(), nil)
+	assertErr(t, err)
+
+	if price != nil || len(engine.Orders()) != 2 {
+		t.Fatal("invalid result")
+	}
+}
+
+type tAuctionOnlyOrder struct {
+	*tOrder
+}
+
+func (t *tAuctionOnlyOrder) AuctionOnly() bool {
+	return true
+}
+
+type tExpiredListener struct {
+	*tEventListener
+	expired []Order
+}
+
+func (t *tExpiredListener) OnExistingOrderExpired(ctx context.Context, o Order) {
+	t.expired = append(t.expired, o)
+}
+
+func TestAuctionOnlyOrders(t *testing.T) {
+	var (
+		processor        = &tExpiredListener{tEventListener: newEventListener()}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		order1 = &tAuctionOnlyOrder{newOrder("1", wallet2, false, 1, 5)}
+	)
+
+	updateWalletBalance(wallet1, asset1, 1)
+	updateWalletBalance(wallet2, asset2, 20)
+
+	if err := engine.PlaceOrder(context.Background(), processor, order1); err != ErrAuctionOnly {
+		t.Fatal("auction-only order must be rejected outside of auction")
+	}
+
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StateAuction))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, order1))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet2, false, 1, 10)))
+
+	if walletBalance(wallet2, asset2) != 5 || walletInOrder(wallet2, asset2) != 15 {
+		t.Fatal("invalid result")
+	}
+
+	_, _, err := engine.Uncross(context.Background(), processor)
+	assertErr(t, err)
+
+	if len(processor.expired) != 1 ||
+		processor.expired[0] != order1 ||
+		len(engine.Orders()) != 0 ||
+		walletBalance(wallet2, asset2) != 10 ||
+		walletBalance(wallet2, asset1) != 1 ||
+		walletInOrder(wallet2, asset2) != 0 {
+		t.Fatal("auction-only order must expire after auction")
+	}
+}
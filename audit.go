@@ -0,0 +1,261 @@
+package fastme
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AuditCheck identifies the invariant verified by Audit
+type AuditCheck string
+
+// Order book invariants
+const (
+	// AuditLevelVolume: level volume equals the sum of order quantities
+	AuditLevelVolume AuditCheck = "level_volume"
+
+	// AuditLevelEmpty: price levels in the index are not empty
+	AuditLevelEmpty AuditCheck = "level_empty"
+
+	// AuditLevelIndex: the index finds the level by its price, levels are
+	// sorted by price and belong to the side
+	AuditLevelIndex AuditCheck = "level_index"
+
+	// AuditBestPrice: cached best levels are the outermost ones
+	AuditBestPrice AuditCheck = "best_price"
+
+	// AuditSideCounters: side depth and order counters match the index
+	AuditSideCounters AuditCheck = "side_counters"
+
+	// AuditOrder: order side and price match the level, quantity is positive
+	AuditOrder AuditCheck = "order"
+
+	// AuditOrderIndex: the orders map contains exactly the orders of levels
+	AuditOrderIndex AuditCheck = "order_index"
+
+	// AuditOwnerIndex: the owner index contains exactly the resting orders
+	AuditOwnerIndex AuditCheck = "owner_index"
+
+	// AuditCrossedBook: the book is not crossed in the StateOpen state
+	AuditCrossedBook AuditCheck = "crossed_book"
+)
+
+// AuditViolation is the broken invariant
+type AuditViolation struct {
+	Check AuditCheck
+
+	// Asks is set if the violation relates to the ask side
+	Asks bool
+
+	// Price is the price level, nil if not related to the level
+	Price Value
+
+	// OrderID is set if the violation relates to the order
+	OrderID string
+
+	Detail string
+}
+
+// AuditReport is the result of Audit
+type AuditReport struct {
+	// Levels and Orders are numbers of visited price levels and orders
+	Levels, Orders int
+
+	Violations []AuditViolation
+}
+
+// OK returns true if no violations are found
+func (r AuditReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Audit verifies internal order book invariants, see AuditCheck. It's
+// intended to be run after Restore or PushOrder based rebuilds and in soak
+// tests. The order book is walked under the read lock, the context error is
+// returned if the context is done before the walk is complete
+func (e *Engine) Audit(ctx context.Context) (AuditReport, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	var (
+		r       AuditReport
+		visited = make(map[*list.Element]bool, len(e.orders))
+	)
+
+	for _, asks := range []bool{true, false} {
+		if err := e.auditSide(ctx, &r, asks, visited); err != nil {
+			return r, err
+		}
+	}
+
+	if len(e.orders) != len(visited) {
+		r.violation(AuditViolation{
+			Check:  AuditOrderIndex,
+			Detail: fmt.Sprintf("%d orders in the map, %d in levels", len(e.orders), len(visited)),
+		})
+	}
+
+	var (
+		owned int
+		stale []string
+	)
+
+	for w, orders := range e.owned {
+		for id, el := range orders {
+			owned++
+			if e.orders[id] != el || el.Value.(Order).Owner() != w {
+				stale = append(stale, id)
+			}
+		}
+	}
+
+	// Sorted, so the report does not depend on map iteration order
+	sort.Strings(stale)
+	for _, id := range stale {
+		r.violation(AuditViolation{
+			Check:   AuditOwnerIndex,
+			OrderID: id,
+			Detail:  "indexed order is not resting",
+		})
+	}
+
+	if owned != len(e.orders) {
+		r.violation(AuditViolation{
+			Check:  AuditOwnerIndex,
+			Detail: fmt.Sprintf("%d orders indexed by owner, %d resting", owned, len(e.orders)),
+		})
+	}
+
+	if ask, bid := e.asks.minPrice(), e.bids.maxPrice(); e.state == StateOpen &&
+		ask != nil && bid != nil && bid.price.Cmp(ask.price) >= 0 {
+		r.violation(AuditViolation{
+			Check:  AuditCrossedBook,
+			Detail: fmt.Sprintf("best bid %s >= best ask %s", e.format(bid.price), e.format(ask.price)),
+		})
+	}
+
+	e.audited.Store(auditSummary{
+		At:         e.now(),
+		Levels:     r.Levels,
+		Orders:     r.Orders,
+		Violations: len(r.Violations),
+	})
+
+	return r, nil
+}
+
+// auditSummary is the result of the last complete Audit reported by
+// DebugHandler. It's stored atomically, as Audit holds the read lock only
+type auditSummary struct {
+	At         time.Time `json:"at"`
+	Levels     int       `json:"levels"`
+	Orders     int       `json:"orders"`
+	Violations int       `json:"violations"`
+}
+
+func (e *Engine) auditSide(
+	ctx context.Context,
+	r *AuditReport,
+	asks bool,
+	visited map[*list.Element]bool,
+) error {
+	s := e.bids
+	if asks {
+		s = e.asks
+	}
+
+	var (
+		levels, orders int
+		prev, last     *queue
+	)
+
+	for q := s.minPrice(); q != nil; q = s.greaterThan(q.price) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		levels++
+		last = q
+
+		level := func(check AuditCheck, detail string) {
+			r.violation(AuditViolation{Check: check, Asks: asks, Price: q.price, Detail: detail})
+		}
+
+		if s.level(q.price) != q || q.side != s {
+			level(AuditLevelIndex, "level is not indexed by its price")
+		}
+
+		if prev != nil && prev.price.Cmp(q.price) >= 0 {
+			level(AuditLevelIndex, "levels are not sorted by price")
+		}
+		prev = q
+
+		if q.orders.Len() == 0 {
+			level(AuditLevelEmpty, "empty level")
+			continue
+		}
+
+		var volume Value
+		for el := q.orders.Front(); el != nil; el = el.Next() {
+			o := el.Value.(Order)
+			orders++
+			visited[el] = true
+
+			order := func(check AuditCheck, detail string) {
+				r.violation(AuditViolation{
+					Check:   check,
+					Asks:    asks,
+					Price:   q.price,
+					OrderID: o.ID(),
+					Detail:  detail,
+				})
+			}
+
+			switch {
+			case o.Sell() != asks:
+				order(AuditOrder, "order is on the wrong side")
+			case o.Price().Cmp(q.price) != 0:
+				order(AuditOrder, "order price differs from the level price")
+			case o.Quantity().Sign() <= 0:
+				order(AuditOrder, "order quantity is not positive")
+			}
+
+			if e.orders[o.ID()] != el {
+				order(AuditOrderIndex, "order is not in the orders map")
+			}
+
+			volume = o.Quantity().Add(volume)
+		}
+
+		if q.volume == nil {
+			level(AuditLevelVolume, "level volume is not set")
+		} else if q.volume.Cmp(volume) != 0 {
+			level(AuditLevelVolume, fmt.Sprintf("level volume %s, orders quantity %s",
+				e.format(q.volume), e.format(volume)))
+		}
+	}
+
+	if first := s.minPrice(); s.maxPrice() != last ||
+		(first != nil && s.lessThan(first.price) != nil) {
+		r.violation(AuditViolation{Check: AuditBestPrice, Asks: asks, Detail: "cached best level is not outermost"})
+	}
+
+	if s.depth != levels || s.numOrders != orders {
+		r.violation(AuditViolation{
+			Check: AuditSideCounters,
+			Asks:  asks,
+			Detail: fmt.Sprintf("depth %d, orders %d, indexed %d levels with %d orders",
+				s.depth, s.numOrders, levels, orders),
+		})
+	}
+
+	r.Levels += levels
+	r.Orders += orders
+	return nil
+}
+
+func (r *AuditReport) violation(v AuditViolation) {
+	r.Violations = append(r.Violations, v)
+}
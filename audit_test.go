@@ -0,0 +1,61 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	order := newOrder("1", wallet1, true, 2, 10)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 1, 10)))
+
+	r, err := engine.Audit(context.Background())
+	assertErr(t, err)
+
+	if !r.OK() || r.Levels != 3 || r.Orders != 4 {
+		t.Fatal("consistent order book must pass the audit", r)
+	}
+
+	// Quantity updated bypassing the engine
+	order.quantity = tFloat64(5)
+
+	// Pushed order crosses the book
+	engine.PushOrder(context.Background(), newOrder("6", wallet2, false, 1, 12))
+
+	r, err = engine.Audit(context.Background())
+	assertErr(t, err)
+
+	var checks []AuditCheck
+	for _, v := range r.Violations {
+		checks = append(checks, v.Check)
+	}
+
+	if len(r.Violations) != 2 ||
+		r.Violations[0].Check != AuditLevelVolume ||
+		r.Violations[0].Price != tFloat64(10) ||
+		!r.Violations[0].Asks ||
+		r.Violations[1].Check != AuditCrossedBook {
+		t.Fatal("violations must be reported", checks)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := engine.Audit(ctx); err != context.Canceled {
+		t.Fatal("audit must be interrupted", err)
+	}
+}
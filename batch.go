@@ -0,0 +1,34 @@
+package fastme
+
+import "context"
+
+// PlaceOrders places the batch of orders under one lock acquisition, as if
+// PlaceOrder was called for each of them in turn. Events are buffered and
+// delivered to the listener in one flush after the whole batch is processed,
+// in the emission order and before the lock is released. Orders are passed
+// to the listener as read-only views taken at the emission time, see ViewOf.
+// Returns errors by order index, nil for accepted orders
+func (e *Engine) PlaceOrders(
+	ctx context.Context,
+	listener EventListener,
+	orders []Order,
+) []error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	var (
+		errs     = make([]error, len(orders))
+		recorder eventRecorder
+	)
+
+	defer func() {
+		defer e.guard(ctx, nil)
+		recorder.flush(e.listenerOf(listener))
+	}()
+
+	for i, o := range orders {
+		errs[i] = e.placeOrder(ctx, &recorder, o, nil)
+	}
+
+	return errs
+}
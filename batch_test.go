@@ -0,0 +1,107 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPlaceOrders(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+		listener         = &tTradeListener{tEventListener: newEventListener()}
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	errs := engine.PlaceOrders(context.Background(), listener, []Order{
+		newOrder("1", wallet1, true, 1, 10),
+		newOrder("2", wallet1, true, 2, 11),
+		newOrder("1", wallet1, true, 1, 12),
+		newOrder("3", wallet1, true, 1, 12),
+		newOrder("4", wallet2, false, 2, 11),
+	})
+
+	if len(errs) != 5 ||
+		errs[0] != nil ||
+		errs[1] != nil ||
+		errs[2] != ErrOrderExists ||
+		!errors.Is(errs[3], ErrInsufficientFunds) ||
+		errs[4] != nil {
+		t.Fatal("invalid batch errors", errs)
+	}
+
+	if len(listener.trades) != 2 {
+		t.Fatal("batch orders must be matched in turn", listener.trades)
+	}
+
+	if orders := engine.Orders(); len(orders) != 1 || orders[0].ID() != "2" || orders[0].Quantity().Cmp(tFloat64(1)) != 0 {
+		t.Fatal("invalid order book", orders)
+	}
+}
+
+type tBatchListener struct {
+	tEventListener
+	wallet   *tWallet
+	inOrder  []float64
+	quantity []Value
+}
+
+func (t *tBatchListener) OnIncomingOrderPlaced(ctx context.Context, o Order) {
+	t.inOrder = append(t.inOrder, walletInOrder(t.wallet, "apples"))
+	t.quantity = append(t.quantity, o.Quantity())
+}
+
+func TestPlaceOrdersFlush(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		listener       = &tBatchListener{wallet: wallet1}
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+
+	errs := engine.PlaceOrders(context.Background(), listener, []Order{
+		newOrder("1", wallet1, true, 1, 10),
+		newOrder("2", wallet1, true, 2, 11),
+	})
+	assertErr(t, errs[0])
+	assertErr(t, errs[1])
+
+	// Events are delivered after the whole batch is processed
+	if len(listener.inOrder) != 2 || listener.inOrder[0] != 3 || listener.inOrder[1] != 3 {
+		t.Fatal("events must be flushed after the batch", listener.inOrder)
+	}
+}
+
+func TestPlaceOrdersEventOrders(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+		wallet2        = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		listener       = &tBatchListener{wallet: wallet1}
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 30)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("m", wallet1, true, 1, 10)))
+
+	errs := engine.PlaceOrders(context.Background(), listener, []Order{
+		newOrder("t", wallet2, false, 3, 10),
+		newOrder("s", wallet1, true, 2, 10),
+	})
+	assertErr(t, errs[0])
+	assertErr(t, errs[1])
+
+	// The placed remainder is filled later in the batch, the listener still
+	// reads the quantity of the emission time
+	if len(listener.quantity) != 1 || listener.quantity[0] != tFloat64(2) {
+		t.Fatal("event must carry the order at the emission time", listener.quantity)
+	}
+}
@@ -0,0 +1,267 @@
+//go:build go1.21
+// +build go1.21
+
+package fastme
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+)
+
+// Number is the numeric type of Book values
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// BookOrder is the order of the Book
+type BookOrder[V Number] struct {
+	ID   string
+	Sell bool
+
+	// Price is the limit price, zero for the market order
+	Price V
+
+	// Quantity is the remaining quantity
+	Quantity V
+}
+
+// BookFill is the execution of the incoming order against the resting one
+type BookFill[V Number] struct {
+	MakerID  string
+	Price    V
+	Quantity V
+}
+
+// BookLevel is the price level of the Book
+type BookLevel[V Number] struct {
+	Price  V
+	Volume V
+
+	// Orders is the number of orders resting at the price
+	Orders int
+}
+
+// Book is the order book specialized for the numeric type. Prices and
+// quantities are not boxed into Value, so matching does no interface
+// allocations and type assertions on the math. It's the price-time matching
+// core of Engine only, without wallets, fees, listeners and trading states:
+// fills are returned to the caller to be settled. Market orders are executed
+// against available levels and the remainder is dropped
+type Book[V Number] struct {
+	orders map[string]*list.Element // ID -> *list.Element.Value.(*BookOrder[V])
+
+	// Levels are sorted from the worst price, so the best one is removed
+	// from the tail
+	asks []*bookLevel[V]
+	bids []*bookLevel[V]
+
+	m sync.RWMutex
+}
+
+type bookLevel[V Number] struct {
+	price  V
+	volume V
+	orders list.List
+}
+
+// NewBook creates empty order book
+func NewBook[V Number]() *Book[V] {
+	return &Book[V]{orders: make(map[string]*list.Element)}
+}
+
+// Place matches the order against the order book and puts the limit order
+// remainder to the queue. Returns executed fills in the execution order
+func (b *Book[V]) Place(o BookOrder[V]) (fills []BookFill[V], err error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if o.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	if o.Price < 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	if _, ok := b.orders[o.ID]; ok {
+		return nil, ErrOrderExists
+	}
+
+	opposite := &b.bids
+	if !o.Sell {
+		opposite = &b.asks
+	}
+
+	for o.Quantity > 0 && len(*opposite) > 0 {
+		l := (*opposite)[len(*opposite)-1]
+		if o.Price != 0 && (o.Sell && o.Price > l.price || !o.Sell && o.Price < l.price) {
+			break
+		}
+
+		for o.Quantity > 0 && l.orders.Len() > 0 {
+			var (
+				el       = l.orders.Front()
+				maker    = el.Value.(*BookOrder[V])
+				quantity = maker.Quantity
+			)
+
+			if o.Quantity < quantity {
+				quantity = o.Quantity
+			}
+
+			maker.Quantity -= quantity
+			l.volume -= quantity
+			o.Quantity -= quantity
+
+			fills = append(fills, BookFill[V]{
+				MakerID:  maker.ID,
+				Price:    l.price,
+				Quantity: quantity,
+			})
+
+			if maker.Quantity == 0 {
+				l.orders.Remove(el)
+				delete(b.orders, maker.ID)
+			}
+		}
+
+		if l.orders.Len() == 0 {
+			(*opposite)[len(*opposite)-1] = nil
+			*opposite = (*opposite)[:len(*opposite)-1]
+		}
+	}
+
+	if o.Quantity > 0 && o.Price != 0 {
+		b.push(o)
+	}
+
+	return fills, nil
+}
+
+// push puts the order to the queue of its price level
+func (b *Book[V]) push(o BookOrder[V]) {
+	levels := b.side(o.Sell)
+
+	i, ok := b.search(o.Sell, o.Price)
+	if !ok {
+		*levels = append(*levels, nil)
+		copy((*levels)[i+1:], (*levels)[i:])
+		(*levels)[i] = &bookLevel[V]{price: o.Price}
+	}
+
+	l := (*levels)[i]
+	l.volume += o.Quantity
+	b.orders[o.ID] = l.orders.PushBack(&o)
+}
+
+// Cancel removes the order from the order book, returns the canceled order
+// or ErrOrderNotFound
+func (b *Book[V]) Cancel(id string) (BookOrder[V], error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	el, ok := b.orders[id]
+	if !ok {
+		return BookOrder[V]{}, ErrOrderNotFound
+	}
+
+	var (
+		o      = el.Value.(*BookOrder[V])
+		levels = b.side(o.Sell)
+		i, _   = b.search(o.Sell, o.Price)
+		l      = (*levels)[i]
+	)
+
+	l.orders.Remove(el)
+	l.volume -= o.Quantity
+	delete(b.orders, id)
+
+	if l.orders.Len() == 0 {
+		copy((*levels)[i:], (*levels)[i+1:])
+		(*levels)[len(*levels)-1] = nil
+		*levels = (*levels)[:len(*levels)-1]
+	}
+
+	return *o, nil
+}
+
+// Find returns the resting order by its ID or ErrOrderNotFound
+func (b *Book[V]) Find(id string) (BookOrder[V], error) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	el, ok := b.orders[id]
+	if !ok {
+		return BookOrder[V]{}, ErrOrderNotFound
+	}
+	return *el.Value.(*BookOrder[V]), nil
+}
+
+// Len returns the number of resting orders
+func (b *Book[V]) Len() int {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return len(b.orders)
+}
+
+// Best returns the best price level of the side, false if the side is empty
+func (b *Book[V]) Best(sell bool) (BookLevel[V], bool) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	levels := *b.side(sell)
+	if len(levels) == 0 {
+		return BookLevel[V]{}, false
+	}
+	return levels[len(levels)-1].level(), true
+}
+
+// Depth returns up to n best price levels of each side, all levels are
+// returned if n <= 0. Levels are sorted from the best price
+func (b *Book[V]) Depth(n int) (asks, bids []BookLevel[V]) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return bookDepth(b.asks, n), bookDepth(b.bids, n)
+}
+
+func bookDepth[V Number](levels []*bookLevel[V], n int) (depth []BookLevel[V]) {
+	for i := len(levels) - 1; i >= 0 && (n <= 0 || len(depth) < n); i-- {
+		depth = append(depth, levels[i].level())
+	}
+	return
+}
+
+func (b *Book[V]) side(sell bool) *[]*bookLevel[V] {
+	if sell {
+		return &b.asks
+	}
+	return &b.bids
+}
+
+// search returns the index of the price level of the side or the index to
+// insert the level at, the levels are sorted from the worst price
+func (b *Book[V]) search(sell bool, price V) (int, bool) {
+	levels := *b.side(sell)
+
+	i := sort.Search(len(levels), func(i int) bool {
+		if sell {
+			return levels[i].price <= price
+		}
+		return levels[i].price >= price
+	})
+
+	return i, i < len(levels) && levels[i].price == price
+}
+
+func (l *bookLevel[V]) level() BookLevel[V] {
+	return BookLevel[V]{
+		Price:  l.price,
+		Volume: l.volume,
+		Orders: l.orders.Len(),
+	}
+}
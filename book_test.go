@@ -0,0 +1,141 @@
+//go:build go1.21
+// +build go1.21
+
+package fastme
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestBook(t *testing.T) {
+	book := NewBook[int64]()
+
+	for _, o := range []BookOrder[int64]{
+		{ID: "1", Sell: true, Price: 11, Quantity: 2},
+		{ID: "2", Sell: true, Price: 10, Quantity: 1},
+		{ID: "3", Sell: true, Price: 10, Quantity: 3},
+		{ID: "4", Sell: false, Price: 8, Quantity: 5},
+	} {
+		if fills, err := book.Place(o); err != nil || len(fills) != 0 {
+			t.Fatal("order must rest", o, fills, err)
+		}
+	}
+
+	if _, err := book.Place(BookOrder[int64]{ID: "1", Price: 1, Quantity: 1}); err != ErrOrderExists {
+		t.Fatal("duplicate order must be rejected", err)
+	}
+
+	if _, err := book.Place(BookOrder[int64]{ID: "5", Price: 1}); err != ErrInvalidQuantity {
+		t.Fatal("zero quantity must be rejected", err)
+	}
+
+	// Crossing buy is executed in the price-time order at maker prices
+	fills, err := book.Place(BookOrder[int64]{ID: "5", Price: 11, Quantity: 5})
+	if err != nil || len(fills) != 3 ||
+		fills[0] != (BookFill[int64]{MakerID: "2", Price: 10, Quantity: 1}) ||
+		fills[1] != (BookFill[int64]{MakerID: "3", Price: 10, Quantity: 3}) ||
+		fills[2] != (BookFill[int64]{MakerID: "1", Price: 11, Quantity: 1}) {
+		t.Fatal("invalid fills", fills, err)
+	}
+
+	asks, bids := book.Depth(0)
+	if len(asks) != 1 || asks[0] != (BookLevel[int64]{Price: 11, Volume: 1, Orders: 1}) ||
+		len(bids) != 1 || bids[0] != (BookLevel[int64]{Price: 8, Volume: 5, Orders: 1}) {
+		t.Fatal("invalid depth", asks, bids)
+	}
+
+	if o, err := book.Find("1"); err != nil || o.Quantity != 1 {
+		t.Fatal("partially filled order must rest", o, err)
+	}
+
+	// Market order remainder is dropped
+	fills, err = book.Place(BookOrder[int64]{ID: "6", Sell: true, Quantity: 7})
+	if err != nil || len(fills) != 1 || fills[0].Quantity != 5 || book.Len() != 1 {
+		t.Fatal("market order must sweep the book", fills, err)
+	}
+
+	if _, ok := book.Best(false); ok {
+		t.Fatal("bids must be empty")
+	}
+
+	if o, err := book.Cancel("1"); err != nil || o.ID != "1" || book.Len() != 0 {
+		t.Fatal("order must be canceled", o, err)
+	}
+
+	if _, err := book.Cancel("1"); err != ErrOrderNotFound {
+		t.Fatal("canceled order must not be found", err)
+	}
+
+	if asks, _ := book.Depth(0); len(asks) != 0 {
+		t.Fatal("canceled level must be removed", asks)
+	}
+}
+
+func TestBookLevels(t *testing.T) {
+	book := NewBook[float64]()
+
+	for _, o := range []BookOrder[float64]{
+		{ID: "1", Sell: true, Price: 30, Quantity: 1},
+		{ID: "2", Sell: false, Price: 10, Quantity: 1},
+		{ID: "3", Sell: true, Price: 50, Quantity: 1},
+		{ID: "4", Sell: true, Price: 40, Quantity: 1},
+		{ID: "5", Sell: false, Price: 20, Quantity: 1},
+	} {
+		if _, err := book.Place(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Asks 30, 50 and 40, bids 10 and 20 don't cross
+	if l, ok := book.Best(true); !ok || l.Price != 30 {
+		t.Fatal("invalid best ask", l)
+	}
+
+	if l, ok := book.Best(false); !ok || l.Price != 20 {
+		t.Fatal("invalid best bid", l)
+	}
+
+	if asks, bids := book.Depth(2); len(asks) != 2 || asks[1].Price != 40 || len(bids) != 2 || bids[1].Price != 10 {
+		t.Fatal("levels must be sorted from the best price", asks, bids)
+	}
+
+	if _, err := book.Cancel("4"); err != nil {
+		t.Fatal(err)
+	}
+
+	if asks, _ := book.Depth(0); len(asks) != 2 || asks[0].Price != 30 || asks[1].Price != 50 {
+		t.Fatal("middle level must be removed", asks)
+	}
+}
+
+func BenchmarkBook(b *testing.B) {
+	book := NewBook[int64]()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		id := strconv.Itoa(i)
+		_, _ = book.Place(BookOrder[int64]{ID: id, Sell: true, Price: int64(100 + i%50), Quantity: 2})
+		_, _ = book.Place(BookOrder[int64]{ID: id + "b", Price: int64(100 + i%50), Quantity: 1})
+	}
+}
+
+func BenchmarkBookEngine(b *testing.B) {
+	var (
+		ctx    = context.Background()
+		engine = NewEngine("apples", "dollars")
+		seller = newWallet()
+		buyer  = newWallet()
+	)
+
+	seller.UpdateBalance(ctx, "apples", tFloat64(2*b.N))
+	buyer.UpdateBalance(ctx, "dollars", tFloat64(150*b.N))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		id := strconv.Itoa(i)
+		_ = engine.PlaceOrder(ctx, nil, newOrder(id, seller, true, 2, float64(100+i%50)))
+		_ = engine.PlaceOrder(ctx, nil, newOrder(id+"b", buyer, false, 1, float64(100+i%50)))
+	}
+}
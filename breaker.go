@@ -0,0 +1,129 @@
+package fastme
+
+import (
+	"context"
+	"time"
+)
+
+// ReferenceSource selects the reference price of the circuit breaker
+type ReferenceSource uint8
+
+// Reference price sources
+const (
+	// ReferenceLast is the last trade price
+	ReferenceLast ReferenceSource = iota
+
+	// ReferenceVWAP is the rolling volume-weighted average price, see SetReferencePrices
+	ReferenceVWAP
+
+	// ReferenceTWAP is the rolling time-weighted average price, see SetReferencePrices
+	ReferenceTWAP
+)
+
+// CircuitBreaker stops matching when an incoming order would trade too far
+// from the reference price. The engine halts trading when it trips
+type CircuitBreaker struct {
+	// Reference is the fixed reference price. The price selected by Source is used if nil
+	Reference Value
+
+	// Source selects the dynamic reference price
+	Source ReferenceSource
+
+	// Low and High are reference price multipliers bounding allowed trade
+	// prices, e.g. 0.95 and 1.05 for a 5% band
+	Low, High Value
+
+	// Cancel drops the remainder of the interrupted limit order instead of
+	// placing it to the order book. The remainder of market order is always dropped
+	Cancel bool
+}
+
+// IncomingCanceledListener is an optional EventListener extension informing
+// about the dropped remainder of the incoming order
+type IncomingCanceledListener interface {
+	OnIncomingOrderCanceled(context.Context, Order)
+}
+
+// SetCircuitBreaker updates circuit breaker settings, nil disables it
+func (e *Engine) SetCircuitBreaker(cb *CircuitBreaker) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if cb == nil {
+		e.breaker = nil
+		return
+	}
+
+	breaker := *cb
+	e.breaker = &breaker
+}
+
+// priceBand contains allowed trade prices. Average reference prices are
+// compared without division: price * weight is checked against sum bounds
+type priceBand struct {
+	low, high Value
+	weight    Value
+}
+
+func (b *priceBand) contains(price Value) bool {
+	if b.weight != nil {
+		price = price.Mul(b.weight)
+	}
+	return price.Cmp(b.low) >= 0 && price.Cmp(b.high) <= 0
+}
+
+// priceBand returns allowed trade prices or nil if there are no restrictions
+func (e *Engine) priceBand(now time.Time) *priceBand {
+	if e.breaker == nil {
+		return nil
+	}
+
+	var (
+		reference = e.breaker.Reference
+		avg       Average
+		ok        bool
+	)
+
+	if reference == nil {
+		switch e.breaker.Source {
+		case ReferenceVWAP:
+			avg, ok = e.vwap(now)
+
+		case ReferenceTWAP:
+			avg, ok = e.twap(now)
+
+		default:
+			reference = e.lastPrice
+		}
+	}
+
+	if ok {
+		return &priceBand{
+			low:    avg.Sum.Mul(e.breaker.Low),
+			high:   avg.Sum.Mul(e.breaker.High),
+			weight: avg.Weight,
+		}
+	}
+
+	if reference == nil {
+		return nil
+	}
+
+	return &priceBand{
+		low:  reference.Mul(e.breaker.Low),
+		high: reference.Mul(e.breaker.High),
+	}
+}
+
+func (e *Engine) cancelIncoming(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) {
+	e.archive(ctx, o, StatusCanceled)
+	e.activity.canceled++
+
+	if l, ok := listener.(IncomingCanceledListener); ok {
+		l.OnIncomingOrderCanceled(ctx, o)
+	}
+}
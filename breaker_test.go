@@ -0,0 +1,108 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tCanceledListener struct {
+	*tStateListener
+	canceled []Order
+}
+
+func (t *tCanceledListener) OnIncomingOrderCanceled(ctx context.Context, o Order) {
+	t.canceled = append(t.canceled, o)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		processor = &tCanceledListener{
+			tStateListener: &tStateListener{tEventListener: newEventListener()},
+		}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 1, 100)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 110)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet1, true, 1, 130)))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet2, false, 1, 0)))
+
+	engine.SetCircuitBreaker(&CircuitBreaker{Low: tFloat64(0.9), High: tFloat64(1.1)})
+
+	if err := engine.PlaceOrder(
+		context.Background(),
+		processor,
+		newOrder("5", wallet2, false, 2, 140),
+	); err != ErrCircuitBreaker {
+		t.Fatal("circuit breaker must trip")
+	}
+
+	if engine.TradingState() != StateHalted ||
+		len(processor.states) != 1 ||
+		len(engine.Orders()) != 2 ||
+		len(processor.canceled) != 0 {
+		t.Fatal("invalid result")
+	}
+
+	engine.SetCircuitBreaker(nil)
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StateOpen))
+
+	if len(engine.Orders()) != 0 {
+		t.Fatal("crossed order book must be uncrossed on resume")
+	}
+
+	if walletBalance(wallet1, asset2) != 340 ||
+		walletBalance(wallet2, asset1) != 3 ||
+		walletBalance(wallet2, asset2) != 660 ||
+		walletInOrder(wallet2, asset2) != 0 {
+		t.Fatal("invalid result")
+	}
+}
+
+func TestCircuitBreakerCancel(t *testing.T) {
+	var (
+		processor = &tCanceledListener{
+			tStateListener: &tStateListener{tEventListener: newEventListener()},
+		}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	engine.SetCircuitBreaker(&CircuitBreaker{
+		Reference: tFloat64(100),
+		Low:       tFloat64(0.9),
+		High:      tFloat64(1.1),
+		Cancel:    true,
+	})
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 1, 100)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 120)))
+
+	if err := engine.PlaceOrder(
+		context.Background(),
+		processor,
+		newOrder("3", wallet2, false, 2, 130),
+	); err != ErrCircuitBreaker {
+		t.Fatal("circuit breaker must trip")
+	}
+
+	if len(processor.canceled) != 1 ||
+		processor.canceled[0].ID() != "3" ||
+		len(engine.Orders()) != 1 ||
+		walletBalance(wallet2, asset2) != 900 ||
+		walletInOrder(wallet2, asset2) != 0 {
+		t.Fatal("invalid result")
+	}
+}
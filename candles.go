@@ -0,0 +1,225 @@
+package fastme
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Candle is the OHLCV bar of trades executed within the interval
+type Candle struct {
+	Interval time.Duration
+
+	// Start is the interval start, the bar covers [Start, Start+Interval)
+	Start time.Time
+
+	Open, High, Low, Close Value
+
+	// Volume is the traded base asset quantity
+	Volume Value
+
+	// Turnover is the traded quote asset amount
+	Turnover Value
+
+	// Trades is the number of trades
+	Trades int
+}
+
+func (c *Candle) add(t Trade) {
+	if c.Trades == 0 {
+		c.Open, c.High, c.Low = t.Price, t.Price, t.Price
+	}
+
+	if t.Price.Cmp(c.High) > 0 {
+		c.High = t.Price
+	}
+
+	if t.Price.Cmp(c.Low) < 0 {
+		c.Low = t.Price
+	}
+
+	c.Close = t.Price
+	c.Volume = t.Quantity.Add(c.Volume)
+	c.Turnover = t.Price.Mul(t.Quantity).Add(c.Turnover)
+	c.Trades++
+}
+
+// CandleFunc receives the updated bar. Closed is set when the bar is final,
+// which is known when the first trade of the next interval arrives
+type CandleFunc func(c Candle, closed bool)
+
+type candleStream struct {
+	bar  *Candle
+	subs map[int]CandleFunc
+}
+
+// subscribers returns subscribers of the stream in the subscription order
+func (s *candleStream) subscribers() []CandleFunc {
+	ids := make([]int, 0, len(s.subs))
+	for id := range s.subs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	subs := make([]CandleFunc, len(ids))
+	for i, id := range ids {
+		subs[i] = s.subs[id]
+	}
+	return subs
+}
+
+// CandleAggregator is the EventListener building OHLCV bars from trades of
+// the engine. It keeps the bounded history of recent trades to build bars of
+// any interval on request and to backfill the current bar on subscription.
+// Intervals without trades produce no bars. Pass it to the engine with
+// WithListener or within ListenerMux
+type CandleAggregator struct {
+	emptyListener
+
+	history []Trade // ring buffer
+	next    int
+	full    bool
+
+	streams map[time.Duration]*candleStream
+	subID   int
+	m       sync.Mutex
+}
+
+// NewCandleAggregator creates aggregator keeping up to history recent trades
+func NewCandleAggregator(history int) *CandleAggregator {
+	return &CandleAggregator{
+		history: make([]Trade, history),
+		streams: make(map[time.Duration]*candleStream),
+	}
+}
+
+// OnTrade adds the trade to the history and updates bars of subscribed
+// intervals. Subscribers are called outside the aggregator lock, shorter
+// intervals first and in the subscription order within the interval
+func (a *CandleAggregator) OnTrade(ctx context.Context, t Trade) {
+	type update struct {
+		fn     CandleFunc
+		bar    Candle
+		closed bool
+	}
+
+	var updates []update
+
+	a.m.Lock()
+	if len(a.history) > 0 {
+		a.history[a.next] = t
+		a.next = (a.next + 1) % len(a.history)
+		a.full = a.full || a.next == 0
+	}
+
+	intervals := make([]time.Duration, 0, len(a.streams))
+	for interval := range a.streams {
+		intervals = append(intervals, interval)
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+
+	for _, interval := range intervals {
+		s := a.streams[interval]
+		start := t.Timestamp.Truncate(interval)
+		if s.bar != nil && s.bar.Start.Before(start) {
+			for _, fn := range s.subscribers() {
+				updates = append(updates, update{fn: fn, bar: *s.bar, closed: true})
+			}
+			s.bar = nil
+		}
+
+		if s.bar == nil {
+			s.bar = &Candle{Interval: interval, Start: start}
+		}
+
+		s.bar.add(t)
+		for _, fn := range s.subscribers() {
+			updates = append(updates, update{fn: fn, bar: *s.bar})
+		}
+	}
+	a.m.Unlock()
+
+	for _, u := range updates {
+		u.fn(u.bar, u.closed)
+	}
+}
+
+// Candles returns bars of the interval built from the trade history,
+// starting with the bar containing since. The last bar may be incomplete
+func (a *CandleAggregator) Candles(interval time.Duration, since time.Time) []Candle {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	return a.candles(interval, since.Truncate(interval))
+}
+
+func (a *CandleAggregator) candles(interval time.Duration, since time.Time) (bars []Candle) {
+	a.trades(func(t Trade) {
+		start := t.Timestamp.Truncate(interval)
+		if start.Before(since) {
+			return
+		}
+
+		if n := len(bars); n == 0 || bars[n-1].Start.Before(start) {
+			bars = append(bars, Candle{Interval: interval, Start: start})
+		}
+		bars[len(bars)-1].add(t)
+	})
+	return
+}
+
+// trades calls fn for trades of the history, oldest first
+func (a *CandleAggregator) trades(fn func(Trade)) {
+	if a.full {
+		for _, t := range a.history[a.next:] {
+			fn(t)
+		}
+	}
+
+	for _, t := range a.history[:a.next] {
+		fn(t)
+	}
+}
+
+// Subscribe calls fn on every update of bars of the interval until the
+// returned cancel function is called. The current bar is backfilled from the
+// trade history, fn is called with it immediately if it has trades
+func (a *CandleAggregator) Subscribe(interval time.Duration, fn CandleFunc) (cancel func()) {
+	a.m.Lock()
+
+	s, ok := a.streams[interval]
+	if !ok {
+		s = &candleStream{subs: make(map[int]CandleFunc)}
+		if bars := a.candles(interval, time.Time{}); len(bars) > 0 {
+			s.bar = &bars[len(bars)-1]
+		}
+		a.streams[interval] = s
+	}
+
+	a.subID++
+	id := a.subID
+	s.subs[id] = fn
+
+	var current *Candle
+	if s.bar != nil {
+		bar := *s.bar
+		current = &bar
+	}
+	a.m.Unlock()
+
+	if current != nil {
+		fn(*current, false)
+	}
+
+	return func() {
+		a.m.Lock()
+		defer a.m.Unlock()
+
+		delete(s.subs, id)
+		if len(s.subs) == 0 && a.streams[interval] == s {
+			delete(a.streams, interval)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCandleAggregator(t *testing.T) {
+	var (
+		start      = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		aggregator = NewCandleAggregator(3)
+		trade      = func(id uint64, offset time.Duration, price, quantity float64) {
+			aggregator.OnTrade(context.Background(), Trade{
+				ID:        id,
+				Price:     tFloat64(price),
+				Quantity:  tFloat64(quantity),
+				Timestamp: start.Add(offset),
+			})
+		}
+	)
+
+	trade(1, 0, 10, 1)
+	trade(2, 10*time.Second, 12, 2)
+
+	var (
+		bars   []Candle
+		closed []Candle
+	)
+
+	cancel := aggregator.Subscribe(time.Minute, func(c Candle, final bool) {
+		if final {
+			closed = append(closed, c)
+		} else {
+			bars = append(bars, c)
+		}
+	})
+
+	if len(bars) != 1 || bars[0].Open != tFloat64(10) || bars[0].Close != tFloat64(12) || bars[0].Volume != tFloat64(3) {
+		t.Fatal("current bar must be backfilled", bars)
+	}
+
+	trade(3, 20*time.Second, 9, 1)
+	trade(4, 70*time.Second, 11, 1)
+
+	if len(closed) != 1 ||
+		closed[0].Start != start ||
+		closed[0].High != tFloat64(12) ||
+		closed[0].Low != tFloat64(9) ||
+		closed[0].Close != tFloat64(9) ||
+		closed[0].Turnover != tFloat64(43) ||
+		closed[0].Trades != 3 {
+		t.Fatal("invalid closed bar", closed)
+	}
+
+	if last := bars[len(bars)-1]; len(bars) != 3 || last.Start != start.Add(time.Minute) || last.Open != tFloat64(11) {
+		t.Fatal("invalid current bar", bars)
+	}
+
+	cancel()
+	trade(5, 130*time.Second, 13, 1)
+
+	if len(bars) != 3 {
+		t.Fatal("canceled subscription must not be called")
+	}
+
+	// The first two trades are out of the history
+	candles := aggregator.Candles(time.Minute, time.Time{})
+	if len(candles) != 3 ||
+		candles[0].Open != tFloat64(9) ||
+		candles[0].Trades != 1 ||
+		candles[2].Close != tFloat64(13) {
+		t.Fatal("invalid history bars", candles)
+	}
+
+	if candles = aggregator.Candles(time.Hour, start.Add(90*time.Second)); len(candles) != 1 || candles[0].Trades != 3 {
+		t.Fatal("invalid hourly bar", candles)
+	}
+}
+
+func TestCandleAggregatorListener(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		aggregator       = NewCandleAggregator(10)
+		engine           = NewEngine(asset1, asset2, WithListener(aggregator))
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet2, false, 2, 10)))
+
+	if candles := aggregator.Candles(time.Minute, time.Time{}); len(candles) != 1 || candles[0].Volume != tFloat64(2) {
+		t.Fatal("engine trades must be aggregated", candles)
+	}
+}
+
+func TestCandleAggregatorOrder(t *testing.T) {
+	var (
+		aggregator = NewCandleAggregator(0)
+		calls      []string
+	)
+
+	for _, interval := range []time.Duration{time.Hour, time.Second, time.Minute} {
+		for _, name := range []string{"a", "b"} {
+			call := interval.String() + name
+			aggregator.Subscribe(interval, func(Candle, bool) { calls = append(calls, call) })
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		calls = nil
+		aggregator.OnTrade(context.Background(), Trade{Price: tFloat64(10), Quantity: tFloat64(1)})
+
+		if len(calls) != 6 || calls[0] != "1sa" || calls[1] != "1sb" || calls[2] != "1m0sa" || calls[5] != "1h0m0sb" {
+			t.Fatal("subscribers must be called in order", calls)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package fastme
+
+// bookCapture is the copy-on-write view of the order book. The capture
+// lists price levels under the engine lock, then the levels are read one by
+// one under the read lock, so commands are executed between the reads.
+// Commands save the captured level before its first change, so every level
+// is read as it was when the capture started
+type bookCapture struct {
+	asks []*capturedLevel // ascending
+	bids []*capturedLevel // ascending
+
+	// pending are levels neither read nor saved yet
+	pending map[*queue]*capturedLevel
+
+	formatter Formatter
+	state     TradingState
+	tradeSeq  uint64
+	lastPrice Value
+}
+
+type capturedLevel struct {
+	q      *queue // live level while pending
+	price  Value
+	volume Value
+	size   int
+	orders []capturedOrder
+}
+
+type capturedOrder struct {
+	id       string
+	clientID string
+	quantity Value
+}
+
+// capture starts the copy-on-write view of the order book, endCapture must
+// be called when the view is read
+func (e *Engine) capture() *bookCapture {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	c := &bookCapture{
+		pending:   make(map[*queue]*capturedLevel, e.asks.depth+e.bids.depth),
+		formatter: e.formatter,
+		state:     e.state,
+		tradeSeq:  e.tradeSeq,
+		lastPrice: e.lastPrice,
+	}
+
+	c.asks = c.levels(e.asks)
+	c.bids = c.levels(e.bids)
+
+	if e.captures == nil {
+		e.captures = make(map[*bookCapture]struct{})
+	}
+	e.captures[c] = struct{}{}
+	e.hookCaptures()
+
+	return c
+}
+
+// endCapture stops saving levels of the view
+func (e *Engine) endCapture(c *bookCapture) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	delete(e.captures, c)
+	e.hookCaptures()
+}
+
+// hookCaptures subscribes sides to changes of price levels while captures
+// are in progress, so no work is done otherwise
+func (e *Engine) hookCaptures() {
+	var mutating func(*queue)
+	if len(e.captures) > 0 {
+		mutating = e.preserve
+	}
+
+	e.asks.mutating = mutating
+	e.bids.mutating = mutating
+}
+
+// preserve saves the price level for captures pending it before the change
+func (e *Engine) preserve(q *queue) {
+	for c := range e.captures {
+		if l, ok := c.pending[q]; ok {
+			l.copy(q, true)
+			delete(c.pending, q)
+		}
+	}
+}
+
+func (c *bookCapture) levels(s *side) []*capturedLevel {
+	levels := make([]*capturedLevel, 0, s.depth)
+	for _, q := range s.ascending() {
+		l := &capturedLevel{q: q, price: q.price}
+		c.pending[q] = l
+		levels = append(levels, l)
+	}
+	return levels
+}
+
+// read returns the captured level reading it from the order book unless
+// it's saved. Orders of the level are copied if orders is set
+func (e *Engine) read(c *bookCapture, l *capturedLevel, orders bool) *capturedLevel {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	if _, ok := c.pending[l.q]; ok {
+		l.copy(l.q, orders)
+		if orders {
+			delete(c.pending, l.q)
+		}
+	}
+
+	return l
+}
+
+func (l *capturedLevel) copy(q *queue, orders bool) {
+	l.volume = q.volume
+	l.size = q.orders.Len()
+
+	if !orders {
+		return
+	}
+
+	l.orders = make([]capturedOrder, 0, l.size)
+	for el := q.orders.Front(); el != nil; el = el.Next() {
+		o := el.Value.(Order)
+		l.orders = append(l.orders, capturedOrder{
+			id:       o.ID(),
+			clientID: OrderClientID(o),
+			quantity: o.Quantity(),
+		})
+	}
+}
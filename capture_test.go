@@ -0,0 +1,213 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotCopyOnWrite(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+
+		before, after bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 100)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+
+	assertErr(t, engine.Snapshot(context.Background(), &before))
+
+	c := engine.capture()
+
+	// Asks are partially read before the book changes
+	asks := engine.snapshotLevels(c, c.asks[:1])
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("6", wallet1, true, 1, 12)))
+	assertErr(t, engine.ReduceOrder(context.Background(), nil, "2", tFloat64(1)))
+	assertErr(t, engine.AmendOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11), newOrder("3", wallet1, true, 2, 11)))
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "4"))
+
+	s := snapshot{
+		Version:  snapshotVersion,
+		Base:     asset1,
+		Quote:    asset2,
+		State:    c.state,
+		TradeSeq: c.tradeSeq,
+		Asks:     append(asks, engine.snapshotLevels(c, c.asks[1:])...),
+		Bids:     engine.snapshotLevels(c, c.bids),
+	}
+	engine.endCapture(c)
+
+	assertErr(t, json.NewEncoder(&after).Encode(s))
+
+	if before.String() != after.String() {
+		t.Fatal("captured book must not change", before.String(), after.String())
+	}
+
+	if len(engine.captures) != 0 || engine.asks.mutating != nil {
+		t.Fatal("ended capture must not be hooked")
+	}
+}
+
+func TestOrderBookCopyOnWrite(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		levels         []string
+	)
+
+	updateWalletBalance(wallet, asset1, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 2, 11)))
+
+	engine.OrderBook(func(asks bool, price, volume Value, len int) {
+		// The iteration doesn't hold the lock, commands are executed
+		if levels == nil {
+			assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+			assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet, true, 1, 12)))
+		}
+		levels = append(levels, price.Hash()+":"+volume.Hash())
+	})
+
+	if len(levels) != 2 || levels[0] != "11:2" || levels[1] != "10:1" {
+		t.Fatal("book must be iterated as it was when the call started", levels)
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		wg             sync.WaitGroup
+	)
+
+	updateWalletBalance(wallet1, asset1, 1e6)
+
+	for i := 0; i < 100; i++ {
+		engine.PushOrder(context.Background(), newOrder("a"+strconv.Itoa(i), wallet1, true, 1, float64(101+i%10)))
+	}
+
+	// Replacements keep the number of resting orders at every command boundary
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			o, err := engine.FindOrder("a" + strconv.Itoa(i))
+			assertErr(t, err)
+
+			_, err = engine.ReplaceOrder(context.Background(), nil, o, newOrder("c"+strconv.Itoa(i), wallet1, true, 1, float64(111+i%10)))
+			assertErr(t, err)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		var buf bytes.Buffer
+		assertErr(t, engine.Snapshot(context.Background(), &buf))
+
+		restored := NewEngine(asset1, asset2)
+		assertErr(t, restored.Restore(context.Background(), &buf, &tOrderFactory{}))
+
+		if n := len(restored.Orders()); n != 100 {
+			t.Fatal("snapshot must be consistent", n)
+		}
+	}
+
+	wg.Wait()
+}
+
+var (
+	largeBookOnce   sync.Once
+	largeBookEngine *Engine
+	largeBookWallet *tWallet
+)
+
+// largeBook returns the engine with 1M resting asks on 10k price levels
+func largeBook() (*Engine, *tWallet) {
+	largeBookOnce.Do(func() {
+		var (
+			asset1, asset2 = Asset("apples"), Asset("dollars")
+			wallet         = newWallet()
+			engine         = NewEngine(asset1, asset2)
+		)
+
+		wallet.UpdateBalance(context.Background(), asset1, tFloat64(1e12))
+		wallet.UpdateBalance(context.Background(), asset2, tFloat64(1e12))
+
+		for i := 0; i < 1000000; i++ {
+			engine.PushOrder(context.Background(), newOrder(strconv.Itoa(i), wallet, true, 1, float64(1000+i%10000)))
+		}
+
+		largeBookEngine, largeBookWallet = engine, wallet
+	})
+
+	return largeBookEngine, largeBookWallet
+}
+
+func BenchmarkSnapshot1M(b *testing.B) {
+	engine, _ := largeBook()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := engine.Snapshot(context.Background(), ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPlaceDuringSnapshot1M measures place and cancel commands executed
+// while snapshots of the 1M orders book are taken continuously
+func BenchmarkPlaceDuringSnapshot1M(b *testing.B) {
+	var (
+		engine, wallet = largeBook()
+		done           = make(chan struct{})
+		stopped        = make(chan struct{})
+	)
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = engine.Snapshot(context.Background(), ioutil.Discard)
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		o := newOrder("bench", wallet, false, 1, 10)
+		if err := engine.PlaceOrder(context.Background(), nil, o); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := engine.CancelOrder(context.Background(), nil, o); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.StopTimer()
+	close(done)
+	<-stopped
+}
@@ -0,0 +1,66 @@
+package fastme
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides current time to the engine. Replays and backtests may use
+// simulated clock to get deterministic timestamps
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of ordinary functions as Clock
+type ClockFunc func() time.Time
+
+// Now calls f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+type eventTimeKey struct{}
+
+// WithEventTime returns context carrying the event time. The engine uses it
+// instead of the clock for the command processed with that context
+func WithEventTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, eventTimeKey{}, t)
+}
+
+// EventTime returns the time of the event. The engine passes it within the
+// context to every EventListener and Wallet call of the command
+func EventTime(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(eventTimeKey{}).(time.Time)
+	return t, ok
+}
+
+// SetClock updates clock used for event timestamps, time windows and
+// expiration. Passing nil restores the system clock
+func (e *Engine) SetClock(c Clock) {
+	e.m.Lock()
+	e.clock = c
+	e.m.Unlock()
+}
+
+func (e *Engine) now() time.Time {
+	if e.clock == nil {
+		return time.Now()
+	}
+	return e.clock.Now()
+}
+
+// stamp returns context carrying the event time of the command
+func (e *Engine) stamp(ctx context.Context) context.Context {
+	if _, ok := EventTime(ctx); ok {
+		return ctx
+	}
+	return WithEventTime(ctx, e.now())
+}
+
+// eventTime returns the event time of the command
+func (e *Engine) eventTime(ctx context.Context) time.Time {
+	if t, ok := EventTime(ctx); ok {
+		return t
+	}
+	return e.now()
+}
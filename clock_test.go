@@ -0,0 +1,58 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type tTimeListener struct {
+	*tTradeListener
+	times []time.Time
+}
+
+func (t *tTimeListener) OnIncomingOrderPlaced(ctx context.Context, o Order) {
+	at, _ := EventTime(ctx)
+	t.times = append(t.times, at)
+}
+
+func (t *tTimeListener) OnExistingOrderCanceled(ctx context.Context, o Order) {
+	at, _ := EventTime(ctx)
+	t.times = append(t.times, at)
+}
+
+func TestClock(t *testing.T) {
+	var (
+		processor = &tTimeListener{
+			tTradeListener: &tTradeListener{tEventListener: newEventListener()},
+		}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		now    = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	engine.SetClock(ClockFunc(func() time.Time { return now }))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 2, 10)))
+
+	now = now.Add(time.Second)
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet2, false, 1, 10)))
+
+	replayed := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	assertErr(t, engine.CancelOrderByID(WithEventTime(context.Background(), replayed), processor, "1"))
+
+	if len(processor.times) != 2 ||
+		!processor.times[0].Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		!processor.times[1].Equal(replayed) {
+		t.Fatal("invalid event times", processor.times)
+	}
+
+	if len(processor.trades) != 1 || !processor.trades[0].Timestamp.Equal(now) {
+		t.Fatal("invalid trade timestamp", processor.trades)
+	}
+}
@@ -0,0 +1,111 @@
+package fastme
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type debugSide struct {
+	Levels int    `json:"levels"`
+	Orders int    `json:"orders"`
+	Best   string `json:"best,omitempty"`
+
+	// Allocated and Reused count price levels taken from the level pool
+	Allocated uint64 `json:"allocated_levels"`
+	Reused    uint64 `json:"reused_levels"`
+}
+
+// debugJournal describes the journal. Records are written synchronously by
+// commands, so there is no lag between the order book and the journal
+type debugJournal struct {
+	Seq uint64 `json:"seq"`
+}
+
+// debugLock counts acquisitions of the engine lock which had to wait for
+// other holders, see engineMutex
+type debugLock struct {
+	Writes uint64 `json:"contended_writes"`
+	Reads  uint64 `json:"contended_reads"`
+}
+
+type debugInfo struct {
+	Base            Asset     `json:"base"`
+	Quote           Asset     `json:"quote"`
+	State           string    `json:"state"`
+	Orders          int       `json:"orders"`
+	Asks            debugSide `json:"asks"`
+	Bids            debugSide `json:"bids"`
+	LastPrice       string    `json:"last_price,omitempty"`
+	CircuitBreaker  bool      `json:"circuit_breaker"`
+	ProtectedMakers int       `json:"protected_makers"`
+	Lock            debugLock `json:"lock"`
+
+	Journal *debugJournal `json:"journal,omitempty"`
+	Audit   *auditSummary `json:"audit,omitempty"`
+}
+
+// DebugHandler returns read-only HTTP handler exposing as JSON the order book
+// counters, the lock contention counters, the level pool usage, the journal
+// sequence number and the result of the last Audit. It's intended to be mounted on an operator-only debug
+// server, e.g. next to net/http/pprof handlers
+func DebugHandler(e *Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(e.debugInfo())
+	})
+}
+
+func (e *Engine) debugInfo() debugInfo {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	info := debugInfo{
+		Base:            e.base,
+		Quote:           e.quote,
+		State:           e.state.String(),
+		Orders:          len(e.orders),
+		Asks:            e.asks.debug(),
+		Bids:            e.bids.debug(),
+		CircuitBreaker:  e.breaker != nil,
+		ProtectedMakers: len(e.protected),
+	}
+
+	info.Lock.Writes, info.Lock.Reads = e.m.contended()
+
+	if q := e.asks.minPrice(); q != nil {
+		info.Asks.Best = e.format(q.price)
+	}
+
+	if q := e.bids.maxPrice(); q != nil {
+		info.Bids.Best = e.format(q.price)
+	}
+
+	if e.lastPrice != nil {
+		info.LastPrice = e.format(e.lastPrice)
+	}
+
+	if e.journal != nil {
+		info.Journal = &debugJournal{Seq: e.journal.seq}
+	}
+
+	if a, ok := e.audited.Load().(auditSummary); ok {
+		info.Audit = &a
+	}
+
+	return info
+}
+
+func (s *side) debug() debugSide {
+	return debugSide{
+		Levels:    s.depth,
+		Orders:    s.numOrders,
+		Allocated: s.allocated,
+		Reused:    s.reused,
+	}
+}
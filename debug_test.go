@@ -0,0 +1,65 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 1, 12)))
+
+	rec := httptest.NewRecorder()
+	DebugHandler(engine).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/fastme", nil))
+
+	var info debugInfo
+	assertErr(t, json.NewDecoder(rec.Body).Decode(&info))
+
+	if info.Orders != 2 ||
+		info.Asks.Levels != 2 ||
+		info.Asks.Best != "10" ||
+		info.Asks.Allocated+info.Asks.Reused != 2 ||
+		info.Bids.Best != "" ||
+		info.State != "open" ||
+		info.Lock.Writes != 0 ||
+		info.Journal != nil ||
+		info.Audit != nil {
+		t.Fatal("invalid result", info)
+	}
+
+	var buf bytes.Buffer
+	engine.SetJournal(NewJournal(&buf))
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "2"))
+
+	_, err := engine.Audit(context.Background())
+	assertErr(t, err)
+
+	rec = httptest.NewRecorder()
+	DebugHandler(engine).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/fastme", nil))
+
+	info = debugInfo{}
+	assertErr(t, json.NewDecoder(rec.Body).Decode(&info))
+
+	if info.Journal == nil || info.Journal.Seq != 1 ||
+		info.Audit == nil || info.Audit.Orders != 1 || info.Audit.Violations != 0 {
+		t.Fatal("journal and audit must be reported", info)
+	}
+
+	rec = httptest.NewRecorder()
+	DebugHandler(engine).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/fastme", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatal("invalid status", rec.Code)
+	}
+}
@@ -0,0 +1,95 @@
+package fastme
+
+import "context"
+
+// DepthUpdate is the change of the price level aggregate volume
+type DepthUpdate struct {
+	// Seq is the sequence number of the update unique within the engine.
+	// Updates are numbered without gaps
+	Seq uint64
+
+	// Ask is set for the asks side
+	Ask bool
+
+	Price Value
+
+	// Volume is the new aggregate volume, zero if the level is removed
+	Volume Value
+}
+
+// DepthListener receives order book level changes. It's called under the
+// engine lock in the order of changes, so it must not call the engine
+type DepthListener interface {
+	OnDepthChanged(context.Context, DepthUpdate)
+}
+
+// LevelListener is an optional DepthListener extension informing about
+// price levels lifecycle, so the mirrored order book is maintained without
+// looking the price up. The hook is called after OnDepthChanged with the
+// same update
+type LevelListener interface {
+	// OnLevelAdded is called when the first order rests at the price
+	OnLevelAdded(context.Context, DepthUpdate)
+
+	// OnLevelRemoved is called when the last order leaves the price
+	OnLevelRemoved(context.Context, DepthUpdate)
+
+	// OnLevelVolumeChanged is called when the volume of the existing price
+	// level is changed
+	OnLevelVolumeChanged(context.Context, DepthUpdate)
+}
+
+// levelChange is the kind of the price level change
+type levelChange int
+
+const (
+	levelVolumeChanged levelChange = iota
+	levelAdded
+	levelRemoved
+)
+
+// SetDepthListener sets the listener receiving level changes of all
+// commands, nil disables it. Restore replaces the order book without
+// updates, the listener must take the new book with Depth or L2
+func (e *Engine) SetDepthListener(l DepthListener) {
+	e.m.Lock()
+	e.depth = l
+	e.m.Unlock()
+}
+
+// hookDepth subscribes the engine to level changes of the order book sides
+func (e *Engine) hookDepth() {
+	e.asks.changed = func(ctx context.Context, q *queue, c levelChange) { e.depthChanged(ctx, true, q, c) }
+	e.bids.changed = func(ctx context.Context, q *queue, c levelChange) { e.depthChanged(ctx, false, q, c) }
+}
+
+func (e *Engine) depthChanged(ctx context.Context, ask bool, q *queue, c levelChange) {
+	if e.depth == nil {
+		return
+	}
+
+	e.depthSeq++
+
+	u := DepthUpdate{
+		Seq:    e.depthSeq,
+		Ask:    ask,
+		Price:  q.price,
+		Volume: q.volume,
+	}
+
+	e.depth.OnDepthChanged(ctx, u)
+
+	l, ok := e.depth.(LevelListener)
+	if !ok {
+		return
+	}
+
+	switch c {
+	case levelAdded:
+		l.OnLevelAdded(ctx, u)
+	case levelRemoved:
+		l.OnLevelRemoved(ctx, u)
+	default:
+		l.OnLevelVolumeChanged(ctx, u)
+	}
+}
@@ -0,0 +1,197 @@
+package fastme
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+type tDepthListener struct {
+	seq  uint64
+	asks map[tFloat64]tFloat64
+	bids map[tFloat64]tFloat64
+}
+
+func (t *tDepthListener) OnDepthChanged(ctx context.Context, u DepthUpdate) {
+	if u.Seq != t.seq+1 {
+		panic("depth updates must be sequenced")
+	}
+	t.seq = u.Seq
+
+	levels := t.bids
+	if u.Ask {
+		levels = t.asks
+	}
+
+	if u.Volume.Sign() == 0 {
+		delete(levels, u.Price.(tFloat64))
+	} else {
+		levels[u.Price.(tFloat64)] = u.Volume.(tFloat64)
+	}
+}
+
+func TestDepthListener(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallets        = []*tWallet{newWallet(), newWallet()}
+		rnd            = rand.New(rand.NewSource(1))
+		ids            []string
+
+		listener = &tDepthListener{
+			asks: make(map[tFloat64]tFloat64),
+			bids: make(map[tFloat64]tFloat64),
+		}
+		engine = NewEngine(asset1, asset2, WithDepthListener(listener))
+	)
+
+	for _, w := range wallets {
+		updateWalletBalance(w, asset1, 1e6)
+		updateWalletBalance(w, asset2, 1e6)
+	}
+
+	for i := 0; i < 1000; i++ {
+		switch {
+		case len(ids) > 0 && rnd.Intn(4) == 0:
+			j := rnd.Intn(len(ids))
+			err := engine.CancelOrderByID(context.Background(), nil, ids[j])
+			if err != nil && err != ErrOrderNotFound {
+				t.Fatal(err)
+			}
+			ids = append(ids[:j], ids[j+1:]...)
+
+		case len(ids) > 0 && rnd.Intn(4) == 0:
+			o, err := engine.FindOrder(ids[rnd.Intn(len(ids))])
+			if err != nil {
+				continue
+			}
+
+			n := newOrder(o.ID(), o.Owner().(*tWallet), o.Sell(), float64(1+rnd.Intn(5)), float64(o.Price().(tFloat64)))
+			assertErr(t, engine.AmendOrder(context.Background(), nil, o, n))
+
+		default:
+			id := strconv.Itoa(i)
+			sell := rnd.Intn(2) == 0
+			assertErr(t, engine.PlaceOrder(context.Background(), nil,
+				newOrder(id, wallets[rnd.Intn(2)], sell, float64(1+rnd.Intn(5)), float64(1+rnd.Intn(20)))))
+			ids = append(ids, id)
+		}
+
+		asks, bids := engine.Depth(0)
+		if len(asks) != len(listener.asks) || len(bids) != len(listener.bids) {
+			t.Fatal("mirrored book must have the same levels")
+		}
+
+		for _, l := range asks {
+			if listener.asks[l.Price.(tFloat64)] != l.Volume {
+				t.Fatal("invalid mirrored ask level", l)
+			}
+		}
+
+		for _, l := range bids {
+			if listener.bids[l.Price.(tFloat64)] != l.Volume {
+				t.Fatal("invalid mirrored bid level", l)
+			}
+		}
+	}
+}
+
+type tLevelListener struct {
+	seq    uint64
+	levels map[bool]map[tFloat64]tFloat64
+}
+
+func (t *tLevelListener) OnDepthChanged(ctx context.Context, u DepthUpdate) {
+	t.seq = u.Seq
+}
+
+func (t *tLevelListener) OnLevelAdded(ctx context.Context, u DepthUpdate) {
+	t.check(u)
+	if _, ok := t.levels[u.Ask][u.Price.(tFloat64)]; ok {
+		panic("added level must be new")
+	}
+	t.levels[u.Ask][u.Price.(tFloat64)] = u.Volume.(tFloat64)
+}
+
+func (t *tLevelListener) OnLevelRemoved(ctx context.Context, u DepthUpdate) {
+	t.check(u)
+	if _, ok := t.levels[u.Ask][u.Price.(tFloat64)]; !ok || u.Volume.Sign() != 0 {
+		panic("removed level must exist")
+	}
+	delete(t.levels[u.Ask], u.Price.(tFloat64))
+}
+
+func (t *tLevelListener) OnLevelVolumeChanged(ctx context.Context, u DepthUpdate) {
+	t.check(u)
+	if _, ok := t.levels[u.Ask][u.Price.(tFloat64)]; !ok {
+		panic("changed level must exist")
+	}
+	t.levels[u.Ask][u.Price.(tFloat64)] = u.Volume.(tFloat64)
+}
+
+func (t *tLevelListener) check(u DepthUpdate) {
+	if u.Seq != t.seq {
+		panic("level hook must follow OnDepthChanged")
+	}
+}
+
+func TestLevelListener(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallets        = []*tWallet{newWallet(), newWallet()}
+		rnd            = rand.New(rand.NewSource(2))
+		ids            []string
+
+		listener = &tLevelListener{levels: map[bool]map[tFloat64]tFloat64{
+			true:  make(map[tFloat64]tFloat64),
+			false: make(map[tFloat64]tFloat64),
+		}}
+		engine = NewEngine(asset1, asset2, WithDepthListener(listener))
+	)
+
+	for _, w := range wallets {
+		updateWalletBalance(w, asset1, 1e6)
+		updateWalletBalance(w, asset2, 1e6)
+	}
+
+	for i := 0; i < 1000; i++ {
+		switch {
+		case len(ids) > 0 && rnd.Intn(4) == 0:
+			j := rnd.Intn(len(ids))
+			err := engine.CancelOrderByID(context.Background(), nil, ids[j])
+			if err != nil && err != ErrOrderNotFound {
+				t.Fatal(err)
+			}
+			ids = append(ids[:j], ids[j+1:]...)
+
+		case len(ids) > 0 && rnd.Intn(4) == 0:
+			o, err := engine.FindOrder(ids[rnd.Intn(len(ids))])
+			if err != nil {
+				continue
+			}
+
+			n := newOrder(o.ID(), o.Owner().(*tWallet), o.Sell(), float64(1+rnd.Intn(5)), float64(o.Price().(tFloat64)))
+			assertErr(t, engine.AmendOrder(context.Background(), nil, o, n))
+
+		default:
+			id := strconv.Itoa(i)
+			sell := rnd.Intn(2) == 0
+			assertErr(t, engine.PlaceOrder(context.Background(), nil,
+				newOrder(id, wallets[rnd.Intn(2)], sell, float64(1+rnd.Intn(5)), float64(1+rnd.Intn(20)))))
+			ids = append(ids, id)
+		}
+
+		asks, bids := engine.Depth(0)
+		for ask, levels := range map[bool][]Level{true: asks, false: bids} {
+			if len(levels) != len(listener.levels[ask]) {
+				t.Fatal("mirrored book must have the same levels")
+			}
+
+			for _, l := range levels {
+				if listener.levels[ask][l.Price.(tFloat64)] != l.Volume {
+					t.Fatal("invalid mirrored level", ask, l)
+				}
+			}
+		}
+	}
+}
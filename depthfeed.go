@@ -0,0 +1,131 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrSubscriberLagged is returned by DepthSubscription.Err when the buffer
+// of the subscriber overflowed and the subscription was closed
+var ErrSubscriberLagged = errors.New("Depth subscriber lagged behind")
+
+// BookSnapshot is all price levels of the order book taken with the
+// sequence number of the last depth update applied to it
+type BookSnapshot struct {
+	Seq  uint64
+	Asks []Level
+	Bids []Level
+}
+
+// DepthFeed distributes depth updates of the engine to subscribers. Each
+// subscriber receives the order book snapshot and then every update
+// following it, without gaps or duplicates, so the mirrored book stays
+// equal to the engine one. The feed is the engine DepthListener, see
+// NewDepthFeed. Restore replaces the order book without updates, so
+// subscribers must subscribe again after it
+type DepthFeed struct {
+	engine *Engine
+	subs   map[*DepthSubscription]struct{}
+	m      sync.Mutex
+}
+
+// DepthSubscription is the subscription of the DepthFeed
+type DepthSubscription struct {
+	// Snapshot is the order book at the moment of subscription
+	Snapshot BookSnapshot
+
+	// Updates receives updates following the snapshot starting with the
+	// Snapshot.Seq+1 sequence number. It's closed by Close or when the
+	// subscriber lags behind
+	Updates <-chan DepthUpdate
+
+	feed    *DepthFeed
+	updates chan DepthUpdate
+	err     error
+}
+
+// NewDepthFeed creates the feed and sets it as the DepthListener of the
+// engine, replacing the previous one
+func NewDepthFeed(e *Engine) *DepthFeed {
+	f := &DepthFeed{
+		engine: e,
+		subs:   make(map[*DepthSubscription]struct{}),
+	}
+
+	e.SetDepthListener(f)
+	return f
+}
+
+// Subscribe takes the order book snapshot and subscribes to updates
+// following it. The snapshot is taken under the engine read lock, so no
+// command is executed between the snapshot and the subscription. Updates
+// are buffered up to buffer items, the subscription is closed with
+// ErrSubscriberLagged when the buffer is full, as the engine never waits
+// for subscribers
+func (f *DepthFeed) Subscribe(buffer int) *DepthSubscription {
+	e := f.engine
+
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	updates := make(chan DepthUpdate, buffer)
+	s := &DepthSubscription{
+		Snapshot: BookSnapshot{
+			Seq:  e.depthSeq,
+			Asks: depthLevels(e.asks.minPrice, e.asks.greaterThan, 0),
+			Bids: depthLevels(e.bids.maxPrice, e.bids.lessThan, 0),
+		},
+		Updates: updates,
+		feed:    f,
+		updates: updates,
+	}
+
+	f.m.Lock()
+	f.subs[s] = struct{}{}
+	f.m.Unlock()
+
+	return s
+}
+
+// OnDepthChanged sends the update to subscribers. It's called by the engine
+func (f *DepthFeed) OnDepthChanged(ctx context.Context, u DepthUpdate) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for s := range f.subs {
+		select {
+		case s.updates <- u:
+		default:
+			f.close(s, ErrSubscriberLagged)
+		}
+	}
+}
+
+// close removes the subscription, the feed lock must be held
+func (f *DepthFeed) close(s *DepthSubscription, err error) {
+	if _, ok := f.subs[s]; !ok {
+		return
+	}
+
+	delete(f.subs, s)
+	s.err = err
+	close(s.updates)
+}
+
+// Close unsubscribes and closes the Updates channel
+func (s *DepthSubscription) Close() {
+	s.feed.m.Lock()
+	defer s.feed.m.Unlock()
+
+	s.feed.close(s, nil)
+}
+
+// Err returns ErrSubscriberLagged if the subscription was closed because
+// the subscriber lagged behind, nil otherwise
+func (s *DepthSubscription) Err() error {
+	s.feed.m.Lock()
+	defer s.feed.m.Unlock()
+
+	return s.err
+}
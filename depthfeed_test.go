@@ -0,0 +1,106 @@
+package fastme
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestDepthFeed(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		feed   = NewDepthFeed(engine)
+		place  = func(id string, sell bool, quantity, price float64) {
+			assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder(id, wallet, sell, quantity, price)))
+		}
+	)
+
+	updateWalletBalance(wallet, asset1, 1000)
+	updateWalletBalance(wallet, asset2, 100000)
+
+	place("1", true, 2, 10)
+	place("2", false, 1, 9)
+
+	sub := feed.Subscribe(1000)
+	if len(sub.Snapshot.Asks) != 1 || len(sub.Snapshot.Bids) != 1 || sub.Snapshot.Seq != 2 {
+		t.Fatal("invalid snapshot", sub.Snapshot)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			place(fmt.Sprint("s", i), true, 1, float64(10+i%5))
+			place(fmt.Sprint("b", i), false, 1, float64(8+i%3))
+		}
+	}()
+
+	late := feed.Subscribe(1000)
+	<-done
+
+	for _, s := range []*DepthSubscription{sub, late} {
+		var (
+			book = make(map[string]string)
+			seq  = s.Snapshot.Seq
+		)
+
+		for _, l := range s.Snapshot.Asks {
+			book[fmt.Sprint(true, l.Price)] = fmt.Sprint(l.Volume)
+		}
+
+		for _, l := range s.Snapshot.Bids {
+			book[fmt.Sprint(false, l.Price)] = fmt.Sprint(l.Volume)
+		}
+
+	updates:
+		for {
+			select {
+			case u := <-s.Updates:
+				if u.Seq != seq+1 {
+					t.Fatal("updates must follow the snapshot without gaps", seq, u.Seq)
+				}
+				seq = u.Seq
+
+				if u.Volume.Sign() == 0 {
+					delete(book, fmt.Sprint(u.Ask, u.Price))
+				} else {
+					book[fmt.Sprint(u.Ask, u.Price)] = fmt.Sprint(u.Volume)
+				}
+			default:
+				break updates
+			}
+		}
+
+		asks, bids := engine.Depth(0)
+		if len(book) != len(asks)+len(bids) {
+			t.Fatal("mirrored book must match the engine one", book, asks, bids)
+		}
+
+		for _, l := range asks {
+			if book[fmt.Sprint(true, l.Price)] != fmt.Sprint(l.Volume) {
+				t.Fatal("mirrored ask must match", l, book)
+			}
+		}
+
+		for _, l := range bids {
+			if book[fmt.Sprint(false, l.Price)] != fmt.Sprint(l.Volume) {
+				t.Fatal("mirrored bid must match", l, book)
+			}
+		}
+
+		s.Close()
+		if _, ok := <-s.Updates; ok || s.Err() != nil {
+			t.Fatal("closed subscription must not have updates")
+		}
+	}
+
+	lagged := feed.Subscribe(0)
+	place("3", true, 1, 20)
+
+	if _, ok := <-lagged.Updates; ok || lagged.Err() != ErrSubscriberLagged {
+		t.Fatal("lagged subscription must be closed", lagged.Err())
+	}
+}
@@ -0,0 +1,83 @@
+package fastme
+
+import "errors"
+
+// ErrDivisionUnsupported is returned when Value doesn't implement DivValue
+var ErrDivisionUnsupported = errors.New("Value doesn't support division")
+
+// DivValue is an optional Value extension required to calculate average
+// prices and proportional allocations
+type DivValue interface {
+	Value
+
+	// Div is a "/" operation, the divisor is never zero
+	Div(Value) Value
+}
+
+// AvgPrice returns average price of the volume. Returns ErrInvalidQuantity
+// for empty volume
+func (v Volume) AvgPrice() (Value, error) {
+	if v.Quantity == nil || v.Quantity.Sign() <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	d, ok := v.Price.(DivValue)
+	if !ok {
+		return nil, ErrDivisionUnsupported
+	}
+
+	return d.Div(v.Quantity), nil
+}
+
+// Allocate splits total in proportion to weights, e.g. fill quantity among
+// orders of a price level by their sizes. Shares are rounded by Div of the
+// value type, the rounding remainder is added to the first share with the
+// largest weight, so shares always sum up to total. Zero and negative weights
+// get zero shares
+func Allocate(total Value, weights []Value) ([]Value, error) {
+	d, ok := total.(DivValue)
+	if !ok {
+		return nil, ErrDivisionUnsupported
+	}
+
+	var (
+		zero   = total.Sub(total)
+		sum    = zero
+		shares = make([]Value, len(weights))
+		max    = -1
+	)
+
+	for i, w := range weights {
+		if w.Sign() <= 0 {
+			continue
+		}
+
+		sum = w.Add(sum)
+		if max < 0 || w.Cmp(weights[max]) > 0 {
+			max = i
+		}
+	}
+
+	if max < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	allocated := zero
+	for i, w := range weights {
+		if w.Sign() <= 0 {
+			shares[i] = zero
+			continue
+		}
+
+		product, ok := d.Mul(w).(DivValue)
+		if !ok {
+			return nil, ErrDivisionUnsupported
+		}
+
+		shares[i] = product.Div(sum)
+		allocated = shares[i].Add(allocated)
+	}
+
+	shares[max] = total.Sub(allocated).Add(shares[max])
+	return shares, nil
+}
@@ -0,0 +1,55 @@
+package fastme
+
+import "testing"
+
+// tInt64 is the integer value truncating division
+type tInt64 int64
+
+func (t tInt64) Add(n Value) Value { return t + t.checkNil(n) }
+func (t tInt64) Sub(n Value) Value { return t - t.checkNil(n) }
+func (t tInt64) Mul(n Value) Value { return t * t.checkNil(n) }
+func (t tInt64) Div(n Value) Value { return t / t.checkNil(n) }
+func (t tInt64) Hash() string      { return tFloat64(t).Hash() }
+func (t tInt64) Sign() int         { return tFloat64(t).Sign() }
+
+func (t tInt64) Cmp(n Value) int {
+	return tFloat64(t).Cmp(tFloat64(t.checkNil(n)))
+}
+
+func (t tInt64) checkNil(v Value) tInt64 {
+	if v != nil {
+		return v.(tInt64)
+	}
+	return 0
+}
+
+func TestAvgPrice(t *testing.T) {
+	avg, err := Volume{Price: tInt64(25), Quantity: tInt64(5)}.AvgPrice()
+	if err != nil || avg != tInt64(5) {
+		t.Fatal("invalid average price", avg, err)
+	}
+
+	if _, err := (Volume{Price: tFloat64(25), Quantity: tFloat64(5)}).AvgPrice(); err != ErrDivisionUnsupported {
+		t.Fatal("values without Div must be rejected")
+	}
+
+	if _, err := (Volume{Price: tInt64(0), Quantity: tInt64(0)}).AvgPrice(); err != ErrInvalidQuantity {
+		t.Fatal("empty volume must be rejected")
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	shares, err := Allocate(tInt64(10), []Value{tInt64(1), tInt64(3), tInt64(0), tInt64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 10*1/7 = 1, 10*3/7 = 4, remainder 1 goes to the first largest weight
+	if shares[0] != tInt64(1) || shares[1] != tInt64(5) || shares[2] != tInt64(0) || shares[3] != tInt64(4) {
+		t.Fatal("invalid shares", shares)
+	}
+
+	if _, err := Allocate(tInt64(10), []Value{tInt64(0)}); err != ErrInvalidQuantity {
+		t.Fatal("zero weights must be rejected")
+	}
+}
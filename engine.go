@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 )
 
 // Fast matching engine errors
@@ -27,35 +28,99 @@ var (
 	ErrOrderExists = errors.New("Order with given ID already exists")
 
 	ErrOrderNotFound = errors.New("Order with given ID not found")
+
+	ErrNoAuction = errors.New("Engine is not in auction phase")
+
+	ErrAuctionMarketOrder = errors.New("Market orders are not accepted during auction")
+
+	ErrCircuitBreaker = errors.New("Circuit breaker tripped, trading is halted")
+
+	ErrAuctionOnly = errors.New("Auction-only order is accepted during auction only")
 )
 
-// Engine implements fast matching engine
+// Engine implements fast matching engine. Given the same commands in the
+// same order, the engine produces the same events in the same order: orders
+// are matched, canceled and returned in the price-time order and never in
+// the order of map iteration, so replicas fed by the replicated log stay
+// identical
 type Engine struct {
 	base       Asset
 	quote      Asset
 	orders     map[string]*list.Element // OrderID() -> *list.Element.Value.(Order)
+	owned      map[Wallet]map[string]*list.Element
+	clients    map[clientKey]string // client order ID -> OrderID()
 	asks       *side
 	bids       *side
 	feeHandler FeeHandler
-	m          sync.Mutex
-}
-
-// NewEngine creates fast matching engine implementation
-func NewEngine(base, quote Asset) *Engine {
-	return &Engine{
-		base:   base,
-		quote:  quote,
-		orders: make(map[string]*list.Element),
-		asks:   newSide(),
-		bids:   newSide(),
-	}
+	formatter  Formatter
+	breaker    *CircuitBreaker
+	policy     TakerPolicy
+	spec       *SymbolSpec
+	ladder     *PriceLadder
+	indexType  IndexType
+	state      TradingState
+	lastPrice  Value
+	protected  map[Wallet]*makerGuard
+	reference  *referencePrices
+	ticker     *tickerStats
+	archived   *archive
+	journal    *Journal
+	clock      Clock
+	tracer     Tracer
+	logger     Logger
+	listener   EventListener
+	tradeSeq   uint64
+	depthSeq   uint64
+	activity   activity
+	depth      DepthListener
+	corrupted  error // PanicError or WalletError
+	strict     bool  // strict funds mode
+	matchOnly  bool  // wallet accounting is disabled
+	views      bool  // listeners receive OrderView copies
+	feeWallet  Wallet
+	rebates    Wallet
+	risk       RiskChecker
+	limits     walletLimits
+	limiter    *rateLimiter
+	blocked    map[Wallet]struct{}
+	captures   map[*bookCapture]struct{}
+	audited    atomic.Value // auditSummary of the last complete Audit
+	closed     bool
+	done       chan struct{}
+	commands   chan Command
+	running    bool
+	m          engineMutex
+}
+
+// NewEngine creates fast matching engine implementation configured by the options
+func NewEngine(base, quote Asset, opts ...Option) *Engine {
+	e := &Engine{
+		base:      base,
+		quote:     quote,
+		orders:    make(map[string]*list.Element),
+		owned:     make(map[Wallet]map[string]*list.Element),
+		clients:   make(map[clientKey]string),
+		formatter: hashFormatterValue,
+		listener:  emptyListenerValue,
+	}
+
+	e.m.waits = new(lockWaits)
+	e.asks = newSide(newTreeIndex())
+	e.bids = newSide(newTreeIndex())
+	e.hookDepth()
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
 }
 
 // NewEngineWithFeeHandler creates fast matching engine implementation
-func NewEngineWithFeeHandler(base, quote Asset, h FeeHandler) (me *Engine) {
-	me = NewEngine(base, quote)
-	me.SetFeeHandler(h)
-	return
+//
+// Deprecated: use NewEngine with WithFeeHandler option
+func NewEngineWithFeeHandler(base, quote Asset, h FeeHandler) *Engine {
+	return NewEngine(base, quote, WithFeeHandler(h))
 }
 
 // ----------------------------------------------------------
@@ -69,78 +134,238 @@ func (e *Engine) SetFeeHandler(h FeeHandler) {
 	e.m.Unlock()
 }
 
+// SetFormatter updates formatter used to render values for serialization
+func (e *Engine) SetFormatter(f Formatter) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if f == nil {
+		f = hashFormatterValue
+	}
+
+	e.formatter = f
+}
+
 // CanPlace calculates balance and retuns an error if is not enought money
-// to place an order with given params
+// to place an order with given params. Crossing quantity of the limit buy
+// order is charged at the ask prices, the remainder at the limit price.
+// Buyer funds are checked on every fill during matching as well.
+//
+// The wallet Balance is checked, which excludes funds reserved by resting
+// orders: the engine moves them to InOrder on placement. The result is the
+// snapshot of the current state only. PlaceOrder repeats the check
+// atomically with placement, so the order may still be rejected, and orders
+// validated but not placed yet are not accounted. Use CanPlaceOrders to
+// validate several orders together
 func (e *Engine) CanPlace(
 	ctx context.Context,
 	w Wallet,
 	sell bool,
 	quantity, price Value,
+) error {
+	return e.canPlace(ctx, w, sell, price != nil && price.Sign() == 0, quantity, price)
+}
+
+// canPlace is CanPlace with explicit order kind. Market order price is ignored
+func (e *Engine) canPlace(
+	ctx context.Context,
+	w Wallet,
+	sell, market bool,
+	quantity, price Value,
 ) error {
 	if quantity == nil || quantity.Sign() <= 0 {
-		return ErrInvalidQuantity
+		return invalidQuantity(quantity, ErrInvalidQuantity)
+	}
+
+	if market {
+		price = quantity.Sub(quantity)
+	} else if price == nil || price.Sign() < 0 {
+		return invalidPrice(price, ErrInvalidPrice)
+	}
+
+	if err := e.checkSpec(quantity, price); err != nil {
+		return err
 	}
 
-	if price == nil || price.Sign() < 0 {
-		return ErrInvalidPrice
+	if e.matchOnly {
+		return nil
 	}
 
 	var (
 		marketPrice Value
 		err         error
 	)
-	if price.Sign() == 0 {
+	if market {
 		if marketPrice, err = e.price(sell, quantity); err != nil {
 			return err
 		}
-	} else {
-		marketPrice = price.Mul(quantity)
+	} else if !sell {
+		marketPrice = e.buyCost(quantity, price)
 	}
 
+	var (
+		asset    = e.quote
+		required = marketPrice
+	)
 	if sell {
-		if w == nil || w.Balance(ctx, e.base).Cmp(quantity) < 0 {
-			return ErrInsufficientFunds
-		}
-	} else {
-		if w == nil || w.Balance(ctx, e.quote).Cmp(marketPrice) < 0 {
-			return ErrInsufficientFunds
+		asset = e.base
+		required = quantity
+	}
+
+	if w == nil {
+		return &InsufficientFundsError{Asset: asset, Required: required}
+	}
+
+	available, err := tryBalance(ctx, w, asset)
+	if err != nil {
+		return err
+	}
+
+	if available.Cmp(required) < 0 {
+		return &InsufficientFundsError{
+			Asset:     asset,
+			Required:  required,
+			Available: available,
 		}
 	}
 
 	return nil
 }
 
-// PlaceOrder order adds the order to the order book and solves exchange task
+// PlaceOrder order adds the order to the order book and solves exchange task.
+// The order is rejected if the context is done. Matching is stopped when the
+// context is done between fills, the remainder is canceled and the context
+// error is returned
 func (e *Engine) PlaceOrder(
 	ctx context.Context,
 	listener EventListener,
 	o Order,
-) (err error) {
+) error {
 	e.m.Lock()
 	defer e.m.Unlock()
 
-	if listener == nil {
-		listener = emptyListenerValue
-	}
+	return e.placeOrder(ctx, listener, o, nil)
+}
+
+// placeOrder validates and places the order, the lock must be held. The
+// report of the order processing is filled if given
+func (e *Engine) placeOrder(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+	r *Report,
+) (err error) {
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	ctx, span := e.trace(ctx, SpanPlaceOrder)
+
+	var fills []Fill
+	defer func() { span.End(spanInfo(o, fills, err)) }()
+
+	listener = e.listenerOf(listener)
 
 	if e.feeHandler == nil {
 		e.feeHandler = emptyFeeHandlerValue
 	}
 
+	if r != nil {
+		r.Remaining, r.Status = o.Quantity(), StatusRejected
+	}
+
+	if err := e.checkOrder(ctx, o); err != nil {
+		return e.reject(ctx, listener, o, err)
+	}
+
+	if err := e.record(ctx, journalPlace, "", o, nil); err != nil {
+		return err
+	}
+
+	fills, err = e.place(ctx, listener, o)
+
+	if r != nil {
+		e.report(r, o, fills)
+	}
+
+	return err
+}
+
+// checkOrder validates incoming order before placement
+func (e *Engine) checkOrder(ctx context.Context, o Order) error {
+	if err := e.unusable(); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := e.checkBlocked(ctx, o.Owner()); err != nil {
+		return err
+	}
+
+	if err := e.checkRate(ctx, o.Owner()); err != nil {
+		return err
+	}
+
 	if _, ok := e.orders[o.ID()]; ok {
 		return ErrOrderExists
 	}
 
-	if err := e.CanPlace(
+	if err := e.checkClientID(o, nil); err != nil {
+		return err
+	}
+
+	if err := checkKind(o); err != nil {
+		return err
+	}
+
+	if err := e.checkPlace(o); err != nil {
+		return err
+	}
+
+	if err := e.canPlace(
 		ctx,
 		o.Owner(),
 		o.Sell(),
+		isMarket(o),
 		o.Quantity(),
 		o.Price(),
 	); err != nil {
 		return err
 	}
 
+	if err := e.checkLimits(o, nil); err != nil {
+		return err
+	}
+
+	return e.checkRisk(ctx, o)
+}
+
+// place matches validated order against the order book and puts the
+// remainder to the queue. Returns fills generated on the way. If the context
+// is done between fills, matching stops, the remainder is canceled and the
+// context error is returned with executed fills
+func (e *Engine) place(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) (fills []Fill, err error) {
+	e.activity.placed++
+
+	if e.state == StateAuction {
+		if !e.reserve(ctx, o) {
+			e.cancelIncoming(ctx, listener, o)
+			return nil, nil
+		}
+
+		e.push(ctx, o)
+		listener.OnIncomingOrderPlaced(ctx, o)
+		e.updateBalanceOnPlaced(ctx, listener, o)
+		return nil, nil
+	}
+
 	var (
 		next    func() *queue
 		compare func(Value) bool
@@ -158,19 +383,40 @@ func (e *Engine) PlaceOrder(
 		}
 	}
 
-	if o.Price().Sign() == 0 {
+	if isMarket(o) {
 		compare = func(Value) bool { return true }
 	}
 
+	var (
+		band     = e.priceBand(e.eventTime(ctx))
+		tripped  bool
+		stopped  bool
+		unfunded bool
+
+		// Marketable limit order remainder is dropped as for market orders
+		dropRemainder = e.policy == TakerPolicyMarket && e.crosses(o)
+	)
+
 	// Side processing
 	bestPriceQueue := next()
 	for bestPriceQueue != nil &&
 		o.Quantity().Sign() > 0 &&
 		compare(bestPriceQueue.price) {
 
+		if band != nil && !band.contains(bestPriceQueue.price) {
+			tripped = true
+			break
+		}
+
 		// Queue processing
 		for bestPriceQueue.orders.Len() > 0 &&
 			o.Quantity().Sign() > 0 {
+			// Context is checked between fills, so executed fills are complete
+			if len(fills) > 0 && interrupted(ctx, len(fills)) {
+				stopped = true
+				break
+			}
+
 			var (
 				makerEl = bestPriceQueue.orders.Front()
 				maker   = makerEl.Value.(Order)
@@ -181,6 +427,19 @@ func (e *Engine) PlaceOrder(
 				volume   Volume
 			)
 
+			if e.strict && e.underfunded(ctx, maker) {
+				e.cancelUnderfunded(ctx, listener, maker)
+				continue
+			}
+
+			// The book may differ from the one funds were checked against,
+			// e.g. makers were pulled by the protection, so the buyer is
+			// checked on every fill
+			if !e.reserveFill(ctx, taker, maker) {
+				unfunded = true
+				break
+			}
+
 			// Matching
 			switch taker.Quantity().Cmp(maker.Quantity()) {
 			case 0: // taker qty == maker qty
@@ -225,59 +484,243 @@ func (e *Engine) PlaceOrder(
 				listener.OnExistingOrderPartial(ctx, maker, volume)
 				listener.OnIncomingOrderDone(ctx, taker, volume)
 			}
+
+			if maker.Quantity().Sign() == 0 {
+				e.archive(ctx, maker, StatusFilled)
+			}
+
+			if taker.Quantity().Sign() == 0 {
+				e.archive(ctx, taker, StatusFilled)
+			}
+
+			fills = append(fills, Fill{
+				MakerID:  maker.ID(),
+				Price:    maker.Price(),
+				Quantity: volume.Quantity,
+			})
+
+			e.trade(ctx, listener, maker, taker, maker.Price(), volume.Quantity)
+
+			if e.recordMakerFill(e.eventTime(ctx), maker.Owner(), volume.Quantity) {
+				e.pullMaker(ctx, listener, maker.Owner())
+			}
+		}
+
+		if stopped || unfunded {
+			break
 		}
 
 		bestPriceQueue = next()
 	}
 
 	if o.Quantity().Sign() > 0 {
-		e.push(ctx, o)
-		listener.OnIncomingOrderPlaced(ctx, o)
-		e.updateBalanceOnPlaced(ctx, listener, o)
+		if isMarket(o) ||
+			dropRemainder ||
+			stopped ||
+			unfunded ||
+			(tripped && e.breaker.Cancel) ||
+			!e.reserve(ctx, o) {
+			e.cancelIncoming(ctx, listener, o)
+		} else {
+			e.push(ctx, o)
+			listener.OnIncomingOrderPlaced(ctx, o)
+			e.updateBalanceOnPlaced(ctx, listener, o)
+		}
 	}
 
-	return nil
+	if stopped {
+		e.recordInterrupt(ctx, o, len(fills))
+		return fills, ctx.Err()
+	}
+
+	if tripped {
+		e.setState(ctx, listener, StateHalted)
+		return fills, ErrCircuitBreaker
+	}
+
+	return fills, nil
 }
 
-// ReplaceOrder replaces order at the same price level without queue loss
+// ReplaceOrder replaces resting order o with n. If the price is not changed,
+// the order is replaced at the same price level without queue loss. Otherwise
+// o is canceled and n is processed as incoming order losing time priority.
+// Returns fills generated by the new order
 func (e *Engine) ReplaceOrder(
 	ctx context.Context,
 	listener EventListener,
 	o, n Order,
-) error {
+) (fills []Fill, err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	ctx, span := e.trace(ctx, SpanReplaceOrder)
+	defer func() { span.End(spanInfo(n, fills, err)) }()
+
+	listener = e.listenerOf(listener)
+
+	if err := e.unusable(); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkBlocked(ctx, n.Owner()); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkRate(ctx, n.Owner()); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	orderEl, o, err := e.resting(o, n)
+	if err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkClientID(n, o); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkLimits(n, o); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkRisk(ctx, n); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.record(ctx, journalReplace, o.ID(), n, nil); err != nil {
+		return nil, err
+	}
+
+	if o.Price().Cmp(n.Price()) != 0 {
+		return e.replace(ctx, listener, o, n)
+	}
+
+	return nil, e.amend(ctx, listener, orderEl, o, n, false)
+}
+
+// AmendOrder replaces resting order o with n at the same price. Decreased
+// order keeps its queue position, increased one is moved to the back of the
+// price level queue
+func (e *Engine) AmendOrder(
+	ctx context.Context,
+	listener EventListener,
+	o, n Order,
+) (err error) {
 	e.m.Lock()
 	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	if err := e.unusable(); err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkBlocked(ctx, n.Owner()); err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkRate(ctx, n.Owner()); err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	orderEl, o, err := e.resting(o, n)
+	if err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	if o.Price().Cmp(n.Price()) != 0 {
+		return e.reject(ctx, listener, n, invalidPrice(n.Price(), ErrInvalidPrice))
+	}
+
+	if err := e.checkClientID(n, o); err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkLimits(n, o); err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.checkRisk(ctx, n); err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	if err := e.record(ctx, journalAmend, o.ID(), n, nil); err != nil {
+		return err
+	}
+
+	listener = e.listenerOf(listener)
+
+	return e.amend(ctx, listener, orderEl, o, n, n.Quantity().Cmp(o.Quantity()) > 0)
+}
+
+// resting validates replacement n of the order o and returns the order
+// book element with the resting order
+func (e *Engine) resting(o, n Order) (*list.Element, Order, error) {
+	if e.state == StateHalted {
+		return nil, nil, ErrTradingHalted
+	}
 
 	orderEl, ok := e.orders[o.ID()]
 	if !ok {
-		return ErrOrderNotFound
+		return nil, nil, ErrOrderNotFound
 	}
 
 	o, ok = orderEl.Value.(Order)
 	if !ok {
-		return ErrInvalidOrder
+		return nil, nil, ErrInvalidOrder
 	}
 
 	if o.Owner() != n.Owner() {
-		return ErrInvalidOrder
+		return nil, nil, ErrInvalidOrder
 	}
 
 	if o.Sell() != n.Sell() {
-		return ErrInvalidOrder
+		return nil, nil, ErrInvalidOrder
 	}
 
-	if o.Price().Cmp(n.Price()) != 0 {
-		return ErrInvalidOrder
+	if n.ID() != o.ID() {
+		if _, ok := e.orders[n.ID()]; ok {
+			return nil, nil, ErrOrderExists
+		}
 	}
 
-	if n.Quantity().Sign() <= 0 {
-		return ErrInvalidQuantity
+	if n.Quantity() == nil || n.Quantity().Sign() <= 0 {
+		return nil, nil, invalidQuantity(n.Quantity(), ErrInvalidQuantity)
 	}
 
-	if listener == nil {
-		listener = emptyListenerValue
+	if err := checkKind(n); err != nil {
+		return nil, nil, err
 	}
 
+	// Market orders could not rest in the order book
+	if isMarket(n) || n.Price() == nil || n.Price().Sign() < 0 {
+		return nil, nil, invalidPrice(n.Price(), ErrInvalidPrice)
+	}
+
+	if err := e.checkSpec(n.Quantity(), n.Price()); err != nil {
+		return nil, nil, err
+	}
+
+	return orderEl, o, nil
+}
+
+// amend replaces resting order o with n at the same price level. The order
+// is moved to the back of the queue if toBack is set
+func (e *Engine) amend(
+	ctx context.Context,
+	listener EventListener,
+	orderEl *list.Element,
+	o, n Order,
+	toBack bool,
+) error {
 	var (
 		wallet     = o.Owner()
 		asset      Asset
@@ -300,108 +743,343 @@ func (e *Engine) ReplaceOrder(
 		newValue = n.Price().Mul(n.Quantity())
 	}
 
-	newBalance = oldValue.
-		Sub(newValue).
-		Add(wallet.Balance(ctx, asset))
+	queue := orderSide.level(n.Price())
+	if queue == nil {
+		return e.reject(ctx, listener, n, invalidPrice(n.Price(), ErrInvalidPrice))
+	}
+
+	if e.matchOnly {
+		e.reorder(ctx, queue, orderEl, o, n, toBack)
+		return nil
+	}
+
+	reserver, reserving := wallet.(Reserver)
+
+	available, err := tryBalance(ctx, wallet, asset)
+	if err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	inOrder, err := tryInOrder(ctx, wallet, asset)
+	if err != nil {
+		return e.reject(ctx, listener, n, err)
+	}
+
+	newBalance = oldValue.
+		Sub(newValue).
+		Add(available)
+
+	if delta := newValue.Sub(oldValue); newBalance.Sign() < 0 ||
+		(reserving && delta.Sign() > 0 && !reserver.Reserve(ctx, asset, delta)) {
+		return e.reject(ctx, listener, n, &InsufficientFundsError{
+			Asset:     asset,
+			Required:  delta,
+			Available: available,
+		})
+	}
+
+	newInOrder = newValue.
+		Sub(oldValue).
+		Add(inOrder)
+
+	e.reorder(ctx, queue, orderEl, o, n, toBack)
+
+	if reserving {
+		if delta := oldValue.Sub(newValue); delta.Sign() > 0 {
+			reserver.Release(ctx, asset, delta)
+		}
+
+		e.notifyBalance(ctx, listener, wallet, asset)
+		e.notifyInOrder(ctx, listener, wallet, asset)
+		return nil
+	}
+
+	setBalance(ctx, wallet, asset, newBalance)
+	listener.OnBalanceChanged(ctx, wallet, asset, newBalance)
+
+	setInOrder(ctx, wallet, asset, newInOrder)
+	listener.OnInOrderChanged(ctx, wallet, asset, newInOrder)
+
+	return nil
+}
+
+// reorder replaces resting order o with n in the queue
+func (e *Engine) reorder(
+	ctx context.Context,
+	queue *queue,
+	orderEl *list.Element,
+	o, n Order,
+	toBack bool,
+) {
+	queue.mutating()
+
+	e.disown(o)
+	orderEl.Value = n
+
+	delete(e.orders, o.ID())
+	e.orders[n.ID()] = orderEl
+	e.own(orderEl)
+
+	queue.volume = n.Quantity().
+		Sub(o.Quantity()).
+		Add(queue.volume)
+
+	if n.Quantity().Cmp(o.Quantity()) != 0 {
+		queue.changed(ctx, levelVolumeChanged)
+	}
+
+	if toBack {
+		queue.orders.MoveToBack(orderEl)
+	}
+}
+
+// replace cancels resting order o and processes n as incoming order
+func (e *Engine) replace(
+	ctx context.Context,
+	listener EventListener,
+	o, n Order,
+) ([]Fill, error) {
+	if err := e.checkPlace(n); err != nil {
+		return nil, e.reject(ctx, listener, n, err)
+	}
+
+	if !e.matchOnly {
+		var (
+			wallet   = o.Owner()
+			asset    Asset
+			reserved Value
+			required Value
+		)
+
+		if o.Sell() {
+			asset = e.base
+			reserved = o.Quantity()
+			required = n.Quantity()
+		} else {
+			asset = e.quote
+			reserved = o.Price().Mul(o.Quantity())
+			required = n.Price().Mul(n.Quantity())
+		}
+
+		balance, err := tryBalance(ctx, wallet, asset)
+		if err != nil {
+			return nil, e.reject(ctx, listener, n, err)
+		}
+
+		// Assets reserved by the old order are available for the new one
+		if available := reserved.Add(balance); available.Cmp(required) < 0 {
+			return nil, e.reject(ctx, listener, n, &InsufficientFundsError{
+				Asset:     asset,
+				Required:  required,
+				Available: available,
+			})
+		}
+	}
+
+	if e.feeHandler == nil {
+		e.feeHandler = emptyFeeHandlerValue
+	}
+
+	e.cancel(ctx, listener, o)
+	listener.OnExistingOrderCanceled(ctx, o)
+
+	return e.place(ctx, listener, n)
+}
+
+// CancelOrder removes order from the order book and refund assets to the owner.
+// Returns ErrOrderNotFound if the order is not in the order book
+func (e *Engine) CancelOrder(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) error {
+	return e.CancelOrderByID(ctx, listener, o.ID())
+}
+
+// CancelOrderByID removes order with given ID from the order book and refund
+// assets to the owner. Returns ErrOrderNotFound if the order is not in the order book
+func (e *Engine) CancelOrderByID(
+	ctx context.Context,
+	listener EventListener,
+	id string,
+) (err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	return e.cancelOrderByID(e.stamp(ctx), listener, id)
+}
 
-	if newBalance.Sign() < 0 {
-		return ErrInsufficientFunds
+// cancelOrderByID cancels the resting order, the lock must be held
+func (e *Engine) cancelOrderByID(
+	ctx context.Context,
+	listener EventListener,
+	id string,
+) (err error) {
+	if err := e.unusable(); err != nil {
+		return err
 	}
 
-	queue, ok := orderSide.prices[n.Price().Hash()]
+	ctx, span := e.trace(ctx, SpanCancelOrder)
+
+	info := SpanInfo{OrderID: id}
+	defer func() {
+		info.Err = err
+		span.End(info)
+	}()
+
+	el, ok := e.orders[id]
 	if !ok {
-		return ErrInvalidPrice
+		e.log(ctx, LogWarn, "cancel rejected",
+			LogField{"order_id", id},
+			LogField{"error", ErrOrderNotFound},
+		)
+		return ErrOrderNotFound
 	}
 
-	newInOrder = newValue.
-		Sub(oldValue).
-		Add(wallet.InOrder(ctx, asset))
+	o := el.Value.(Order)
+	if err := e.checkRate(ctx, o.Owner()); err != nil {
+		e.log(ctx, LogWarn, "cancel rejected",
+			LogField{"order_id", id},
+			LogField{"error", err},
+		)
+		return err
+	}
 
-	orderEl.Value = n
+	if err := e.record(ctx, journalCancel, id, nil, nil); err != nil {
+		return err
+	}
 
-	delete(e.orders, o.ID())
-	e.orders[n.ID()] = orderEl
+	listener = e.listenerOf(listener)
 
-	queue.volume = n.Quantity().
-		Sub(o.Quantity()).
-		Add(queue.volume)
+	info.Sell = o.Sell()
+	e.cancel(ctx, listener, o)
+	listener.OnExistingOrderCanceled(ctx, o)
+	return nil
+}
 
-	wallet.UpdateBalance(ctx, asset, newBalance)
-	listener.OnBalanceChanged(ctx, wallet, asset, newBalance)
+// CancelAll removes all resting orders of the wallet from the order book in
+// one lock acquisition and refunds assets to the owner. Returns canceled orders
+func (e *Engine) CancelAll(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+) []Order {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, nil)
 
-	wallet.UpdateInOrder(ctx, asset, newInOrder)
-	listener.OnInOrderChanged(ctx, wallet, asset, newInOrder)
+	ctx = e.stamp(ctx)
 
-	return nil
+	if e.unusable() != nil {
+		return nil
+	}
+
+	return e.cancelAllJournaled(ctx, listener, w, e.asks, e.bids)
 }
 
-// CancelOrder removes order from the order book and refund assets to the owner
-func (e *Engine) CancelOrder(
+// CancelAllSide is the same as CancelAll, but removes only sell or buy orders
+func (e *Engine) CancelAllSide(
 	ctx context.Context,
 	listener EventListener,
-	o Order,
-) {
+	w Wallet,
+	sell bool,
+) []Order {
 	e.m.Lock()
 	defer e.m.Unlock()
+	defer e.guard(ctx, nil)
 
-	if listener == nil {
-		listener = emptyListenerValue
+	ctx = e.stamp(ctx)
+
+	if e.unusable() != nil {
+		return nil
 	}
 
-	e.pull(ctx, o)
+	if sell {
+		return e.cancelAllJournaled(ctx, listener, w, e.asks)
+	}
+	return e.cancelAllJournaled(ctx, listener, w, e.bids)
+}
 
-	var (
-		wallet = o.Owner()
-		value  Value
-		asset  Asset
-	)
+// cancelAllJournaled journals and cancels resting orders of the wallet one
+// by one. Cancellation stops on the journal error, canceled orders are returned
+func (e *Engine) cancelAllJournaled(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	sides ...*side,
+) []Order {
+	listener = e.listenerOf(listener)
+
+	orders := e.ordersOf(w, sides...)
+	for i, o := range orders {
+		if err := e.record(ctx, journalCancel, o.ID(), nil, nil); err != nil {
+			return orders[:i]
+		}
 
-	if o.Sell() {
-		value = o.Quantity()
-		asset = e.base
-	} else {
-		value = o.Quantity().Mul(o.Price())
-		asset = e.quote
+		e.cancel(ctx, listener, o)
+		listener.OnExistingOrderCanceled(ctx, o)
 	}
 
-	valBalance := value.Add(wallet.Balance(ctx, asset))
-	wallet.UpdateBalance(ctx, asset, valBalance)
-	listener.OnBalanceChanged(ctx, wallet, asset, valBalance)
+	return orders
+}
 
-	valInOrder := wallet.InOrder(ctx, asset).Sub(value)
-	wallet.UpdateInOrder(ctx, asset, valInOrder)
-	listener.OnInOrderChanged(ctx, wallet, asset, valInOrder)
+func (e *Engine) cancelAll(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	sides ...*side,
+) []Order {
+	listener = e.listenerOf(listener)
 
-	listener.OnExistingOrderCanceled(ctx, o)
+	orders := e.ordersOf(w, sides...)
+	for _, o := range orders {
+		e.cancel(ctx, listener, o)
+		listener.OnExistingOrderCanceled(ctx, o)
+	}
+
+	return orders
 }
 
 // PushOrder puts the order to the queue without any calculations
 func (e *Engine) PushOrder(ctx context.Context, o Order) {
 	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, nil)
+
 	e.push(ctx, o)
-	e.m.Unlock()
 }
 
-// Quantity returns quantity for price limit
+// Quantity returns quantity for price limit. Price level at exactly the
+// limit price is included
 func (e *Engine) Quantity(sell bool, priceLim Value) Value {
-	e.m.Lock()
-	defer e.m.Unlock()
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return e.quantity(sell, priceLim, true)
+}
+
+// QuantityLimit returns quantity available at or better than the price limit
+// if inclusive is set and strictly better than the price limit otherwise
+func (e *Engine) QuantityLimit(sell bool, priceLim Value, inclusive bool) Value {
+	e.m.RLock()
+	defer e.m.RUnlock()
 
-	return e.quantity(sell, priceLim)
+	return e.quantity(sell, priceLim, inclusive)
 }
 
 // Price returns market price of given quantity
 func (e *Engine) Price(sell bool, quantity Value) (Value, error) {
-	e.m.Lock()
-	defer e.m.Unlock()
+	e.m.RLock()
+	defer e.m.RUnlock()
 
 	return e.price(sell, quantity)
 }
 
 // Spread returns best bid and best ask
 func (e *Engine) Spread() (bestAsk, bestBid Value) {
-	e.m.Lock()
-	defer e.m.Unlock()
+	e.m.RLock()
+	defer e.m.RUnlock()
 
 	asksQueue := e.asks.minPrice()
 	bidsQueue := e.bids.maxPrice()
@@ -417,10 +1095,11 @@ func (e *Engine) Spread() (bestAsk, bestBid Value) {
 	return
 }
 
-// FindOrder returns order bygiven ID
+// FindOrder returns order bygiven ID. Completed orders are not in the order
+// book anymore, use FindArchived to look them up
 func (e *Engine) FindOrder(id string) (Order, error) {
-	e.m.Lock()
-	defer e.m.Unlock()
+	e.m.RLock()
+	defer e.m.RUnlock()
 
 	el, ok := e.orders[id]
 	if !ok {
@@ -430,37 +1109,45 @@ func (e *Engine) FindOrder(id string) (Order, error) {
 	return el.Value.(Order), nil
 }
 
-// Orders returns all existing limit orders
+// Orders returns all existing limit orders. Orders are sorted by side (asks
+// first), ascending price and time, so the result does not depend on map
+// iteration order. RestingOrders iterates orders in the same order
 func (e *Engine) Orders() (orders []Order) {
-	e.m.Lock()
-	defer e.m.Unlock()
+	e.m.RLock()
+	defer e.m.RUnlock()
 
-	for _, order := range e.orders {
-		orders = append(orders, order.Value.(Order))
+	for _, s := range []*side{e.asks, e.bids} {
+		for _, q := range s.ascending() {
+			for el := q.orders.Front(); el != nil; el = el.Next() {
+				orders = append(orders, el.Value.(Order))
+			}
+		}
 	}
 
 	return
 }
 
-// OrderBook returns information about volume and price for definite price level
+// OrderBook returns information about volume and price for definite price
+// level. Asks are iterated first, then bids, both from the highest price to
+// the lowest. Like Snapshot, it iterates the order book as it was when the
+// call started without holding the lock for the whole iteration, so iter
+// may take its time
 func (e *Engine) OrderBook(iter func(asks bool, price, volume Value, len int)) {
-	e.m.Lock()
-	defer e.m.Unlock()
+	c := e.capture()
+	defer e.endCapture(c)
 
-	level := e.asks.maxPrice()
-	for level != nil {
-		iter(true, level.price, level.volume, level.orders.Len())
-		level = e.asks.lessThan(level.price)
+	for i := len(c.asks) - 1; i >= 0; i-- {
+		l := e.read(c, c.asks[i], false)
+		iter(true, l.price, l.volume, l.size)
 	}
 
-	level = e.bids.maxPrice()
-	for level != nil {
-		iter(false, level.price, level.volume, level.orders.Len())
-		level = e.bids.lessThan(level.price)
+	for i := len(c.bids) - 1; i >= 0; i-- {
+		l := e.read(c, c.bids[i], false)
+		iter(false, l.price, l.volume, l.size)
 	}
 }
 
-func (e *Engine) quantity(sell bool, priceLim Value) Value {
+func (e *Engine) quantity(sell bool, priceLim Value, inclusive bool) Value {
 	var (
 		level    *queue
 		iter     func(Value) *queue
@@ -476,10 +1163,15 @@ func (e *Engine) quantity(sell bool, priceLim Value) Value {
 	}
 
 	for level != nil {
-		if priceLim != nil &&
-			((sell && level.price.Cmp(priceLim) < 0) ||
-				(!sell && level.price.Cmp(priceLim) > 0)) {
-			break
+		if priceLim != nil {
+			cmp := level.price.Cmp(priceLim)
+			if !sell {
+				cmp = -cmp
+			}
+
+			if cmp < 0 || (cmp == 0 && !inclusive) {
+				break
+			}
 		}
 
 		quantity = level.volume.Add(quantity)
@@ -489,6 +1181,34 @@ func (e *Engine) quantity(sell bool, priceLim Value) Value {
 	return quantity
 }
 
+// buyCost returns quote amount required by the limit buy order: crossing
+// quantity at the ask prices and the remainder at the limit price
+func (e *Engine) buyCost(quantity, limit Value) Value {
+	var cost Value
+
+	if e.state != StateAuction {
+		for level := e.asks.minPrice(); level != nil &&
+			quantity.Sign() > 0 &&
+			level.price.Cmp(limit) <= 0; level = e.asks.greaterThan(level.price) {
+
+			fill := level.volume
+			if quantity.Cmp(fill) < 0 {
+				fill = quantity
+			}
+
+			cost = level.price.Mul(fill).Add(cost)
+			quantity = quantity.Sub(fill)
+		}
+
+		// Marketable limit order remainder is dropped as for market orders
+		if cost != nil && e.policy == TakerPolicyMarket {
+			return cost
+		}
+	}
+
+	return limit.Mul(quantity).Add(cost)
+}
+
 func (e *Engine) price(sell bool, quantity Value) (Value, error) {
 	var (
 		level *queue
@@ -538,6 +1258,13 @@ func (e *Engine) updateBalance(
 	v Volume,
 	isMaker bool,
 ) {
+	if e.matchOnly {
+		return
+	}
+
+	// The fee is charged after the fill is settled
+	defer e.chargeFee(ctx, listener, o, isMaker, v)
+
 	var (
 		wallet             = o.Owner()
 		assetInc, assetDec Asset
@@ -556,24 +1283,45 @@ func (e *Engine) updateBalance(
 		valueDec = v.Price
 	}
 
-	if isMaker {
-		valueInc = e.feeHandler.HandleFeeMaker(ctx, o, assetInc, valueInc)
-	} else {
-		valueInc = e.feeHandler.HandleFeeTaker(ctx, o, assetInc, valueInc)
+	var credited Value
+	switch {
+	case isFeeExempt(o):
+		credited = valueInc
+	case isMaker:
+		credited = e.feeHandler.HandleFeeMaker(ctx, o, assetInc, valueInc)
+	default:
+		credited = e.feeHandler.HandleFeeTaker(ctx, o, assetInc, valueInc)
+	}
+	valueInc = e.rebate(ctx, listener, o, assetInc, valueInc, credited)
+
+	// Taker funds are reserved before the fill
+	if r, ok := wallet.(Reserver); ok {
+		r.Commit(ctx, assetDec, valueDec, assetInc, valueInc)
+
+		e.notifyBalance(ctx, listener, wallet, assetInc)
+		if isMaker {
+			e.notifyInOrder(ctx, listener, wallet, assetDec)
+		} else {
+			e.notifyBalance(ctx, listener, wallet, assetDec)
+		}
+		return
 	}
 
-	valBalance := valueInc.Add(wallet.Balance(ctx, assetInc))
-	wallet.UpdateBalance(ctx, assetInc, valBalance)
+	valBalance := valueInc.Add(balanceOf(ctx, wallet, assetInc))
+	setBalance(ctx, wallet, assetInc, valBalance)
 	listener.OnBalanceChanged(ctx, wallet, assetInc, valBalance)
+	e.checkBalance(ctx, wallet, assetInc, "balance", valBalance)
 
 	if isMaker {
-		valInOrder := wallet.InOrder(ctx, assetDec).Sub(valueDec)
-		wallet.UpdateInOrder(ctx, assetDec, valInOrder)
+		valInOrder := inOrderOf(ctx, wallet, assetDec).Sub(valueDec)
+		setInOrder(ctx, wallet, assetDec, valInOrder)
 		listener.OnInOrderChanged(ctx, wallet, assetDec, valInOrder)
+		e.checkBalance(ctx, wallet, assetDec, "amount in order", valInOrder)
 	} else {
-		valInOrder := wallet.Balance(ctx, assetDec).Sub(valueDec)
-		wallet.UpdateBalance(ctx, assetDec, valInOrder)
+		valInOrder := balanceOf(ctx, wallet, assetDec).Sub(valueDec)
+		setBalance(ctx, wallet, assetDec, valInOrder)
 		listener.OnBalanceChanged(ctx, wallet, assetDec, valInOrder)
+		e.checkBalance(ctx, wallet, assetDec, "balance", valInOrder)
 	}
 }
 
@@ -582,6 +1330,10 @@ func (e *Engine) updateBalanceOnPlaced(
 	listener EventListener,
 	o Order,
 ) {
+	if e.matchOnly {
+		return
+	}
+
 	var (
 		wallet = o.Owner()
 		asset  Asset
@@ -596,21 +1348,92 @@ func (e *Engine) updateBalanceOnPlaced(
 		value = o.Price().Mul(o.Quantity())
 	}
 
-	valBalance := wallet.Balance(ctx, asset).Sub(value)
-	wallet.UpdateBalance(ctx, asset, valBalance)
+	// Funds are reserved before the order is placed
+	if _, ok := wallet.(Reserver); ok {
+		e.notifyBalance(ctx, listener, wallet, asset)
+		e.notifyInOrder(ctx, listener, wallet, asset)
+		return
+	}
+
+	valBalance := balanceOf(ctx, wallet, asset).Sub(value)
+	setBalance(ctx, wallet, asset, valBalance)
+	listener.OnBalanceChanged(ctx, wallet, asset, valBalance)
+	e.checkBalance(ctx, wallet, asset, "balance", valBalance)
+
+	valInOrder := value.Add(inOrderOf(ctx, wallet, asset))
+	setInOrder(ctx, wallet, asset, valInOrder)
+	listener.OnInOrderChanged(ctx, wallet, asset, valInOrder)
+}
+
+// cancel removes the order from the order book and refunds assets to the owner
+func (e *Engine) cancel(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) {
+	e.pull(ctx, o)
+	e.archive(ctx, o, StatusCanceled)
+	e.activity.canceled++
+
+	var (
+		wallet = o.Owner()
+		value  Value
+		asset  Asset
+	)
+
+	if o.Sell() {
+		value = o.Quantity()
+		asset = e.base
+	} else {
+		value = o.Quantity().Mul(o.Price())
+		asset = e.quote
+	}
+
+	e.release(ctx, listener, wallet, asset, value)
+}
+
+// release moves value of the asset from the wallet in-order amount back to the balance
+func (e *Engine) release(
+	ctx context.Context,
+	listener EventListener,
+	wallet Wallet,
+	asset Asset,
+	value Value,
+) {
+	if e.matchOnly {
+		return
+	}
+
+	if r, ok := wallet.(Reserver); ok {
+		r.Release(ctx, asset, value)
+		e.notifyBalance(ctx, listener, wallet, asset)
+		e.notifyInOrder(ctx, listener, wallet, asset)
+		return
+	}
+
+	valBalance := value.Add(balanceOf(ctx, wallet, asset))
+	setBalance(ctx, wallet, asset, valBalance)
 	listener.OnBalanceChanged(ctx, wallet, asset, valBalance)
 
-	valInOrder := value.Add(wallet.InOrder(ctx, asset))
-	wallet.UpdateInOrder(ctx, asset, valInOrder)
+	valInOrder := inOrderOf(ctx, wallet, asset).Sub(value)
+	setInOrder(ctx, wallet, asset, valInOrder)
 	listener.OnInOrderChanged(ctx, wallet, asset, valInOrder)
 }
 
+func (e *Engine) format(v Value) string {
+	return e.formatter.Format(v)
+}
+
 func (e *Engine) push(ctx context.Context, o Order) {
+	var el *list.Element
 	if o.Sell() {
-		e.orders[o.ID()] = e.asks.append(ctx, o)
+		el = e.asks.append(ctx, o)
 	} else {
-		e.orders[o.ID()] = e.bids.append(ctx, o)
+		el = e.bids.append(ctx, o)
 	}
+
+	e.orders[o.ID()] = el
+	e.own(el)
 }
 
 func (e *Engine) pull(ctx context.Context, o Order) {
@@ -625,6 +1448,7 @@ func (e *Engine) pull(ctx context.Context, o Order) {
 		e.bids.remove(ctx, el)
 	}
 
+	e.disown(el.Value.(Order))
 	delete(e.orders, o.ID())
 }
 
@@ -633,31 +1457,54 @@ func (e *Engine) pull(ctx context.Context, o Order) {
 // ----------------------------------------------------------
 
 type side struct {
-	prices    map[string]*queue
-	priceTree *rbTree
+	index     priceIndex
 	numOrders int
 	depth     int
+
+	// best price levels are cached to avoid tree traversal in the matching loop
+	min, max *queue
+
+	// queues reuses emptied price levels. The pool belongs to the side, so a
+	// released level is reused only by the next append to the same side
+	queues sync.Pool
+
+	// allocated and reused count price levels taken from the pool
+	allocated, reused uint64
+
+	// changed is called when the price level is added, removed or its
+	// volume is changed
+	changed func(ctx context.Context, q *queue, c levelChange)
+
+	// mutating is called before the price level is changed
+	mutating func(q *queue)
 }
 
-func newSide() *side {
-	return &side{
-		priceTree: newRBTree(func(a, b interface{}) int {
-			return a.(Value).Cmp(b.(Value))
-		}),
-		prices: make(map[string]*queue),
-	}
+func newSide(index priceIndex) *side {
+	return &side{index: index}
+}
+
+// level returns price level queue by comparing prices in the index, so
+// lookups don't allocate string keys
+func (s *side) level(price Value) *queue {
+	return s.index.get(price)
 }
 
 func (s *side) append(ctx context.Context, o Order) *list.Element {
 	p := o.Price()
-	h := p.Hash()
 
-	q, ok := s.prices[h]
-	if !ok {
-		q = newQueue(p)
-		s.prices[h] = q
-		s.priceTree.put(p, q)
+	q := s.level(p)
+	if q == nil {
+		q = s.newQueue(p)
+		s.index.put(q)
 		s.depth++
+
+		if s.min == nil || p.Cmp(s.min.price) < 0 {
+			s.min = q
+		}
+
+		if s.max == nil || p.Cmp(s.max.price) > 0 {
+			s.max = q
+		}
 	}
 
 	s.numOrders++
@@ -666,41 +1513,109 @@ func (s *side) append(ctx context.Context, o Order) *list.Element {
 
 func (s *side) remove(ctx context.Context, e *list.Element) (o Order) {
 	p := e.Value.(Order).Price()
-	h := p.Hash()
 
-	q := s.prices[h]
+	q := s.level(p)
 	o = q.remove(ctx, e)
 
 	if q.orders.Len() == 0 {
-		delete(s.prices, h)
-		s.priceTree.remove(p)
+		s.index.remove(p)
 		s.depth--
+
+		if q == s.min {
+			s.min = s.greaterThan(p)
+		}
+
+		if q == s.max {
+			s.max = s.lessThan(p)
+		}
+
+		s.releaseQueue(q)
 	}
 
 	s.numOrders--
 	return
 }
 
-func (s *side) maxPrice() *queue {
-	if s.depth > 0 {
-		if value, found := s.priceTree.getMax(); found {
-			return value.(*queue)
-		}
+// reindex moves price levels to the new index
+func (s *side) reindex(index priceIndex) {
+	for _, q := range s.ascending() {
+		index.put(q)
 	}
-	return nil
+	s.index = index
+}
+
+func (s *side) maxPrice() *queue {
+	return s.max
 }
 
 func (s *side) minPrice() *queue {
-	if s.depth > 0 {
-		if value, found := s.priceTree.getMin(); found {
-			return value.(*queue)
-		}
+	return s.min
+}
+
+// ascending returns all price levels sorted by price
+func (s *side) ascending() (levels []*queue) {
+	for q := s.minPrice(); q != nil; q = s.greaterThan(q.price) {
+		levels = append(levels, q)
 	}
-	return nil
+	return
 }
 
 func (s *side) greaterThan(price Value) *queue {
-	tree := s.priceTree
+	return s.index.greaterThan(price)
+}
+
+func (s *side) lessThan(price Value) *queue {
+	return s.index.lessThan(price)
+}
+
+// priceIndex keeps price levels of the order book side sorted by price
+type priceIndex interface {
+	// get returns price level with exactly the given price
+	get(price Value) *queue
+
+	// put adds new price level
+	put(q *queue)
+
+	// remove deletes price level with the given price
+	remove(price Value)
+
+	// greaterThan returns price level with the lowest price above the given one
+	greaterThan(price Value) *queue
+
+	// lessThan returns price level with the highest price below the given one
+	lessThan(price Value) *queue
+}
+
+// treeIndex is the price index backed by the red-black tree
+type treeIndex struct {
+	tree *rbTree
+}
+
+func newTreeIndex() *treeIndex {
+	return &treeIndex{
+		tree: newRBTree(func(a, b interface{}) int {
+			return a.(Value).Cmp(b.(Value))
+		}),
+	}
+}
+
+func (t *treeIndex) get(price Value) *queue {
+	if node := t.tree.lookup(price); node != nil {
+		return node.Value.(*queue)
+	}
+	return nil
+}
+
+func (t *treeIndex) put(q *queue) {
+	t.tree.put(q.price, q)
+}
+
+func (t *treeIndex) remove(price Value) {
+	t.tree.remove(price)
+}
+
+func (t *treeIndex) greaterThan(price Value) *queue {
+	tree := t.tree
 	node := tree.root
 
 	var ceiling *rbtNode
@@ -720,8 +1635,8 @@ func (s *side) greaterThan(price Value) *queue {
 	return nil
 }
 
-func (s *side) lessThan(price Value) *queue {
-	tree := s.priceTree
+func (t *treeIndex) lessThan(price Value) *queue {
+	tree := t.tree
 	node := tree.root
 
 	var floor *rbtNode
@@ -783,6 +1698,7 @@ type queue struct {
 	volume Value
 	price  Value
 	orders *list.List
+	side   *side
 }
 
 func newQueue(price Value) *queue {
@@ -793,23 +1709,78 @@ func newQueue(price Value) *queue {
 	}
 }
 
+// newQueue returns empty price level from the pool
+func (s *side) newQueue(price Value) *queue {
+	q, ok := s.queues.Get().(*queue)
+	if !ok {
+		q = newQueue(price)
+		q.side = s
+		s.allocated++
+	} else {
+		s.reused++
+	}
+
+	q.price = price
+	return q
+}
+
+// releaseQueue returns empty price level to the pool
+func (s *side) releaseQueue(q *queue) {
+	q.price, q.volume = nil, nil
+	s.queues.Put(q)
+}
+
 func (q *queue) append(ctx context.Context, o Order) *list.Element {
+	q.mutating()
+
+	c := levelVolumeChanged
+	if q.orders.Len() == 0 {
+		c = levelAdded
+	}
+
 	q.volume = o.Quantity().Add(q.volume)
-	return q.orders.PushBack(o)
+	el := q.orders.PushBack(o)
+	q.changed(ctx, c)
+	return el
 }
 
 func (q *queue) remove(ctx context.Context, e *list.Element) Order {
+	q.mutating()
 	q.volume = q.volume.Sub(e.Value.(Order).Quantity())
-	return q.orders.Remove(e).(Order)
+	o := q.orders.Remove(e).(Order)
+
+	c := levelVolumeChanged
+	if q.orders.Len() == 0 {
+		c = levelRemoved
+	}
+
+	q.changed(ctx, c)
+	return o
 }
 
 func (q *queue) updateQuantity(ctx context.Context, e *list.Element, qty Value) Order {
+	q.mutating()
 	o := e.Value.(Order)
 	q.volume = q.volume.Sub(o.Quantity()).Add(qty)
 	o.UpdateQuantity(qty)
+	q.changed(ctx, levelVolumeChanged)
 	return o
 }
 
+// mutating reports the price level is about to change to the side hook
+func (q *queue) mutating() {
+	if q.side != nil && q.side.mutating != nil {
+		q.side.mutating(q)
+	}
+}
+
+// changed reports the change of the price level to the side hook
+func (q *queue) changed(ctx context.Context, c levelChange) {
+	if q.side != nil && q.side.changed != nil {
+		q.side.changed(ctx, q, c)
+	}
+}
+
 // ----------------------------------------------------------
 // RedBlackTree implementation
 // ----------------------------------------------------------
@@ -881,6 +1852,9 @@ type rbTree struct {
 	root *rbtNode
 	comp comparator
 	size int
+
+	// nodes reuses removed nodes
+	nodes sync.Pool
 }
 
 // newRBTree instantiates a red-black tree with the custom comparator.
@@ -895,7 +1869,7 @@ func (t *rbTree) put(key interface{}, value interface{}) {
 	if t.root == nil {
 		// Assert key is of comparator's type for initial tree
 		t.comp(key, key)
-		t.root = &rbtNode{Key: key, Value: value, color: red}
+		t.root = t.newNode(key, value)
 		insertedNode = t.root
 	} else {
 		node := t.root
@@ -909,7 +1883,7 @@ func (t *rbTree) put(key interface{}, value interface{}) {
 				return
 			case compare < 0:
 				if node.Left == nil {
-					node.Left = &rbtNode{Key: key, Value: value, color: red}
+					node.Left = t.newNode(key, value)
 					insertedNode = node.Left
 					loop = false
 				} else {
@@ -917,7 +1891,7 @@ func (t *rbTree) put(key interface{}, value interface{}) {
 				}
 			case compare > 0:
 				if node.Right == nil {
-					node.Right = &rbtNode{Key: key, Value: value, color: red}
+					node.Right = t.newNode(key, value)
 					insertedNode = node.Right
 					loop = false
 				} else {
@@ -961,6 +1935,24 @@ func (t *rbTree) remove(key interface{}) {
 		}
 	}
 	t.size--
+	t.releaseNode(node)
+}
+
+// newNode returns red node from the pool
+func (t *rbTree) newNode(key interface{}, value interface{}) *rbtNode {
+	n, ok := t.nodes.Get().(*rbtNode)
+	if !ok {
+		n = new(rbtNode)
+	}
+
+	*n = rbtNode{Key: key, Value: value, color: red}
+	return n
+}
+
+// releaseNode returns detached node to the pool
+func (t *rbTree) releaseNode(n *rbtNode) {
+	*n = rbtNode{}
+	t.nodes.Put(n)
 }
 
 // getMin gets the min value and flag if found
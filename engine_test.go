@@ -2,7 +2,11 @@ package fastme
 
 import (
 	"context"
+	"errors"
+	"math"
+	"math/rand"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -26,6 +30,11 @@ func (t tFloat64) Mul(n Value) Value {
 	return t * t.checkNil(n)
 }
 
+// Mod is an "%" operation
+func (t tFloat64) Mod(n Value) Value {
+	return tFloat64(math.Mod(float64(t), float64(t.checkNil(n))))
+}
+
 // Cmp returns 1 if self > given, -1 if self < given and 0 if self == given
 func (t tFloat64) Cmp(n Value) int {
 	num := t.checkNil(n)
@@ -701,7 +710,7 @@ func TestLimitSell(t *testing.T) {
 	if processor.partial.ID() != "3" ||
 		processor.partial.Quantity().(tFloat64) != 1 ||
 		processor.done != 2 ||
-		engine.asks.prices["5"].volume.(tFloat64) != 1 {
+		engine.asks.level(tFloat64(5)).volume.(tFloat64) != 1 {
 		t.Fatalf("invalid result")
 	}
 
@@ -767,7 +776,7 @@ func TestLimitBuy(t *testing.T) {
 		processor.done != 2 ||
 		processor.priceDone != 30 ||
 		processor.qtyDone != 2 ||
-		engine.bids.prices["20"].volume.(tFloat64) != 1 {
+		engine.bids.level(tFloat64(20)).volume.(tFloat64) != 1 {
 		t.Fatalf("invalid result")
 	}
 
@@ -833,7 +842,7 @@ func TestLimitSellWithSelfDone(t *testing.T) {
 		processor.done != 2 ||
 		processor.priceDone != 30 ||
 		processor.qtyDone != 2 ||
-		engine.bids.prices["10"].volume.(tFloat64) != 1 ||
+		engine.bids.level(tFloat64(10)).volume.(tFloat64) != 1 ||
 		//---------------
 		walletBalance(wallet1, asset1) != 1 ||
 		walletBalance(wallet2, asset1) != 1 ||
@@ -891,7 +900,7 @@ func TestLimitBuyWithSelfDone(t *testing.T) {
 		processor.done != 2 ||
 		processor.priceDone != 30 ||
 		processor.qtyDone != 2 ||
-		engine.asks.prices["20"].volume.(tFloat64) != 1 {
+		engine.asks.level(tFloat64(20)).volume.(tFloat64) != 1 {
 		t.Fatalf("invalid result")
 	}
 
@@ -949,7 +958,8 @@ func TestOrderReplaceSell(t *testing.T) {
 	assertErr(t, engine.PlaceOrder(context.Background(), processor, order1))
 	assertErr(t, engine.PlaceOrder(context.Background(), processor, order2))
 
-	assertErr(t, engine.ReplaceOrder(context.Background(), processor, order2, order3))
+	_, err := engine.ReplaceOrder(context.Background(), processor, order2, order3)
+	assertErr(t, err)
 
 	// --------------------------------------
 
@@ -1000,7 +1010,8 @@ func TestOrderReplaceBuy(t *testing.T) {
 	assertErr(t, engine.PlaceOrder(context.Background(), processor, order1))
 	assertErr(t, engine.PlaceOrder(context.Background(), processor, order2))
 
-	assertErr(t, engine.ReplaceOrder(context.Background(), processor, order2, order3))
+	_, err := engine.ReplaceOrder(context.Background(), processor, order2, order3)
+	assertErr(t, err)
 
 	// --------------------------------------
 
@@ -1015,6 +1026,149 @@ func TestOrderReplaceBuy(t *testing.T) {
 	}
 }
 
+func TestOrderReplacePrice(t *testing.T) {
+	var (
+		processor        = newEventListener()
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+	updateWalletBalance(wallet2, asset2, 30)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 1, 30)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 20)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet2, false, 2, 10)))
+
+	// Sell order moved to the occupied level loses time priority
+	fills, err := engine.ReplaceOrder(
+		context.Background(),
+		processor,
+		newOrder("1", wallet1, true, 1, 30),
+		newOrder("4", wallet1, true, 1, 20),
+	)
+	assertErr(t, err)
+
+	if len(fills) != 0 || walletInOrder(wallet1, asset1) != 2 {
+		t.Fatal("invalid result")
+	}
+
+	if _, err := engine.ReplaceOrder(
+		context.Background(),
+		processor,
+		newOrder("3", wallet2, false, 2, 10),
+		newOrder("5", wallet2, false, 2, 20),
+	); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("replace must be rejected with insufficient funds")
+	}
+
+	fills, err = engine.ReplaceOrder(
+		context.Background(),
+		processor,
+		newOrder("3", wallet2, false, 2, 10),
+		newOrder("5", wallet2, false, 1, 20),
+	)
+	assertErr(t, err)
+
+	if len(fills) != 1 ||
+		fills[0].MakerID != "2" ||
+		fills[0].Price.(tFloat64) != 20 ||
+		fills[0].Quantity.(tFloat64) != 1 {
+		t.Fatal("invalid fills", fills)
+	}
+
+	if _, err := engine.FindOrder("3"); err != ErrOrderNotFound {
+		t.Fatal("replaced order must be removed")
+	}
+
+	if _, err := engine.FindOrder("4"); err != nil {
+		t.Fatal("order must keep resting")
+	}
+
+	if walletBalance(wallet1, asset2) != 20 ||
+		walletInOrder(wallet1, asset1) != 1 ||
+		walletBalance(wallet2, asset1) != 1 ||
+		walletBalance(wallet2, asset2) != 10 ||
+		walletInOrder(wallet2, asset2) != 0 {
+		t.Fatal("invalid result")
+	}
+}
+
+func TestAmendOrder(t *testing.T) {
+	var (
+		processor        = newEventListener()
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 5)
+	updateWalletBalance(wallet2, asset2, 20)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet1, true, 1, 10)))
+
+	if err := engine.AmendOrder(
+		context.Background(),
+		processor,
+		newOrder("1", wallet1, true, 2, 10),
+		newOrder("1", wallet1, true, 2, 11),
+	); !errors.Is(err, ErrInvalidPrice) {
+		t.Fatal("amend must keep the price")
+	}
+
+	if err := engine.AmendOrder(
+		context.Background(),
+		processor,
+		newOrder("1", wallet1, true, 2, 10),
+		newOrder("3", wallet1, true, 1, 10),
+	); err != ErrOrderExists {
+		t.Fatal("amend must not take ID of other resting order", err)
+	}
+
+	if _, err := engine.ReplaceOrder(
+		context.Background(),
+		processor,
+		newOrder("1", wallet1, true, 2, 10),
+		newOrder("3", wallet1, true, 1, 10),
+	); err != ErrOrderExists {
+		t.Fatal("same price replace must not take ID of other resting order", err)
+	}
+
+	// Decreased order keeps queue position
+	assertErr(t, engine.AmendOrder(
+		context.Background(),
+		processor,
+		newOrder("1", wallet1, true, 2, 10),
+		newOrder("1", wallet1, true, 1, 10),
+	))
+
+	// Increased order loses queue position
+	assertErr(t, engine.AmendOrder(
+		context.Background(),
+		processor,
+		newOrder("2", wallet1, true, 1, 10),
+		newOrder("2", wallet1, true, 2, 10),
+	))
+
+	if walletBalance(wallet1, asset1) != 1 || walletInOrder(wallet1, asset1) != 4 {
+		t.Fatal("invalid result")
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet2, false, 2, 10)))
+
+	orders := engine.Orders()
+	if len(orders) != 1 ||
+		orders[0].ID() != "2" ||
+		orders[0].Quantity().(tFloat64) != 2 {
+		t.Fatal("invalid queue order")
+	}
+}
+
 func BenchmarkOrderProcessung(b *testing.B) {
 	var (
 		asset1, asset2 = Asset("apples"), Asset("dollars")
@@ -1504,3 +1658,248 @@ func TestOrderBookQuantity(t *testing.T) {
 	}
 
 }
+
+func TestCancelOrder(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		order1 = newOrder("1", wallet1, true, 1, 10)
+		order2 = newOrder("2", wallet1, true, 1, 10)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order1))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order2))
+
+	assertErr(t, engine.CancelOrder(context.Background(), nil, order1))
+	if err := engine.CancelOrder(context.Background(), nil, order1); err != ErrOrderNotFound {
+		t.Fatal("order must not be canceled twice")
+	}
+
+	if walletBalance(wallet1, asset1) != 1 || walletInOrder(wallet1, asset1) != 1 {
+		t.Fatal("invalid result")
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "2"))
+	if err := engine.CancelOrderByID(context.Background(), nil, "2"); err != ErrOrderNotFound {
+		t.Fatal("order must not be canceled twice")
+	}
+
+	if walletBalance(wallet1, asset1) != 2 || walletInOrder(wallet1, asset1) != 0 {
+		t.Fatal("invalid result")
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet1, asset2, 100)
+	updateWalletBalance(wallet2, asset1, 1)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 20)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, false, 1, 5)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, true, 1, 10)))
+
+	canceled := engine.CancelAllSide(context.Background(), nil, wallet1, false)
+	if len(canceled) != 1 || canceled[0].ID() != "3" || walletBalance(wallet1, asset2) != 100 {
+		t.Fatal("invalid result")
+	}
+
+	canceled = engine.CancelAll(context.Background(), nil, wallet1)
+	if len(canceled) != 2 ||
+		canceled[0].ID() != "2" ||
+		canceled[1].ID() != "1" ||
+		walletBalance(wallet1, asset1) != 3 ||
+		walletInOrder(wallet1, asset1) != 0 {
+		t.Fatal("invalid result")
+	}
+
+	if orders := engine.Orders(); len(orders) != 1 || orders[0].ID() != "4" {
+		t.Fatal("orders of other wallets must stay in the order book")
+	}
+}
+
+func TestQuantityLimit(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 20)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 3, 5)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 4, 8)))
+
+	if vol, _ := engine.QuantityLimit(false, tFloat64(20), true).(tFloat64); vol != 3 {
+		t.Fatal("inclusive purchase quantity: invalid result", vol)
+	}
+
+	if vol, _ := engine.QuantityLimit(false, tFloat64(20), false).(tFloat64); vol != 1 {
+		t.Fatal("exclusive purchase quantity: invalid result", vol)
+	}
+
+	if vol, _ := engine.QuantityLimit(true, tFloat64(5), true).(tFloat64); vol != 7 {
+		t.Fatal("inclusive sale quantity: invalid result", vol)
+	}
+
+	if vol, _ := engine.QuantityLimit(true, tFloat64(5), false).(tFloat64); vol != 4 {
+		t.Fatal("exclusive sale quantity: invalid result", vol)
+	}
+
+	if vol := engine.QuantityLimit(true, tFloat64(8), false); vol != nil {
+		t.Fatal("no quantity expected", vol)
+	}
+}
+
+func TestOrdersDeterministic(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet2, false, 1, 5)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 3)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet1, true, 1, 11)))
+
+	for i := 0; i < 10; i++ {
+		var ids string
+		for _, o := range engine.Orders() {
+			ids += o.ID()
+		}
+
+		if ids != "35142" {
+			t.Fatal("invalid orders order", ids)
+		}
+	}
+}
+
+func TestBestPriceCache(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		rnd            = rand.New(rand.NewSource(1))
+		ids            []string
+	)
+
+	updateWalletBalance(wallet, asset1, 1000)
+
+	check := func() {
+		min, _ := engine.asks.index.(*treeIndex).tree.getMin()
+		max, _ := engine.asks.index.(*treeIndex).tree.getMax()
+
+		if engine.asks.depth == 0 {
+			min, max = (*queue)(nil), (*queue)(nil)
+		}
+
+		if engine.asks.minPrice() != min || engine.asks.maxPrice() != max {
+			t.Fatal("cached best prices must match the tree")
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		if len(ids) == 0 || rnd.Intn(3) > 0 {
+			id := strconv.Itoa(i)
+			assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder(id, wallet, true, 1, float64(1+rnd.Intn(20)))))
+			ids = append(ids, id)
+		} else {
+			j := rnd.Intn(len(ids))
+			assertErr(t, engine.CancelOrderByID(context.Background(), nil, ids[j]))
+			ids = append(ids[:j], ids[j+1:]...)
+		}
+		check()
+	}
+}
+
+func BenchmarkPlaceCancelChurn(b *testing.B) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		ctx            = context.Background()
+		wallet         = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		orders         = make([]*tOrder, 1000)
+	)
+
+	wallet.UpdateBalance(ctx, asset1, tFloat64(1e12))
+
+	for i := range orders {
+		orders[i] = newOrder(strconv.Itoa(i), wallet, true, 1, float64(100+i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		o := orders[i%len(orders)]
+		if i >= len(orders) {
+			if err := engine.CancelOrder(ctx, nil, o); err != nil {
+				b.Fatal(err)
+			}
+			o.quantity = 1
+		}
+
+		if err := engine.PlaceOrder(ctx, nil, o); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	for _, index := range []IndexType{IndexRBTree, IndexSkipList} {
+		var (
+			asset1, asset2   = Asset("apples"), Asset("dollars")
+			wallet1, wallet2 = newWallet(), newWallet()
+			engine           = NewEngine(asset1, asset2, WithPriceIndex(index))
+			wg               sync.WaitGroup
+		)
+
+		updateWalletBalance(wallet1, asset1, 1e6)
+		updateWalletBalance(wallet2, asset2, 1e6)
+
+		for i := 0; i < 100; i++ {
+			engine.PushOrder(context.Background(), newOrder("a"+strconv.Itoa(i), wallet1, true, 1, float64(101+i)))
+			engine.PushOrder(context.Background(), newOrder("b"+strconv.Itoa(i), wallet2, false, 1, float64(1+i)))
+		}
+
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					engine.Spread()
+					engine.Quantity(false, tFloat64(150))
+					engine.Quantity(true, tFloat64(50))
+					engine.L2(10)
+				}
+			}()
+		}
+
+		for i := 0; i < 100; i++ {
+			assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("c"+strconv.Itoa(i), wallet1, true, 1, float64(101+i%10))))
+		}
+
+		wg.Wait()
+	}
+}
@@ -0,0 +1,71 @@
+package fastme
+
+import "context"
+
+// RejectedListener is an optional EventListener extension informing about
+// rejected orders. It's called for orders failing validation on placement,
+// replacement and amendment with the error returned to the caller
+type RejectedListener interface {
+	OnOrderRejected(ctx context.Context, o Order, err error)
+}
+
+// InsufficientFundsError is returned when the owner balance doesn't cover the
+// order. It wraps ErrInsufficientFunds
+type InsufficientFundsError struct {
+	Asset Asset
+
+	// Required is the amount to be reserved
+	Required Value
+
+	// Available is the balance of the owner, nil if the order has no owner
+	Available Value
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return ErrInsufficientFunds.Error()
+}
+
+// Unwrap returns ErrInsufficientFunds
+func (e *InsufficientFundsError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// InvalidPriceError is returned when the order price is rejected. Reason is
+// ErrInvalidPrice, ErrInvalidTick or ErrPriceTooHigh
+type InvalidPriceError struct {
+	Price  Value
+	Reason error
+}
+
+func (e *InvalidPriceError) Error() string {
+	return e.Reason.Error()
+}
+
+// Unwrap returns the reason
+func (e *InvalidPriceError) Unwrap() error {
+	return e.Reason
+}
+
+// InvalidQuantityError is returned when the order quantity is rejected.
+// Reason is ErrInvalidQuantity, ErrInvalidLot or ErrQuantityTooLarge
+type InvalidQuantityError struct {
+	Quantity Value
+	Reason   error
+}
+
+func (e *InvalidQuantityError) Error() string {
+	return e.Reason.Error()
+}
+
+// Unwrap returns the reason
+func (e *InvalidQuantityError) Unwrap() error {
+	return e.Reason
+}
+
+func invalidPrice(price Value, reason error) error {
+	return &InvalidPriceError{Price: price, Reason: reason}
+}
+
+func invalidQuantity(quantity Value, reason error) error {
+	return &InvalidQuantityError{Quantity: quantity, Reason: reason}
+}
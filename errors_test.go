@@ -0,0 +1,92 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet, asset2, 100)
+
+	err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, false, 20, 10))
+
+	var funds *InsufficientFundsError
+	if !errors.As(err, &funds) || !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("insufficient funds error expected", err)
+	}
+
+	if funds.Asset != asset2 ||
+		funds.Required != tFloat64(200) ||
+		funds.Available != tFloat64(100) {
+		t.Fatal("invalid error details", funds)
+	}
+
+	err = engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, false, 1, -1))
+
+	var price *InvalidPriceError
+	if !errors.As(err, &price) || price.Reason != ErrInvalidPrice || price.Price != tFloat64(-1) {
+		t.Fatal("invalid price error expected", err)
+	}
+
+	if err.Error() != ErrInvalidPrice.Error() {
+		t.Fatal("error message must be kept", err)
+	}
+
+	err = engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet, false, 0, 10))
+
+	var qty *InvalidQuantityError
+	if !errors.As(err, &qty) || !errors.Is(err, ErrInvalidQuantity) {
+		t.Fatal("invalid quantity error expected", err)
+	}
+}
+
+type tRejectedListener struct {
+	*tEventListener
+	rejected []error
+}
+
+func (t *tRejectedListener) OnOrderRejected(ctx context.Context, o Order, err error) {
+	t.rejected = append(t.rejected, err)
+}
+
+func TestOrderRejected(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+		listener       = &tRejectedListener{tEventListener: newEventListener()}
+
+		engine = NewEngine(asset1, asset2, WithListener(NewListenerMux(listener)))
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	order := newOrder("1", wallet, true, 5, 10)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 1, 10)); err != ErrOrderExists {
+		t.Fatal("duplicate order must be rejected", err)
+	}
+
+	if _, err := engine.ReplaceOrder(context.Background(), nil, order, newOrder("2", wallet, true, 20, 11)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("replacement must be rejected", err)
+	}
+
+	if err := engine.AmendOrder(context.Background(), nil, order, newOrder("1", wallet, true, 1, 11)); !errors.Is(err, ErrInvalidPrice) {
+		t.Fatal("amendment must be rejected", err)
+	}
+
+	if len(listener.rejected) != 3 ||
+		listener.rejected[0] != ErrOrderExists ||
+		!errors.Is(listener.rejected[1], ErrInsufficientFunds) ||
+		!errors.Is(listener.rejected[2], ErrInvalidPrice) {
+		t.Fatal("rejections must be reported", listener.rejected)
+	}
+}
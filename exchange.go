@@ -13,6 +13,13 @@ type Volume struct {
 	Quantity Value
 }
 
+// Fill describes single match of the incoming order against resting order
+type Fill struct {
+	MakerID  string
+	Price    Value
+	Quantity Value
+}
+
 // Value calcultes math operations
 type Value interface {
 	// Add is an "+" operation
@@ -36,7 +43,8 @@ type Value interface {
 
 // Wallet describes interface for asset exchange operations
 type Wallet interface {
-	// Balance returns current wallet balance for given asset
+	// Balance returns current wallet balance for given asset available for
+	// new orders, i.e. excluding the amount in order
 	Balance(context.Context, Asset) Value
 
 	// UpdateBalance calls by matching engine to update wallet balance
@@ -84,7 +92,8 @@ type EventListener interface {
 	OnInOrderChanged(context.Context, Wallet, Asset, Value)
 }
 
-// FeeHandler responsible for fee calculations and fee wallet processing
+// FeeHandler responsible for fee calculations and fee wallet processing.
+// Output value above the input one is the rebate, see SetRebateWallet
 type FeeHandler interface {
 	// HandleFeeMaker calls by  matching engine and provide data to correct output value for fee processing
 	HandleFeeMaker(context.Context, Order, Asset, Value) (out Value)
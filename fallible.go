@@ -0,0 +1,128 @@
+package fastme
+
+import (
+	"context"
+	"fmt"
+)
+
+// FallibleWallet is an optional Wallet extension for wallets which may fail,
+// e.g. backed by the database. The engine calls the methods below instead of
+// Balance, InOrder, UpdateBalance and UpdateInOrder of such wallets.
+//
+// The failure while the command is validated rejects it with WalletError,
+// nothing is changed. The failure during matching aborts the command: the
+// order book and balances updated before it can't be rolled back, so the
+// engine is marked corrupted like on the panic (see PanicError) and the
+// WalletError wrapping ErrEngineCorrupted is returned. Reserver wallets
+// keep the state consistent as the reservation is the only step which may
+// fail in the middle of the command
+type FallibleWallet interface {
+	Wallet
+
+	TryBalance(context.Context, Asset) (Value, error)
+	TryUpdateBalance(context.Context, Asset, Value) error
+	TryInOrder(context.Context, Asset) (Value, error)
+	TryUpdateInOrder(context.Context, Asset, Value) error
+}
+
+// WalletError is returned by the command if the FallibleWallet call failed
+type WalletError struct {
+	Wallet Wallet
+	Asset  Asset
+	Op     string
+	Err    error
+
+	// Corrupted is set if the command was aborted in the middle
+	Corrupted bool
+}
+
+func (e *WalletError) Error() string {
+	msg := fmt.Sprintf("Wallet %s of %v failed: %v", e.Op, e.Asset, e.Err)
+	if e.Corrupted {
+		return fmt.Sprintf("%s: %s", ErrEngineCorrupted, msg)
+	}
+	return msg
+}
+
+// Unwrap returns the wallet error
+func (e *WalletError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports ErrEngineCorrupted if the command was aborted in the middle
+func (e *WalletError) Is(target error) bool {
+	return e.Corrupted && target == ErrEngineCorrupted
+}
+
+// walletFailure is the panic value aborting the command, see guard
+type walletFailure struct {
+	err *WalletError
+}
+
+// tryBalance returns the wallet balance or WalletError, used by validation
+func tryBalance(ctx context.Context, w Wallet, a Asset) (Value, error) {
+	if f, ok := w.(FallibleWallet); ok {
+		v, err := f.TryBalance(ctx, a)
+		if err != nil {
+			return nil, &WalletError{Wallet: w, Asset: a, Op: "balance", Err: err}
+		}
+		return v, nil
+	}
+	return w.Balance(ctx, a), nil
+}
+
+// tryInOrder returns the wallet in-order amount or WalletError, used by validation
+func tryInOrder(ctx context.Context, w Wallet, a Asset) (Value, error) {
+	if f, ok := w.(FallibleWallet); ok {
+		v, err := f.TryInOrder(ctx, a)
+		if err != nil {
+			return nil, &WalletError{Wallet: w, Asset: a, Op: "in order", Err: err}
+		}
+		return v, nil
+	}
+	return w.InOrder(ctx, a), nil
+}
+
+// balanceOf returns the wallet balance, the failure aborts the command
+func balanceOf(ctx context.Context, w Wallet, a Asset) Value {
+	v, err := tryBalance(ctx, w, a)
+	if err != nil {
+		panic(walletFailure{err.(*WalletError)})
+	}
+	return v
+}
+
+// inOrderOf returns the wallet in-order amount, the failure aborts the command
+func inOrderOf(ctx context.Context, w Wallet, a Asset) Value {
+	v, err := tryInOrder(ctx, w, a)
+	if err != nil {
+		panic(walletFailure{err.(*WalletError)})
+	}
+	return v
+}
+
+// setBalance updates the wallet balance, the failure aborts the command
+func setBalance(ctx context.Context, w Wallet, a Asset, v Value) {
+	f, ok := w.(FallibleWallet)
+	if !ok {
+		w.UpdateBalance(ctx, a, v)
+		return
+	}
+
+	if err := f.TryUpdateBalance(ctx, a, v); err != nil {
+		panic(walletFailure{&WalletError{Wallet: w, Asset: a, Op: "balance update", Err: err}})
+	}
+}
+
+// setInOrder updates the wallet in-order amount, the failure aborts the command
+func setInOrder(ctx context.Context, w Wallet, a Asset, v Value) {
+	f, ok := w.(FallibleWallet)
+	if !ok {
+		w.UpdateInOrder(ctx, a, v)
+		return
+	}
+
+	if err := f.TryUpdateInOrder(ctx, a, v); err != nil {
+		panic(walletFailure{&WalletError{Wallet: w, Asset: a, Op: "in order update", Err: err}})
+	}
+}
@@ -0,0 +1,101 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errWalletDown = errors.New("wallet is down")
+
+type tFallibleWallet struct {
+	*tWallet
+	failReads   bool
+	failUpdates bool
+}
+
+func (t *tFallibleWallet) TryBalance(ctx context.Context, a Asset) (Value, error) {
+	if t.failReads {
+		return nil, errWalletDown
+	}
+	return t.Balance(ctx, a), nil
+}
+
+func (t *tFallibleWallet) TryUpdateBalance(ctx context.Context, a Asset, v Value) error {
+	if t.failUpdates {
+		return errWalletDown
+	}
+	t.UpdateBalance(ctx, a, v)
+	return nil
+}
+
+func (t *tFallibleWallet) TryInOrder(ctx context.Context, a Asset) (Value, error) {
+	if t.failReads {
+		return nil, errWalletDown
+	}
+	return t.InOrder(ctx, a), nil
+}
+
+func (t *tFallibleWallet) TryUpdateInOrder(ctx context.Context, a Asset, v Value) error {
+	if t.failUpdates {
+		return errWalletDown
+	}
+	t.UpdateInOrder(ctx, a, v)
+	return nil
+}
+
+type tFallibleOrder struct {
+	*tOrder
+	owner Wallet
+}
+
+func (t *tFallibleOrder) Owner() Wallet {
+	return t.owner
+}
+
+func TestFallibleWallet(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		seller, buyer  = newWallet(), &tFallibleWallet{tWallet: newWallet()}
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(seller, asset1, 10)
+	updateWalletBalance(buyer.tWallet, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", seller, true, 2, 10)))
+
+	buy := func(id string) error {
+		return engine.PlaceOrder(context.Background(), nil,
+			&tFallibleOrder{newOrder(id, buyer.tWallet, false, 1, 10), buyer})
+	}
+
+	buyer.failReads = true
+	err := buy("2")
+
+	var we *WalletError
+	if !errors.As(err, &we) || !errors.Is(err, errWalletDown) || errors.Is(err, ErrEngineCorrupted) {
+		t.Fatal("validation failure must reject the order", err)
+	}
+
+	if maker, _ := engine.FindOrder("1"); engine.Corrupted() != nil || maker.Quantity().Cmp(tFloat64(2)) != 0 {
+		t.Fatal("rejected order must not change the engine state")
+	}
+
+	buyer.failReads, buyer.failUpdates = false, true
+	err = buy("3")
+
+	if !errors.As(err, &we) || !errors.Is(err, errWalletDown) || !errors.Is(err, ErrEngineCorrupted) {
+		t.Fatal("matching failure must corrupt the engine", err)
+	}
+
+	if engine.Corrupted() != err {
+		t.Fatal("engine must be marked corrupted")
+	}
+
+	buyer.failUpdates = false
+	if err := buy("4"); err != we {
+		t.Fatal("orders must be rejected by corrupted engine", err)
+	}
+}
@@ -0,0 +1,169 @@
+package fastme
+
+import "context"
+
+// Fee is the fee charged by FeeCharger
+type Fee struct {
+	// Asset is the fee asset, it may differ from the traded assets
+	Asset Asset
+
+	// Amount is debited from the balance of the order owner
+	Amount Value
+
+	// To receives the fee, the engine fee wallet is used if it's nil
+	To Wallet
+}
+
+// FeeExemptOrder is an optional Order extension. The engine doesn't call
+// FeeHandler for fee-exempt orders, e.g. liquidation or administrative ones
+type FeeExemptOrder interface {
+	FeeExempt() bool
+}
+
+// FeeCharger is an optional FeeHandler extension charging the fee as the
+// separate transfer. It's called for each order of the fill after the
+// credited value is adjusted by HandleFeeMaker or HandleFeeTaker. The engine
+// debits the fee from the balance of the order owner and credits it to the
+// destination wallet. Nothing is charged if it returns nil or zero amount.
+// If the balance doesn't cover the fee, the balance is charged only
+type FeeCharger interface {
+	ChargeFee(ctx context.Context, o Order, isMaker bool, v Volume) *Fee
+}
+
+// FeeListener is an optional EventListener extension informing about fees
+// charged by FeeCharger. Fee.To is the wallet received the fee
+type FeeListener interface {
+	OnFeeCharged(ctx context.Context, o Order, f Fee)
+}
+
+// SetFeeWallet sets the wallet collecting fees of FeeCharger without
+// destination
+func (e *Engine) SetFeeWallet(w Wallet) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.feeWallet = w
+}
+
+// chargeFee transfers the fee of the filled order to the fee wallet
+func (e *Engine) chargeFee(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+	isMaker bool,
+	v Volume,
+) {
+	charger, ok := e.feeHandler.(FeeCharger)
+	if !ok || isFeeExempt(o) {
+		return
+	}
+
+	fee := charger.ChargeFee(ctx, o, isMaker, v)
+	if fee == nil || fee.Amount == nil || fee.Amount.Sign() <= 0 {
+		return
+	}
+
+	if fee.To == nil {
+		fee.To = e.feeWallet
+	}
+
+	if fee.To == nil {
+		e.log(ctx, LogError, "fee wallet is not set",
+			LogField{"order_id", o.ID()},
+		)
+		return
+	}
+
+	var (
+		payer     = o.Owner()
+		balance   = balanceOf(ctx, payer, fee.Asset)
+		shortfall = fee.Amount.Sub(balance)
+	)
+
+	if shortfall.Sign() > 0 {
+		e.log(ctx, LogWarn, "fee exceeds balance",
+			LogField{"order_id", o.ID()},
+			LogField{"wallet", payer},
+			LogField{"asset", fee.Asset},
+			LogField{"shortfall", e.format(shortfall)},
+		)
+
+		if balance.Sign() <= 0 {
+			return
+		}
+		fee.Amount = balance
+	}
+
+	if !e.transfer(ctx, listener, payer, fee.To, fee.Asset, fee.Amount) {
+		return
+	}
+
+	if l, ok := listener.(FeeListener); ok {
+		l.OnFeeCharged(ctx, o, *fee)
+	}
+}
+
+func isFeeExempt(o Order) bool {
+	f, ok := o.(FeeExemptOrder)
+	return ok && f.FeeExempt()
+}
+
+// transfer moves value of the asset between wallet balances. Returns false
+// if the Reserver wallet refused to debit it
+func (e *Engine) transfer(
+	ctx context.Context,
+	listener EventListener,
+	from, to Wallet,
+	asset Asset,
+	value Value,
+) bool {
+	if !e.debit(ctx, listener, from, asset, value) {
+		return false
+	}
+
+	e.credit(ctx, listener, to, asset, value)
+	return true
+}
+
+// debit subtracts value of the asset from the wallet balance. Returns false
+// if the Reserver wallet refused it
+func (e *Engine) debit(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	asset Asset,
+	value Value,
+) bool {
+	if r, ok := w.(Reserver); ok {
+		if !r.Reserve(ctx, asset, value) {
+			return false
+		}
+		r.Commit(ctx, asset, value, asset, value.Sub(value))
+		e.notifyBalance(ctx, listener, w, asset)
+		return true
+	}
+
+	valBalance := balanceOf(ctx, w, asset).Sub(value)
+	setBalance(ctx, w, asset, valBalance)
+	listener.OnBalanceChanged(ctx, w, asset, valBalance)
+	return true
+}
+
+// credit adds value of the asset to the wallet balance
+func (e *Engine) credit(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	asset Asset,
+	value Value,
+) {
+	if r, ok := w.(Reserver); ok {
+		r.Commit(ctx, asset, value.Sub(value), asset, value)
+		e.notifyBalance(ctx, listener, w, asset)
+		return
+	}
+
+	valBalance := value.Add(balanceOf(ctx, w, asset))
+	setBalance(ctx, w, asset, valBalance)
+	listener.OnBalanceChanged(ctx, w, asset, valBalance)
+}
@@ -0,0 +1,165 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tFeeCharger struct {
+	emptyFeeHandler
+	asset Asset
+}
+
+func (t *tFeeCharger) ChargeFee(ctx context.Context, o Order, isMaker bool, v Volume) *Fee {
+	if isMaker {
+		return nil
+	}
+	return &Fee{Asset: t.asset, Amount: v.Quantity}
+}
+
+type tFeeListener struct {
+	*tEventListener
+	fees []Fee
+}
+
+func (t *tFeeListener) OnFeeCharged(ctx context.Context, o Order, f Fee) {
+	t.fees = append(t.fees, f)
+}
+
+func TestFeeCharger(t *testing.T) {
+	var (
+		asset1, asset2, asset3     = Asset("apples"), Asset("dollars"), Asset("tokens")
+		wallet1, wallet2, treasury = newWallet(), newWallet(), newWallet()
+		listener                   = &tFeeListener{tEventListener: newEventListener()}
+
+		engine = NewEngine(asset1, asset2,
+			WithFeeHandler(&tFeeCharger{asset: asset3}),
+			WithFeeWallet(treasury),
+		)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+	updateWalletBalance(wallet2, asset3, 3)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 5, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("2", wallet2, false, 2, 10)))
+
+	if len(listener.fees) != 1 || listener.fees[0].To != treasury || listener.fees[0].Amount.Cmp(tFloat64(2)) != 0 {
+		t.Fatal("taker fee must be charged to the fee wallet", listener.fees)
+	}
+
+	if walletBalance(wallet2, asset3) != 1 || walletBalance(treasury, asset3) != 2 {
+		t.Fatal("fee must be transferred",
+			walletBalance(wallet2, asset3), walletBalance(treasury, asset3))
+	}
+
+	if walletBalance(wallet2, asset1) != 2 || walletBalance(wallet1, asset3) != 0 {
+		t.Fatal("traded assets must not be affected",
+			walletBalance(wallet2, asset1), walletBalance(wallet1, asset3))
+	}
+
+	// The balance is charged only if it doesn't cover the fee
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("3", wallet2, false, 2, 10)))
+
+	if len(listener.fees) != 2 || listener.fees[1].Amount.Cmp(tFloat64(1)) != 0 {
+		t.Fatal("fee must be limited by the balance", listener.fees)
+	}
+
+	if walletBalance(wallet2, asset3) != 0 || walletBalance(treasury, asset3) != 3 {
+		t.Fatal("balance must be charged",
+			walletBalance(wallet2, asset3), walletBalance(treasury, asset3))
+	}
+}
+
+type tRebateListener struct {
+	*tEventListener
+	rebates []Value
+}
+
+func (t *tRebateListener) OnRebatePaid(ctx context.Context, o Order, a Asset, v Value) {
+	t.rebates = append(t.rebates, v)
+}
+
+func TestMakerRebate(t *testing.T) {
+	var (
+		asset1, asset2           = Asset("apples"), Asset("dollars")
+		wallet1, wallet2, rebate = newWallet(), newWallet(), newWallet()
+		listener                 = &tRebateListener{tEventListener: newEventListener()}
+
+		engine = NewEngine(asset1, asset2,
+			WithFeeHandler(&FeeSchedule{
+				BasisPoint: tFloat64(0.0001),
+				Tiers:      []FeeTier{{FeeRates: FeeRates{Maker: tFloat64(-1000)}}},
+			}),
+			WithRebateWallet(rebate),
+		)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+	updateWalletBalance(rebate, asset2, 3)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 4, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("2", wallet2, false, 2, 10)))
+
+	if len(listener.rebates) != 1 || listener.rebates[0].Cmp(tFloat64(2)) != 0 {
+		t.Fatal("rebate must be reported", listener.rebates)
+	}
+
+	if walletBalance(wallet1, asset2) != 22 || walletBalance(rebate, asset2) != 1 {
+		t.Fatal("rebate must be paid from the rebate wallet",
+			walletBalance(wallet1, asset2), walletBalance(rebate, asset2))
+	}
+
+	// Rebate wallet can't cover the next rebate
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("3", wallet2, false, 2, 10)))
+
+	if len(listener.rebates) != 1 {
+		t.Fatal("uncovered rebate must not be reported", listener.rebates)
+	}
+
+	if walletBalance(wallet1, asset2) != 42 || walletBalance(rebate, asset2) != 1 {
+		t.Fatal("uncovered rebate must be dropped",
+			walletBalance(wallet1, asset2), walletBalance(rebate, asset2))
+	}
+}
+
+type tFeeExemptOrder struct {
+	*tOrder
+}
+
+func (t *tFeeExemptOrder) FeeExempt() bool {
+	return true
+}
+
+func TestFeeExemptOrder(t *testing.T) {
+	var (
+		asset1, asset2, asset3     = Asset("apples"), Asset("dollars"), Asset("tokens")
+		wallet1, wallet2, treasury = newWallet(), newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2,
+			WithFeeHandler(&tFeeCharger{asset: asset3}),
+			WithFeeWallet(treasury),
+		)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+	updateWalletBalance(wallet2, asset3, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 5, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, &tFeeExemptOrder{newOrder("2", wallet2, false, 2, 10)}))
+
+	if walletBalance(wallet2, asset3) != 10 || walletBalance(treasury, asset3) != 0 {
+		t.Fatal("fee-exempt order must not be charged",
+			walletBalance(wallet2, asset3), walletBalance(treasury, asset3))
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 2, 10)))
+
+	if walletBalance(wallet2, asset3) != 8 || walletBalance(treasury, asset3) != 2 {
+		t.Fatal("regular order must be charged",
+			walletBalance(wallet2, asset3), walletBalance(treasury, asset3))
+	}
+}
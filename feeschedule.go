@@ -0,0 +1,96 @@
+package fastme
+
+import "context"
+
+// FeeRates are maker and taker fee rates in basis points of the credited
+// value, e.g. 10 for 0.1%. Nil rate charges nothing, negative rate pays the
+// rebate, see SetRebateWallet
+type FeeRates struct {
+	Maker, Taker Value
+}
+
+// FeeTier applies the rates to wallets with trading volume of at least MinVolume
+type FeeTier struct {
+	MinVolume Value
+	FeeRates
+}
+
+// VolumeProvider returns the trading volume of the wallet selecting its fee
+// tier, e.g. 30 days turnover kept by the application
+type VolumeProvider func(ctx context.Context, w Wallet) Value
+
+// FeeSchedule is the FeeHandler charging tiered maker and taker fees from
+// the credited value: out = in - in * rate * BasisPoint. The withheld fee
+// isn't credited to any wallet
+type FeeSchedule struct {
+	// BasisPoint is 0.0001 in the value type, nothing is charged if it's nil
+	BasisPoint Value
+
+	// Tiers are sorted by MinVolume ascending. The last tier with MinVolume
+	// not above the wallet volume applies, the first one if Volume is nil
+	// or the volume is below all tiers
+	Tiers []FeeTier
+
+	// Volume provides the wallet volume selecting the tier
+	Volume VolumeProvider
+
+	// Overrides are rates of the specific wallets, tiers are ignored for them
+	Overrides map[Wallet]FeeRates
+}
+
+// HandleFeeMaker subtracts the maker fee from the credited value
+func (s *FeeSchedule) HandleFeeMaker(ctx context.Context, o Order, _ Asset, in Value) Value {
+	return s.charge(in, s.Rates(ctx, o.Owner()).Maker)
+}
+
+// HandleFeeTaker subtracts the taker fee from the credited value
+func (s *FeeSchedule) HandleFeeTaker(ctx context.Context, o Order, _ Asset, in Value) Value {
+	return s.charge(in, s.Rates(ctx, o.Owner()).Taker)
+}
+
+// Rates returns the fee rates of the wallet
+func (s *FeeSchedule) Rates(ctx context.Context, w Wallet) FeeRates {
+	if rates, ok := s.Overrides[w]; ok {
+		return rates
+	}
+
+	if len(s.Tiers) == 0 {
+		return FeeRates{}
+	}
+
+	tier := s.Tiers[0]
+	if s.Volume == nil {
+		return tier.FeeRates
+	}
+
+	volume := s.Volume(ctx, w)
+	if volume == nil {
+		return tier.FeeRates
+	}
+
+	for _, t := range s.Tiers[1:] {
+		if t.MinVolume.Cmp(volume) > 0 {
+			break
+		}
+		tier = t
+	}
+
+	return tier.FeeRates
+}
+
+// Fee returns the fee charged from the value at the rate
+func (s *FeeSchedule) Fee(in, rate Value) Value {
+	if rate == nil || s.BasisPoint == nil {
+		return in.Sub(in)
+	}
+	return in.Mul(rate).Mul(s.BasisPoint)
+}
+
+// charge subtracts the fee, the credited value is never negative
+func (s *FeeSchedule) charge(in, rate Value) Value {
+	fee := s.Fee(in, rate)
+	if fee.Cmp(in) > 0 {
+		return in.Sub(in)
+	}
+	return in.Sub(fee)
+}
@@ -0,0 +1,66 @@
+package fastme
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func assertFloat(t *testing.T, v Value, expected float64) {
+	t.Helper()
+	if math.Abs(float64(v.(tFloat64))-expected) > 1e-9 {
+		t.Fatal("unexpected value", v, expected)
+	}
+}
+
+func TestFeeSchedule(t *testing.T) {
+	var (
+		asset1, asset2            = Asset("apples"), Asset("dollars")
+		wallet1, wallet2, wallet3 = newWallet(), newWallet(), newWallet()
+		volumes                   = map[Wallet]Value{wallet1: tFloat64(500), wallet2: tFloat64(5000)}
+
+		schedule = &FeeSchedule{
+			BasisPoint: tFloat64(0.0001),
+			Tiers: []FeeTier{
+				{MinVolume: tFloat64(0), FeeRates: FeeRates{Maker: tFloat64(10), Taker: tFloat64(20)}},
+				{MinVolume: tFloat64(1000), FeeRates: FeeRates{Maker: tFloat64(5), Taker: tFloat64(10)}},
+				{MinVolume: tFloat64(10000), FeeRates: FeeRates{Maker: tFloat64(0), Taker: tFloat64(5)}},
+			},
+			Volume: func(ctx context.Context, w Wallet) Value {
+				return volumes[w]
+			},
+			Overrides: map[Wallet]FeeRates{wallet3: {Taker: tFloat64(1)}},
+		}
+	)
+
+	var (
+		order1 = newOrder("1", wallet1, true, 1, 10)
+		order2 = newOrder("2", wallet2, false, 1, 10)
+		order3 = newOrder("3", wallet3, false, 1, 10)
+	)
+
+	assertFloat(t, schedule.HandleFeeMaker(context.Background(), order1, asset2, tFloat64(1000)), 999)
+	assertFloat(t, schedule.HandleFeeTaker(context.Background(), order1, asset2, tFloat64(1000)), 998)
+	assertFloat(t, schedule.HandleFeeMaker(context.Background(), order2, asset1, tFloat64(1000)), 999.5)
+	assertFloat(t, schedule.HandleFeeTaker(context.Background(), order2, asset1, tFloat64(1000)), 999)
+
+	// Overrides ignore tiers, nil rate charges nothing
+	assertFloat(t, schedule.HandleFeeMaker(context.Background(), order3, asset1, tFloat64(1000)), 1000)
+	assertFloat(t, schedule.HandleFeeTaker(context.Background(), order3, asset1, tFloat64(1000)), 999.9)
+
+	// The top tier applies to the largest volume
+	volumes[wallet2] = tFloat64(20000)
+	if rates := schedule.Rates(context.Background(), wallet2); rates.Maker.Sign() != 0 {
+		t.Fatal("top tier must apply", rates)
+	}
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	engine := NewEngine(asset1, asset2, WithFeeHandler(schedule))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order1))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order2))
+
+	assertFloat(t, wallet1.Balance(context.Background(), asset2), 9.99)
+	assertFloat(t, wallet2.Balance(context.Background(), asset1), 0.9995)
+}
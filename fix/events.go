@@ -0,0 +1,99 @@
+package fix
+
+import (
+	"context"
+
+	"github.com/newity/fastme"
+)
+
+// OnIncomingOrderPartial is reported by OnTrade
+func (g *Gateway) OnIncomingOrderPartial(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnIncomingOrderDone is reported by OnTrade
+func (g *Gateway) OnIncomingOrderDone(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnExistingOrderPartial is reported by OnTrade
+func (g *Gateway) OnExistingOrderPartial(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnExistingOrderDone is reported by OnTrade
+func (g *Gateway) OnExistingOrderDone(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnBalanceChanged is not reported
+func (g *Gateway) OnBalanceChanged(context.Context, fastme.Wallet, fastme.Asset, fastme.Value) {}
+
+// OnInOrderChanged is not reported
+func (g *Gateway) OnInOrderChanged(context.Context, fastme.Wallet, fastme.Asset, fastme.Value) {}
+
+// OnIncomingOrderPlaced reports New order if it's not filled partially
+func (g *Gateway) OnIncomingOrderPlaced(ctx context.Context, o fastme.Order) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	g.announce(ctx, o, g.track(o, nil))
+}
+
+// OnTrade reports fills of the maker and the taker
+func (g *Gateway) OnTrade(ctx context.Context, t fastme.Trade) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	g.fill(ctx, t.MakerOrder, t)
+
+	g.announce(ctx, t.TakerOrder, g.track(t.TakerOrder, t.Quantity))
+	g.fill(ctx, t.TakerOrder, t)
+}
+
+// fill must be called with g.m locked
+func (g *Gateway) fill(ctx context.Context, o fastme.Order, t fastme.Trade) {
+	ex := g.track(o, t.Quantity)
+	ex.cumQty = t.Quantity.Add(ex.cumQty)
+	ex.cumValue = t.Price.Mul(t.Quantity).Add(ex.cumValue)
+
+	g.send(ex.account, g.report(ctx, ex, o.ID(), o, ExecTrade, t.Price, t.Quantity))
+
+	if o.Quantity().Sign() == 0 {
+		delete(g.orders, o.ID())
+	}
+}
+
+// OnIncomingOrderCanceled reports canceled remainder of the incoming order
+func (g *Gateway) OnIncomingOrderCanceled(ctx context.Context, o fastme.Order) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	ex := g.track(o, nil)
+	g.announce(ctx, o, ex)
+	g.done(ctx, ex, o, ExecCanceled)
+}
+
+// OnExistingOrderCanceled reports canceled order. Orders canceled by
+// OrderCancelReplaceRequest are reported as Replaced instead
+func (g *Gateway) OnExistingOrderCanceled(ctx context.Context, o fastme.Order) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	if g.replaced[o.ID()] {
+		return
+	}
+
+	ex := g.track(o, nil)
+	if clOrdID, ok := g.canceled[o.ID()]; ok {
+		ex.orig, ex.clOrdID = ex.clOrdID, clOrdID
+	}
+
+	g.done(ctx, ex, o, ExecCanceled)
+}
+
+// OnExistingOrderExpired reports expired order
+func (g *Gateway) OnExistingOrderExpired(ctx context.Context, o fastme.Order) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	g.done(ctx, g.track(o, nil), o, ExecExpired)
+}
+
+// done must be called with g.m locked
+func (g *Gateway) done(ctx context.Context, ex *execution, o fastme.Order, execType string) {
+	g.send(ex.account, g.report(ctx, ex, o.ID(), o, execType, nil, nil))
+	delete(g.orders, o.ID())
+}
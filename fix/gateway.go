@@ -0,0 +1,622 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+// Gateway errors
+var (
+	ErrUnsupportedMessage = errors.New("Unsupported FIX message type")
+
+	ErrUnknownSymbol = errors.New("Unknown symbol")
+
+	ErrInvalidField = errors.New("Invalid or missing FIX field")
+)
+
+// Side values
+const (
+	SideBuy  = "1"
+	SideSell = "2"
+)
+
+// OrdType values
+const (
+	OrdTypeMarket = "1"
+	OrdTypeLimit  = "2"
+)
+
+// ExecType and OrdStatus values
+const (
+	ExecNew             = "0"
+	ExecPartiallyFilled = "1"
+	ExecFilled          = "2"
+	ExecCanceled        = "4"
+	ExecReplaced        = "5"
+	ExecRejected        = "8"
+	ExecExpired         = "C"
+	ExecTrade           = "F"
+)
+
+// OrdRejReason values
+const (
+	RejUnknownSymbol     = "1"
+	RejExchangeClosed    = "2"
+	RejExceedsLimit      = "3"
+	RejDuplicateOrder    = "6"
+	RejIncorrectQuantity = "13"
+	RejPriceBand         = "16"
+	RejPriceIncrement    = "18"
+	RejOther             = "99"
+)
+
+// Responses of OrderCancelReject
+const (
+	cxlRejCancel  = "1"
+	cxlRejReplace = "2"
+)
+
+const transactTimeFormat = "20060102-15:04:05.000"
+
+// OrderFactory creates engine orders of FIX accounts
+type OrderFactory interface {
+	// Value parses FIX price or quantity
+	Value(string) (fastme.Value, error)
+
+	// Order creates order of the account. Record ID is composed of the
+	// account and ClOrdID, so it's unique across accounts
+	Order(ctx context.Context, account string, r fastme.OrderRecord) (fastme.Order, error)
+
+	// Account returns FIX account of the wallet
+	Account(fastme.Wallet) string
+}
+
+// Config configures the gateway
+type Config struct {
+	// Symbol is the engine trading pair, messages of other symbols are rejected
+	Symbol string
+
+	// Factory creates orders, required
+	Factory OrderFactory
+
+	// Send delivers the report to the session of the account, required
+	Send func(account string, report Message)
+
+	// Listener receives engine events of gateway commands, optional
+	Listener fastme.EventListener
+
+	// Formatter renders report values and must match the engine Formatter.
+	// Values are rendered by Hash if it's nil
+	Formatter fastme.Formatter
+}
+
+// execution tracks the order for reports
+type execution struct {
+	account  string
+	clOrdID  string
+	orig     string
+	sell     bool
+	market   bool
+	price    fastme.Value
+	orderQty fastme.Value
+	cumQty   fastme.Value
+
+	// cumValue is the executed notional for AvgPx
+	cumValue fastme.Value
+
+	// announced is false until New or Replaced report is sent
+	announced bool
+}
+
+// Gateway handles order entry messages and reports engine events as
+// ExecutionReports. It must receive events of all engine commands, including
+// those not issued by the gateway, to keep reports consistent
+type Gateway struct {
+	engine   *fastme.Engine
+	cfg      Config
+	listener fastme.EventListener
+
+	orders   map[string]*execution
+	canceled map[string]string
+	replaced map[string]bool
+	execSeq  uint64
+	m        sync.Mutex
+}
+
+// NewGateway creates the gateway of the engine
+func NewGateway(e *fastme.Engine, cfg Config) *Gateway {
+	g := &Gateway{
+		engine:   e,
+		cfg:      cfg,
+		orders:   make(map[string]*execution),
+		canceled: make(map[string]string),
+		replaced: make(map[string]bool),
+	}
+
+	g.listener = g
+	if cfg.Listener != nil {
+		g.listener = fastme.NewListenerMux(g, cfg.Listener)
+	}
+
+	return g
+}
+
+// OrderID returns engine order ID of the account order
+func OrderID(account, clOrdID string) string {
+	return account + "/" + clOrdID
+}
+
+// Handle processes NewOrderSingle, OrderCancelRequest and
+// OrderCancelReplaceRequest. Rejections are reported to the account and
+// returned
+func (g *Gateway) Handle(ctx context.Context, m Message) error {
+	switch m.Type() {
+	case MsgNewOrderSingle:
+		return g.newOrder(ctx, m)
+	case MsgOrderCancelRequest:
+		return g.cancelOrder(ctx, m)
+	case MsgOrderCancelReplaceRequest:
+		return g.replaceOrder(ctx, m)
+	default:
+		return ErrUnsupportedMessage
+	}
+}
+
+func account(m Message) string {
+	if a := m.Get(TagAccount); a != "" {
+		return a
+	}
+	return m.Get(TagSenderCompID)
+}
+
+// request parses common fields of order entry messages
+func (g *Gateway) request(m Message) (ex *execution, err error) {
+	ex = &execution{
+		account: account(m),
+		clOrdID: m.Get(TagClOrdID),
+		orig:    m.Get(TagOrigClOrdID),
+	}
+
+	if ex.account == "" || ex.clOrdID == "" {
+		return ex, ErrInvalidField
+	}
+
+	if g.cfg.Symbol != "" && m.Get(TagSymbol) != g.cfg.Symbol {
+		return ex, ErrUnknownSymbol
+	}
+
+	switch m.Get(TagSide) {
+	case SideBuy:
+	case SideSell:
+		ex.sell = true
+	default:
+		return ex, ErrInvalidField
+	}
+
+	return ex, nil
+}
+
+// order parses price and quantity fields of new or replacing order
+func (g *Gateway) order(m Message, ex *execution) (err error) {
+	switch m.Get(TagOrdType) {
+	case OrdTypeMarket:
+		ex.market = true
+		ex.price, err = g.cfg.Factory.Value("0")
+	case OrdTypeLimit:
+		ex.price, err = g.cfg.Factory.Value(m.Get(TagPrice))
+	default:
+		err = ErrInvalidField
+	}
+
+	if err != nil {
+		return
+	}
+
+	ex.orderQty, err = g.cfg.Factory.Value(m.Get(TagOrderQty))
+	return
+}
+
+func (g *Gateway) newOrder(ctx context.Context, m Message) error {
+	ex, err := g.request(m)
+	if err == nil {
+		err = g.order(m, ex)
+	}
+
+	var o fastme.Order
+	if err == nil {
+		o, err = g.cfg.Factory.Order(ctx, ex.account, fastme.OrderRecord{
+			ID:       OrderID(ex.account, ex.clOrdID),
+			Sell:     ex.sell,
+			Price:    ex.price,
+			Quantity: ex.orderQty,
+		})
+	}
+
+	if err == nil {
+		ex.cumQty = ex.orderQty.Sub(ex.orderQty)
+
+		g.m.Lock()
+		if _, ok := g.orders[o.ID()]; ok {
+			err = fastme.ErrOrderExists
+		} else {
+			g.orders[o.ID()] = ex
+		}
+		g.m.Unlock()
+	}
+
+	if err == nil {
+		if err = g.engine.PlaceOrder(ctx, g.listener, o); err != nil {
+			g.m.Lock()
+			if !ex.announced {
+				delete(g.orders, o.ID())
+			}
+			g.m.Unlock()
+		}
+	}
+
+	if err != nil && !g.announced(ex) {
+		g.send(ex.account, g.reject(ctx, m, ex, err))
+	}
+
+	return err
+}
+
+func (g *Gateway) cancelOrder(ctx context.Context, m Message) error {
+	ex, err := g.request(m)
+	id := OrderID(ex.account, ex.orig)
+
+	if err == nil {
+		g.m.Lock()
+		g.canceled[id] = ex.clOrdID
+		g.m.Unlock()
+
+		err = g.engine.CancelOrderByID(ctx, g.listener, id)
+
+		g.m.Lock()
+		delete(g.canceled, id)
+		g.m.Unlock()
+	}
+
+	if err != nil {
+		g.send(ex.account, g.cancelReject(ctx, m, ex, cxlRejCancel, err))
+	}
+
+	return err
+}
+
+func (g *Gateway) replaceOrder(ctx context.Context, m Message) error {
+	ex, err := g.request(m)
+	if err == nil {
+		err = g.order(m, ex)
+	}
+
+	if err == nil && ex.market {
+		err = fastme.ErrInvalidPrice
+	}
+
+	var (
+		id      = OrderID(ex.account, ex.orig)
+		newID   = OrderID(ex.account, ex.clOrdID)
+		resting fastme.Order
+		n       fastme.Order
+	)
+
+	if err == nil {
+		resting, err = g.engine.FindOrder(id)
+	}
+
+	if err == nil {
+		g.m.Lock()
+		if prev, ok := g.orders[id]; ok {
+			ex.cumQty, ex.cumValue = prev.cumQty, prev.cumValue
+		} else {
+			ex.cumQty = ex.orderQty.Sub(ex.orderQty)
+		}
+		g.m.Unlock()
+
+		// OrderQty includes executed quantity of the replaced order
+		n, err = g.cfg.Factory.Order(ctx, ex.account, fastme.OrderRecord{
+			ID:       newID,
+			Sell:     ex.sell,
+			Price:    ex.price,
+			Quantity: ex.orderQty.Sub(ex.cumQty),
+		})
+	}
+
+	var (
+		prev    *execution
+		tracked bool
+	)
+
+	if err == nil {
+		g.m.Lock()
+		if _, ok := g.orders[newID]; ok && newID != id {
+			err = fastme.ErrOrderExists
+		} else {
+			prev, tracked = g.orders[id]
+			g.replaced[id] = true
+			g.orders[newID] = ex
+		}
+		g.m.Unlock()
+	}
+
+	if err == nil {
+		_, err = g.engine.ReplaceOrder(ctx, g.listener, resting, n)
+
+		g.m.Lock()
+		delete(g.replaced, id)
+		switch {
+		case err == nil && !ex.announced:
+			// Same price replacement doesn't produce order events
+			ex.announced = true
+			delete(g.orders, id)
+			g.orders[newID] = ex
+			g.send(ex.account, g.report(ctx, ex, newID, n, ExecReplaced, nil, nil))
+			ex.orig = ""
+		case err != nil && !ex.announced:
+			delete(g.orders, newID)
+			if tracked {
+				g.orders[id] = prev
+			}
+		}
+		g.m.Unlock()
+	}
+
+	if err != nil && !g.announced(ex) {
+		g.send(ex.account, g.cancelReject(ctx, m, ex, cxlRejReplace, err))
+	}
+
+	return err
+}
+
+func (g *Gateway) send(account string, report Message) {
+	g.cfg.Send(account, report)
+}
+
+func (g *Gateway) format(v fastme.Value) string {
+	switch {
+	case v == nil:
+		return ""
+	case g.cfg.Formatter == nil:
+		return v.Hash()
+	default:
+		return g.cfg.Formatter.Format(v)
+	}
+}
+
+func transactTime(ctx context.Context) string {
+	t, ok := fastme.EventTime(ctx)
+	if !ok {
+		t = time.Now()
+	}
+	return t.UTC().Format(transactTimeFormat)
+}
+
+func side(sell bool) string {
+	if sell {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// nextExecID must be called with g.m locked
+func (g *Gateway) nextExecID() string {
+	g.execSeq++
+	return strconv.FormatUint(g.execSeq, 10)
+}
+
+// report must be called with g.m locked
+func (g *Gateway) report(
+	ctx context.Context,
+	ex *execution,
+	id string,
+	o fastme.Order,
+	execType string,
+	lastPx, lastQty fastme.Value,
+) Message {
+	var (
+		status = execType
+		leaves = o.Quantity()
+	)
+
+	switch execType {
+	case ExecNew, ExecReplaced:
+		status = ExecNew
+		if ex.cumQty.Sign() > 0 {
+			status = ExecPartiallyFilled
+		}
+	case ExecTrade:
+		status = ExecPartiallyFilled
+		if leaves.Sign() == 0 {
+			status = ExecFilled
+		}
+	case ExecCanceled, ExecExpired:
+		leaves = leaves.Sub(leaves)
+	}
+
+	m := Message{
+		{TagMsgType, MsgExecutionReport},
+		{TagOrderID, id},
+		{TagClOrdID, ex.clOrdID},
+	}
+
+	if ex.orig != "" && (execType == ExecReplaced || execType == ExecCanceled) {
+		m = append(m, Field{TagOrigClOrdID, ex.orig})
+	}
+
+	m = append(m,
+		Field{TagExecID, g.nextExecID()},
+		Field{TagExecType, execType},
+		Field{TagOrdStatus, status},
+		Field{TagAccount, ex.account},
+		Field{TagSymbol, g.cfg.Symbol},
+		Field{TagSide, side(ex.sell)},
+		Field{TagOrderQty, g.format(ex.orderQty)},
+	)
+
+	if ex.market {
+		m = append(m, Field{TagOrdType, OrdTypeMarket})
+	} else {
+		m = append(m, Field{TagOrdType, OrdTypeLimit}, Field{TagPrice, g.format(ex.price)})
+	}
+
+	if lastQty != nil {
+		m = append(m, Field{TagLastQty, g.format(lastQty)}, Field{TagLastPx, g.format(lastPx)})
+	}
+
+	// AvgPx is reported as zero for values without fastme.DivValue
+	avgPx := "0"
+	if v, err := (fastme.Volume{Price: ex.cumValue, Quantity: ex.cumQty}).AvgPrice(); err == nil {
+		avgPx = g.format(v)
+	}
+
+	return append(m,
+		Field{TagLeavesQty, g.format(leaves)},
+		Field{TagCumQty, g.format(ex.cumQty)},
+		Field{TagAvgPx, avgPx},
+		Field{TagTransactTime, transactTime(ctx)},
+	)
+}
+
+func (g *Gateway) reject(ctx context.Context, m Message, ex *execution, err error) Message {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	return Message{
+		{TagMsgType, MsgExecutionReport},
+		{TagOrderID, "NONE"},
+		{TagClOrdID, ex.clOrdID},
+		{TagExecID, g.nextExecID()},
+		{TagExecType, ExecRejected},
+		{TagOrdStatus, ExecRejected},
+		{TagAccount, ex.account},
+		{TagSymbol, m.Get(TagSymbol)},
+		{TagSide, m.Get(TagSide)},
+		{TagOrdRejReason, rejectReason(err)},
+		{TagLeavesQty, "0"},
+		{TagCumQty, "0"},
+		{TagAvgPx, "0"},
+		{TagTransactTime, transactTime(ctx)},
+		{TagText, err.Error()},
+	}
+}
+
+// rejectReason maps engine errors to OrdRejReason
+func rejectReason(err error) string {
+	var (
+		qty   *fastme.InvalidQuantityError
+		limit *fastme.LimitError
+	)
+
+	switch {
+	case errors.Is(err, ErrUnknownSymbol):
+		return RejUnknownSymbol
+	case errors.Is(err, fastme.ErrInsufficientFunds),
+		errors.As(err, &limit):
+		return RejExceedsLimit
+	case errors.Is(err, fastme.ErrOrderExists):
+		return RejDuplicateOrder
+	case errors.Is(err, fastme.ErrTradingHalted),
+		errors.Is(err, fastme.ErrCancelOnly):
+		return RejExchangeClosed
+	case errors.Is(err, fastme.ErrInvalidTick):
+		return RejPriceIncrement
+	case errors.Is(err, fastme.ErrPriceTooHigh):
+		return RejPriceBand
+	case errors.As(err, &qty):
+		return RejIncorrectQuantity
+	default:
+		return RejOther
+	}
+}
+
+func (g *Gateway) cancelReject(
+	ctx context.Context,
+	m Message,
+	ex *execution,
+	responseTo string,
+	err error,
+) Message {
+	var (
+		id     = OrderID(ex.account, ex.orig)
+		status = ExecRejected
+	)
+
+	g.m.Lock()
+	if prev, ok := g.orders[id]; ok {
+		status = ExecNew
+		if prev.cumQty.Sign() > 0 {
+			status = ExecPartiallyFilled
+		}
+	} else {
+		id = "NONE"
+	}
+	g.m.Unlock()
+
+	return Message{
+		{TagMsgType, MsgOrderCancelReject},
+		{TagOrderID, id},
+		{TagClOrdID, ex.clOrdID},
+		{TagOrigClOrdID, ex.orig},
+		{TagOrdStatus, status},
+		{TagAccount, ex.account},
+		{TagCxlRejResponseTo, responseTo},
+		{TagTransactTime, transactTime(ctx)},
+		{TagText, err.Error()},
+	}
+}
+
+// track returns execution of the order. Orders placed not by the gateway are
+// registered without New report. Must be called with g.m locked
+func (g *Gateway) track(o fastme.Order, filled fastme.Value) *execution {
+	if ex, ok := g.orders[o.ID()]; ok {
+		return ex
+	}
+
+	account := g.cfg.Factory.Account(o.Owner())
+	ex := &execution{
+		account:   account,
+		clOrdID:   strings.TrimPrefix(o.ID(), account+"/"),
+		sell:      o.Sell(),
+		market:    o.Price().Sign() == 0,
+		price:     o.Price(),
+		orderQty:  o.Quantity().Add(filled),
+		cumQty:    o.Quantity().Sub(o.Quantity()),
+		announced: true,
+	}
+
+	g.orders[o.ID()] = ex
+	return ex
+}
+
+func (g *Gateway) announced(ex *execution) bool {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	return ex.announced
+}
+
+// announce sends New or Replaced report of the incoming order once. Must be
+// called with g.m locked
+func (g *Gateway) announce(ctx context.Context, o fastme.Order, ex *execution) {
+	if ex.announced {
+		return
+	}
+	ex.announced = true
+
+	execType := ExecNew
+	if ex.orig != "" {
+		execType = ExecReplaced
+		delete(g.orders, OrderID(ex.account, ex.orig))
+		g.orders[o.ID()] = ex
+	}
+
+	g.send(ex.account, g.report(ctx, ex, o.ID(), o, execType, nil, nil))
+	ex.orig = ""
+}
@@ -0,0 +1,210 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/newity/fastme"
+)
+
+type tValue float64
+
+func (t tValue) Add(n fastme.Value) fastme.Value { return t + t.checkNil(n) }
+func (t tValue) Sub(n fastme.Value) fastme.Value { return t - t.checkNil(n) }
+func (t tValue) Mul(n fastme.Value) fastme.Value { return t * t.checkNil(n) }
+func (t tValue) Hash() string                    { return strconv.FormatFloat(float64(t), 'f', -1, 64) }
+
+func (t tValue) Cmp(n fastme.Value) int {
+	switch v := t.checkNil(n); {
+	case t > v:
+		return 1
+	case t < v:
+		return -1
+	}
+	return 0
+}
+
+func (t tValue) Sign() int {
+	return t.Cmp(tValue(0))
+}
+
+func (t tValue) Div(n fastme.Value) fastme.Value { return t / t.checkNil(n) }
+
+func (t tValue) checkNil(v fastme.Value) tValue {
+	if v == nil {
+		return 0
+	}
+	return v.(tValue)
+}
+
+type tWallet struct {
+	account string
+	values  map[string]fastme.Value
+}
+
+func (t *tWallet) get(key string) fastme.Value {
+	if v, ok := t.values[key]; ok {
+		return v
+	}
+	return tValue(0)
+}
+
+func (t *tWallet) Balance(ctx context.Context, a fastme.Asset) fastme.Value {
+	return t.get("balance/" + string(a))
+}
+
+func (t *tWallet) UpdateBalance(ctx context.Context, a fastme.Asset, v fastme.Value) {
+	t.values["balance/"+string(a)] = v
+}
+
+func (t *tWallet) InOrder(ctx context.Context, a fastme.Asset) fastme.Value {
+	return t.get("in_order/" + string(a))
+}
+
+func (t *tWallet) UpdateInOrder(ctx context.Context, a fastme.Asset, v fastme.Value) {
+	t.values["in_order/"+string(a)] = v
+}
+
+type tOrder struct {
+	id              string
+	owner           fastme.Wallet
+	sell            bool
+	price, quantity fastme.Value
+}
+
+func (t *tOrder) ID() string                    { return t.id }
+func (t *tOrder) Owner() fastme.Wallet          { return t.owner }
+func (t *tOrder) Sell() bool                    { return t.sell }
+func (t *tOrder) Price() fastme.Value           { return t.price }
+func (t *tOrder) Quantity() fastme.Value        { return t.quantity }
+func (t *tOrder) UpdateQuantity(v fastme.Value) { t.quantity = v }
+
+type tFactory map[string]*tWallet
+
+func (t tFactory) Value(s string) (fastme.Value, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	return tValue(v), err
+}
+
+func (t tFactory) Order(ctx context.Context, account string, r fastme.OrderRecord) (fastme.Order, error) {
+	return &tOrder{
+		id:       r.ID,
+		owner:    t[account],
+		sell:     r.Sell,
+		price:    r.Price,
+		quantity: r.Quantity,
+	}, nil
+}
+
+func (t tFactory) Account(w fastme.Wallet) string {
+	return w.(*tWallet).account
+}
+
+type tReport struct {
+	account string
+	Message
+}
+
+func TestGateway(t *testing.T) {
+	var (
+		engine  = fastme.NewEngine("apples", "dollars")
+		reports []tReport
+		factory = tFactory{
+			"A": &tWallet{account: "A", values: map[string]fastme.Value{"balance/apples": tValue(3)}},
+			"B": &tWallet{account: "B", values: map[string]fastme.Value{"balance/dollars": tValue(100)}},
+		}
+		gateway = NewGateway(engine, Config{
+			Symbol:  "APL/USD",
+			Factory: factory,
+			Send:    func(account string, m Message) { reports = append(reports, tReport{account, m}) },
+		})
+	)
+
+	order := func(msgType, account, clOrdID, orig, side, qty, price string) Message {
+		return Message{
+			{TagMsgType, msgType},
+			{TagClOrdID, clOrdID},
+			{TagOrigClOrdID, orig},
+			{TagAccount, account},
+			{TagSymbol, "APL/USD"},
+			{TagSide, side},
+			{TagOrdType, OrdTypeLimit},
+			{TagOrderQty, qty},
+			{TagPrice, price},
+		}
+	}
+
+	expect := func(n int, fields ...[3]string) {
+		t.Helper()
+
+		if len(reports) != n {
+			t.Fatal("unexpected reports count", len(reports))
+		}
+
+		for _, f := range fields {
+			i, _ := strconv.Atoi(f[0])
+			tag, _ := strconv.Atoi(f[1])
+			if got := reports[i].Get(tag); got != f[2] {
+				t.Fatalf("report %d tag %d: %q != %q", i, tag, got, f[2])
+			}
+		}
+		reports = nil
+	}
+
+	if err := gateway.Handle(context.Background(), order(MsgNewOrderSingle, "A", "1", "", SideSell, "2", "10")); err != nil {
+		t.Fatal(err)
+	}
+	expect(1, [3]string{"0", "150", ExecNew}, [3]string{"0", "37", "A/1"}, [3]string{"0", "151", "2"})
+
+	if err := gateway.Handle(context.Background(), order(MsgNewOrderSingle, "B", "1", "", SideBuy, "1", "10")); err != nil {
+		t.Fatal(err)
+	}
+	expect(3,
+		// Maker fill
+		[3]string{"0", "150", ExecTrade}, [3]string{"0", "39", ExecPartiallyFilled},
+		[3]string{"0", "14", "1"}, [3]string{"0", "151", "1"},
+		// Taker is accepted and filled
+		[3]string{"1", "150", ExecNew}, [3]string{"1", "1", "B"},
+		[3]string{"2", "150", ExecTrade}, [3]string{"2", "39", ExecFilled}, [3]string{"2", "31", "10"},
+		[3]string{"2", "6", "10"},
+	)
+
+	// OrderQty includes executed quantity
+	if err := gateway.Handle(context.Background(), order(MsgOrderCancelReplaceRequest, "A", "2", "1", SideSell, "3", "11")); err != nil {
+		t.Fatal(err)
+	}
+	expect(1,
+		[3]string{"0", "150", ExecReplaced}, [3]string{"0", "41", "1"}, [3]string{"0", "11", "2"},
+		[3]string{"0", "151", "2"}, [3]string{"0", "14", "1"}, [3]string{"0", "39", ExecPartiallyFilled},
+	)
+
+	// Same price replacement has no engine order events
+	if err := gateway.Handle(context.Background(), order(MsgOrderCancelReplaceRequest, "A", "3", "2", SideSell, "2", "11")); err != nil {
+		t.Fatal(err)
+	}
+	expect(1, [3]string{"0", "150", ExecReplaced}, [3]string{"0", "41", "2"}, [3]string{"0", "151", "1"})
+
+	if err := gateway.Handle(context.Background(), order(MsgOrderCancelRequest, "A", "4", "3", SideSell, "", "")); err != nil {
+		t.Fatal(err)
+	}
+	expect(1, [3]string{"0", "150", ExecCanceled}, [3]string{"0", "11", "4"}, [3]string{"0", "41", "3"}, [3]string{"0", "151", "0"})
+
+	if err := gateway.Handle(context.Background(), order(MsgOrderCancelRequest, "A", "5", "3", SideSell, "", "")); err != fastme.ErrOrderNotFound {
+		t.Fatal("unknown order must be rejected")
+	}
+	expect(1, [3]string{"0", "35", MsgOrderCancelReject}, [3]string{"0", "434", cxlRejCancel})
+
+	m := order(MsgNewOrderSingle, "B", "2", "", SideBuy, "1", "10")
+	m[4].Value = "APL/EUR"
+	if err := gateway.Handle(context.Background(), m); err != ErrUnknownSymbol {
+		t.Fatal("unknown symbol must be rejected")
+	}
+	expect(1, [3]string{"0", "150", ExecRejected}, [3]string{"0", "11", "2"}, [3]string{"0", "103", RejUnknownSymbol})
+
+	if err := gateway.Handle(context.Background(), order(MsgNewOrderSingle, "B", "3", "", SideBuy, "100", "10")); !errors.Is(err, fastme.ErrInsufficientFunds) {
+		t.Fatal("order must be rejected with insufficient funds", err)
+	}
+	expect(1, [3]string{"0", "150", ExecRejected}, [3]string{"0", "103", RejExceedsLimit})
+}
@@ -0,0 +1,184 @@
+// Package fix translates FIX 4.4 order entry messages into engine commands
+// and engine events into execution reports. Session level (logon,
+// heartbeats, sequence numbers and resend requests) is left to the FIX
+// engine the gateway is plugged into. The package has no dependencies
+// besides the engine
+package fix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Message errors
+var (
+	ErrInvalidMessage = errors.New("Invalid FIX message")
+
+	ErrChecksum = errors.New("Invalid FIX message checksum")
+)
+
+// BeginString of supported protocol version
+const BeginString = "FIX.4.4"
+
+const soh = 0x01
+
+// Tags
+const (
+	TagAccount          = 1
+	TagAvgPx            = 6
+	TagBeginString      = 8
+	TagBodyLength       = 9
+	TagCheckSum         = 10
+	TagClOrdID          = 11
+	TagCumQty           = 14
+	TagExecID           = 17
+	TagLastPx           = 31
+	TagLastQty          = 32
+	TagMsgType          = 35
+	TagOrderID          = 37
+	TagOrderQty         = 38
+	TagOrdStatus        = 39
+	TagOrdType          = 40
+	TagOrigClOrdID      = 41
+	TagPrice            = 44
+	TagSenderCompID     = 49
+	TagSide             = 54
+	TagSymbol           = 55
+	TagText             = 58
+	TagTransactTime     = 60
+	TagOrdRejReason     = 103
+	TagExecType         = 150
+	TagLeavesQty        = 151
+	TagCxlRejResponseTo = 434
+)
+
+// Message types
+const (
+	MsgExecutionReport           = "8"
+	MsgOrderCancelReject         = "9"
+	MsgNewOrderSingle            = "D"
+	MsgOrderCancelRequest        = "F"
+	MsgOrderCancelReplaceRequest = "G"
+)
+
+// Field is the tag=value pair
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message is the list of body fields in order. BeginString, BodyLength and
+// CheckSum are added by Marshal and removed by Parse
+type Message []Field
+
+// Get returns value of the first field with the tag or empty string
+func (m Message) Get(tag int) string {
+	for _, f := range m {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Type returns MsgType of the message
+func (m Message) Type() string {
+	return m.Get(TagMsgType)
+}
+
+// Marshal encodes the message adding standard header and trailer
+func (m Message) Marshal() []byte {
+	var body bytes.Buffer
+	for _, f := range m {
+		body.WriteString(strconv.Itoa(f.Tag))
+		body.WriteByte('=')
+		body.WriteString(f.Value)
+		body.WriteByte(soh)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d=%s\x01%d=%d\x01", TagBeginString, BeginString, TagBodyLength, body.Len())
+	b.Write(body.Bytes())
+	fmt.Fprintf(&b, "%d=%03d\x01", TagCheckSum, checksum(b.Bytes()))
+
+	return b.Bytes()
+}
+
+// Parse decodes the message validating BeginString, BodyLength and CheckSum
+func Parse(data []byte) (Message, error) {
+	var (
+		fields []Field
+		start  int
+	)
+
+	for start < len(data) {
+		end := bytes.IndexByte(data[start:], soh)
+		if end < 0 {
+			return nil, ErrInvalidMessage
+		}
+		end += start
+
+		eq := bytes.IndexByte(data[start:end], '=')
+		if eq <= 0 {
+			return nil, ErrInvalidMessage
+		}
+
+		tag, err := strconv.Atoi(string(data[start : start+eq]))
+		if err != nil || tag <= 0 {
+			return nil, ErrInvalidMessage
+		}
+
+		if tag == TagCheckSum {
+			if end+1 != len(data) {
+				return nil, ErrInvalidMessage
+			}
+
+			sum, err := strconv.Atoi(string(data[start+eq+1 : end]))
+			if err != nil || sum != checksum(data[:start]) {
+				return nil, ErrChecksum
+			}
+
+			return body(fields, start)
+		}
+
+		fields = append(fields, Field{Tag: tag, Value: string(data[start+eq+1 : end])})
+		start = end + 1
+	}
+
+	return nil, ErrInvalidMessage
+}
+
+// body validates header fields and strips them, size is the length of the
+// message before CheckSum
+func body(fields []Field, size int) (Message, error) {
+	if len(fields) < 3 ||
+		fields[0].Tag != TagBeginString || fields[0].Value != BeginString ||
+		fields[1].Tag != TagBodyLength || fields[2].Tag != TagMsgType {
+		return nil, ErrInvalidMessage
+	}
+
+	length, err := strconv.Atoi(fields[1].Value)
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+
+	// BeginString and BodyLength fields with delimiters
+	header := len(strconv.Itoa(TagBeginString)) + len(BeginString) +
+		len(strconv.Itoa(TagBodyLength)) + len(fields[1].Value) + 4
+
+	if header+length != size {
+		return nil, ErrInvalidMessage
+	}
+
+	return Message(fields[2:]), nil
+}
+
+func checksum(b []byte) int {
+	var sum int
+	for _, c := range b {
+		sum += int(c)
+	}
+	return sum % 256
+}
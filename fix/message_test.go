@@ -0,0 +1,33 @@
+package fix
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	m := Message{
+		{TagMsgType, MsgNewOrderSingle},
+		{TagClOrdID, "1"},
+		{TagSymbol, "APL/USD"},
+	}
+
+	data := m.Marshal()
+	if !bytes.HasPrefix(data, []byte("8=FIX.4.4\x019=21\x0135=D\x01")) {
+		t.Fatal("invalid header", string(data))
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed) != 3 || parsed.Type() != MsgNewOrderSingle || parsed.Get(TagSymbol) != "APL/USD" {
+		t.Fatal("message must be decoded")
+	}
+
+	data[len(data)-3]++
+	if _, err := Parse(data); err != ErrChecksum {
+		t.Fatal("checksum must be validated")
+	}
+}
@@ -0,0 +1,68 @@
+package fastme
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Formatter renders values to strings. The engine uses it for serialization,
+// so equal values must produce equal strings and different values must
+// produce different strings
+type Formatter interface {
+	Format(Value) string
+}
+
+// FormatterFunc is an adapter to allow the use of ordinary functions as Formatter
+type FormatterFunc func(Value) string
+
+// Format calls f(v)
+func (f FormatterFunc) Format(v Value) string {
+	return f(v)
+}
+
+// DecimalFormatter renders values with fixed number of decimals. The value
+// is parsed from its Hash representation, so it works with any Value
+// implementation producing decimal or fractional ("1/3") hashes. Values with
+// non-numeric hashes and values with more significant decimals are rendered
+// as is, so different values never produce equal strings and journals and
+// snapshots keep exact values
+type DecimalFormatter struct {
+	// Decimals is the number of digits after the decimal point
+	Decimals int
+
+	// TrimZeros removes trailing zeros and the decimal point if nothing left after it
+	TrimZeros bool
+}
+
+// Format renders value as decimal string
+func (f DecimalFormatter) Format(v Value) string {
+	h := v.Hash()
+
+	r, ok := new(big.Rat).SetString(h)
+	if !ok {
+		return h
+	}
+
+	s := r.FloatString(f.Decimals)
+	if rounded, _ := new(big.Rat).SetString(s); rounded.Cmp(r) != 0 {
+		return h
+	}
+
+	if f.TrimZeros && strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	if strings.Trim(s, "-0.") == "" {
+		s = strings.TrimPrefix(s, "-")
+	}
+
+	return s
+}
+
+type hashFormatter struct{}
+
+func (hashFormatter) Format(v Value) string {
+	return v.Hash()
+}
+
+var hashFormatterValue = hashFormatter{}
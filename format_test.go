@@ -0,0 +1,77 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestDecimalFormatter(t *testing.T) {
+	for _, tc := range []struct {
+		f    DecimalFormatter
+		v    Value
+		want string
+	}{
+		{DecimalFormatter{Decimals: 2}, tFloat64(10), "10.00"},
+		{DecimalFormatter{Decimals: 2}, tFloat64(10.5), "10.50"},
+		{DecimalFormatter{Decimals: 4, TrimZeros: true}, tFloat64(10.5), "10.5"},
+		{DecimalFormatter{Decimals: 4, TrimZeros: true}, tFloat64(10), "10"},
+		{DecimalFormatter{Decimals: 2, TrimZeros: true}, tFloat64(-0.001), "-0.001"},
+		{DecimalFormatter{Decimals: 2}, tFloat64(-0.001), "-0.001"},
+		{DecimalFormatter{Decimals: 2}, tFloat64(10.005), "10.005"},
+		{DecimalFormatter{Decimals: 2}, tFloat64(0), "0.00"},
+		{DecimalFormatter{Decimals: 0}, tFloat64(-3), "-3"},
+	} {
+		if got := tc.f.Format(tc.v); got != tc.want {
+			t.Fatalf("%+v: got %q, want %q", tc.f, got, tc.want)
+		}
+	}
+}
+
+func TestSetFormatter(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		order1 = newOrder("1", wallet1, true, 2, 10)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order1))
+
+	engine.SetFormatter(DecimalFormatter{Decimals: 8})
+	if engine.asks.level(tFloat64(10)) == nil || engine.format(tFloat64(10)) != "10.00000000" {
+		t.Fatal("price levels must be kept")
+	}
+
+	_, err := engine.ReplaceOrder(context.Background(), nil, order1, newOrder("1", wallet1, true, 1, 10))
+	assertErr(t, err)
+	engine.CancelOrder(context.Background(), nil, order1)
+}
+
+func TestDecimalFormatterJournal(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+		engine         = NewEngine(asset1, asset2, WithFormatter(DecimalFormatter{Decimals: 2}))
+		buf            bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+
+	engine.SetJournal(NewJournal(&buf))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1.005, 10)))
+
+	wallet2 := newWallet()
+	updateWalletBalance(wallet2, asset1, 2)
+
+	replayed := NewEngine(asset1, asset2)
+	assertErr(t, replayed.Replay(context.Background(), &buf,
+		&tOrderFactory{owners: map[string]*tWallet{"1": wallet2}}, nil))
+
+	if o, err := replayed.FindOrder("1"); err != nil || o.Quantity().Cmp(tFloat64(1.005)) != 0 {
+		t.Fatal("journal must keep exact values", o, err)
+	}
+}
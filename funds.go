@@ -0,0 +1,175 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+)
+
+// UnderfundedListener is an optional EventListener extension informing about
+// makers canceled in the strict funds mode. OnExistingOrderCanceled is called
+// if it's not implemented
+type UnderfundedListener interface {
+	OnExistingOrderUnderfunded(context.Context, Order)
+}
+
+// SetStrictFunds enables the strict funds mode. Funds are checked on
+// placement only, so the wallet may be drained externally while the order
+// rests in the order book. In the strict mode the maker is checked before each
+// match: if the in-order amount of its owner doesn't cover the order, the
+// maker is canceled and matching continues with the next one. The cancel
+// refunds no more than the in-order amount left
+func (e *Engine) SetStrictFunds(strict bool) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.strict = strict
+}
+
+// reservation returns asset and amount reserved by the resting order
+func (e *Engine) reservation(o Order) (Asset, Value) {
+	if o.Sell() {
+		return e.base, o.Quantity()
+	}
+	return e.quote, o.Quantity().Mul(o.Price())
+}
+
+// underfunded returns true if the in-order amount of the owner doesn't cover
+// the resting order
+func (e *Engine) underfunded(ctx context.Context, o Order) bool {
+	if e.matchOnly {
+		return false
+	}
+
+	asset, value := e.reservation(o)
+	return inOrderOf(ctx, o.Owner(), asset).Cmp(value) < 0
+}
+
+// cancelUnderfunded removes the maker from the order book and refunds the
+// in-order amount left, but no more than reserved by the order
+func (e *Engine) cancelUnderfunded(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) {
+	e.pull(ctx, o)
+	e.archive(ctx, o, StatusCanceled)
+	e.activity.canceled++
+
+	var (
+		wallet       = o.Owner()
+		asset, value = e.reservation(o)
+		inOrder      = inOrderOf(ctx, wallet, asset)
+	)
+
+	if inOrder.Cmp(value) < 0 {
+		value = inOrder
+	}
+
+	if value.Sign() > 0 {
+		e.release(ctx, listener, wallet, asset, value)
+	}
+
+	e.log(ctx, LogWarn, "underfunded maker canceled",
+		LogField{"order_id", o.ID()},
+		LogField{"wallet", wallet},
+	)
+
+	if l, ok := listener.(UnderfundedListener); ok {
+		l.OnExistingOrderUnderfunded(ctx, o)
+	} else {
+		listener.OnExistingOrderCanceled(ctx, o)
+	}
+}
+
+// SetAccounting enables or disables wallet accounting, it's enabled by
+// default. With accounting disabled the engine matches quantities only:
+// orders may have nil owners, funds aren't checked by CanPlace and matching,
+// Wallet methods are never called and balance events aren't emitted. The
+// FeeHandler isn't called as there is no credited value to adjust. Use it if
+// balances are kept in the external ledger updated from fills
+func (e *Engine) SetAccounting(enabled bool) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.matchOnly = !enabled
+}
+
+// CanPlaceOrders validates orders of one or more wallets together as if they
+// were placed one after another without matching: every order is charged
+// the full reservation, limit buys at the limit price, and the rest of the
+// balance is available to the next orders of the wallet. Returns errors by
+// order index, nil for orders which fit. As CanPlace it doesn't change any
+// state and doesn't guarantee that PlaceOrder accepts the orders
+func (e *Engine) CanPlaceOrders(ctx context.Context, orders []Order) []error {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	type funds struct {
+		w Wallet
+		a Asset
+	}
+
+	var (
+		errs    = make([]error, len(orders))
+		charged = make(map[funds]Value)
+	)
+
+	for i, o := range orders {
+		market := isMarket(o)
+		if err := checkKind(o); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		if err := e.canPlace(ctx, nil, o.Sell(), market, o.Quantity(), o.Price()); err != nil &&
+			!errors.Is(err, ErrInsufficientFunds) {
+			errs[i] = err
+			continue
+		}
+
+		if e.matchOnly {
+			continue
+		}
+
+		asset, required := e.base, o.Quantity()
+		if !o.Sell() {
+			asset = e.quote
+			if market {
+				required, _ = e.price(false, o.Quantity())
+			} else {
+				required = o.Price().Mul(o.Quantity())
+			}
+		}
+
+		w := o.Owner()
+		if w == nil {
+			errs[i] = &InsufficientFundsError{Asset: asset, Required: required}
+			continue
+		}
+
+		balance, err := tryBalance(ctx, w, asset)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		key := funds{w, asset}
+		available := balance
+		if c, ok := charged[key]; ok {
+			available = balance.Sub(c)
+		}
+
+		if available.Cmp(required) < 0 {
+			errs[i] = &InsufficientFundsError{
+				Asset:     asset,
+				Required:  required,
+				Available: available,
+			}
+			continue
+		}
+
+		charged[key] = required.Add(charged[key])
+	}
+
+	return errs
+}
@@ -0,0 +1,184 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tUnderfundedListener struct {
+	*tEventListener
+	underfunded []Order
+}
+
+func (t *tUnderfundedListener) OnExistingOrderUnderfunded(ctx context.Context, o Order) {
+	t.underfunded = append(t.underfunded, o)
+}
+
+func TestStrictFunds(t *testing.T) {
+	var (
+		asset1, asset2            = Asset("apples"), Asset("dollars")
+		wallet1, wallet2, wallet3 = newWallet(), newWallet(), newWallet()
+		listener                  = &tUnderfundedListener{tEventListener: newEventListener()}
+
+		engine = NewEngine(asset1, asset2, WithStrictFunds())
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+	updateWalletBalance(wallet3, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet3, true, 1, 11)))
+
+	// Reserved assets are drained externally
+	wallet1.UpdateInOrder(context.Background(), asset1, tFloat64(1))
+
+	r, err := engine.PlaceOrderReport(context.Background(), listener, newOrder("3", wallet2, false, 1, 11))
+	assertErr(t, err)
+
+	if len(r.Fills) != 1 || r.Fills[0].MakerID != "2" {
+		t.Fatal("underfunded maker must be skipped", r.Fills)
+	}
+
+	if len(listener.underfunded) != 1 || listener.underfunded[0].ID() != "1" {
+		t.Fatal("underfunded maker must be reported", listener.underfunded)
+	}
+
+	if _, err := engine.FindOrder("1"); err != ErrOrderNotFound {
+		t.Fatal("underfunded maker must be canceled", err)
+	}
+
+	if walletBalance(wallet1, asset1) != 9 || walletInOrder(wallet1, asset1) != 0 {
+		t.Fatal("in-order amount left must be refunded",
+			walletBalance(wallet1, asset1), walletInOrder(wallet1, asset1))
+	}
+}
+
+func TestBuyFundsCheckedPerLevel(t *testing.T) {
+	var (
+		asset1, asset2            = Asset("apples"), Asset("dollars")
+		wallet1, wallet2, wallet3 = newWallet(), newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2, WithStrictFunds())
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 15)
+	updateWalletBalance(wallet3, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet3, true, 1, 20)))
+
+	// Crossing quantity is charged at the ask price, not at the limit price
+	assertErr(t, engine.CanPlace(context.Background(), wallet2, false, tFloat64(1), tFloat64(20)))
+
+	if err := engine.CanPlace(context.Background(), wallet2, false, tFloat64(2), tFloat64(20)); err == nil {
+		t.Fatal("remainder must be charged at the limit price")
+	}
+
+	// The cheapest maker is canceled at match time, the buyer can't afford
+	// the next level estimated on validation
+	wallet1.UpdateInOrder(context.Background(), asset1, tFloat64(0))
+
+	r, err := engine.PlaceOrderReport(context.Background(), nil, newOrder("3", wallet2, false, 1, 0))
+	assertErr(t, err)
+
+	if len(r.Fills) != 0 || r.Status != StatusCanceled {
+		t.Fatal("unaffordable fill must not be executed", r)
+	}
+
+	if walletBalance(wallet2, asset2) != 15 {
+		t.Fatal("buyer must not be overcharged", walletBalance(wallet2, asset2))
+	}
+
+	if _, err := engine.FindOrder("2"); err != nil {
+		t.Fatal("maker must be kept", err)
+	}
+}
+
+type tUnownedOrder struct {
+	*tOrder
+}
+
+func (t *tUnownedOrder) Owner() Wallet {
+	return nil
+}
+
+func newUnownedOrder(id string, sell bool, qty, price float64) *tUnownedOrder {
+	return &tUnownedOrder{newOrder(id, nil, sell, qty, price)}
+}
+
+func TestWithoutAccounting(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		engine         = NewEngine(asset1, asset2, WithoutAccounting(), WithStrictFunds())
+	)
+
+	assertErr(t, engine.CanPlace(context.Background(), nil, false, tFloat64(1), tFloat64(10)))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newUnownedOrder("1", true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newUnownedOrder("2", true, 1, 11)))
+	assertErr(t, engine.AmendOrder(context.Background(), nil,
+		newUnownedOrder("2", true, 1, 11), newUnownedOrder("2", true, 3, 11)))
+
+	_, err := engine.ReplaceOrder(context.Background(), nil,
+		newUnownedOrder("1", true, 2, 10), newUnownedOrder("3", true, 1, 10))
+	assertErr(t, err)
+
+	r, err := engine.PlaceOrderReport(context.Background(), nil, newUnownedOrder("4", false, 3, 11))
+	assertErr(t, err)
+
+	if len(r.Fills) != 2 || r.Fills[0].MakerID != "3" || r.Fills[1].MakerID != "2" {
+		t.Fatal("orders must be matched without wallets", r.Fills)
+	}
+
+	if o, err := engine.FindOrder("2"); err != nil || o.Quantity().Cmp(tFloat64(1)) != 0 {
+		t.Fatal("maker remainder must rest", o, err)
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "2"))
+
+	if err := engine.CanPlace(context.Background(), nil, false, tFloat64(0), tFloat64(10)); err == nil {
+		t.Fatal("order parameters must be checked")
+	}
+}
+
+func TestCanPlaceOrders(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	errs := engine.CanPlaceOrders(context.Background(), []Order{
+		newOrder("1", wallet1, true, 6, 10),
+		newOrder("2", wallet2, false, 5, 10),
+		newOrder("3", wallet1, true, 6, 11),
+		newOrder("4", wallet2, false, 5, 10),
+		newOrder("5", wallet1, true, 4, 11),
+		newOrder("6", wallet1, true, 0, 11),
+	})
+
+	if errs[0] != nil || errs[1] != nil || errs[3] != nil || errs[4] != nil {
+		t.Fatal("orders fitting the balance must pass", errs)
+	}
+
+	if !errors.Is(errs[2], ErrInsufficientFunds) || !errors.Is(errs[5], ErrInvalidQuantity) {
+		t.Fatal("orders must be validated together", errs)
+	}
+
+	// Reserved funds are excluded from the balance checked on placement
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 6, 10)))
+	if err := engine.CanPlace(context.Background(), wallet1, true, tFloat64(6), tFloat64(11)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("funds of resting orders must be accounted", err)
+	}
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 6, 11)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("funds of resting orders must be accounted", err)
+	}
+}
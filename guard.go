@@ -0,0 +1,75 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrEngineCorrupted is wrapped by PanicError
+var ErrEngineCorrupted = errors.New("Engine state is corrupted")
+
+// PanicError is returned by the command interrupted by the panic of
+// EventListener, Wallet or other callback. The order book and balances may be
+// half-updated, so the engine is marked corrupted and rejects mutating
+// commands with the error until it's restored from the snapshot. It wraps
+// ErrEngineCorrupted
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: panic: %v", ErrEngineCorrupted, e.Value)
+}
+
+// Unwrap returns ErrEngineCorrupted
+func (e *PanicError) Unwrap() error {
+	return ErrEngineCorrupted
+}
+
+// Corrupted returns PanicError or WalletError of the command which corrupted
+// the engine state, nil if the state is consistent
+func (e *Engine) Corrupted() error {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return e.corrupted
+}
+
+// guard recovers the panic of the command and marks the engine corrupted.
+// It must be deferred after the lock, err is set to PanicError or WalletError
+// if not nil
+func (e *Engine) guard(ctx context.Context, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if f, ok := r.(walletFailure); ok {
+		f.err.Corrupted = true
+		e.corrupted = f.err
+
+		e.log(ctx, LogError, "engine corrupted",
+			LogField{"wallet", f.err.Wallet},
+			LogField{"error", f.err.Err},
+		)
+
+		if err != nil {
+			*err = f.err
+		}
+		return
+	}
+
+	pe := &PanicError{Value: r, Stack: debug.Stack()}
+	e.corrupted = pe
+
+	e.log(ctx, LogError, "engine corrupted",
+		LogField{"panic", r},
+	)
+
+	if err != nil {
+		*err = pe
+	}
+}
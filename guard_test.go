@@ -0,0 +1,63 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPanicGuard(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine  = NewEngine(asset1, asset2)
+		factory = &tOrderFactory{owners: map[string]*tWallet{"1": wallet1, "2": wallet1}}
+
+		buf bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 1, 11)))
+	assertErr(t, engine.Snapshot(context.Background(), &buf))
+
+	if engine.Corrupted() != nil {
+		t.Fatal("engine must not be corrupted")
+	}
+
+	listener := &tPanicListener{newEventListener()}
+	err := engine.PlaceOrder(context.Background(), listener, newOrder("3", wallet2, false, 1, 10))
+
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "listener failure" || !errors.Is(err, ErrEngineCorrupted) {
+		t.Fatal("panic must be returned as PanicError", err)
+	}
+
+	if engine.Corrupted() != err {
+		t.Fatal("engine must be marked corrupted")
+	}
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 11)); err != pe {
+		t.Fatal("orders must be rejected by corrupted engine", err)
+	}
+
+	if err := engine.CancelOrderByID(context.Background(), nil, "2"); err != pe {
+		t.Fatal("cancellation must be rejected by corrupted engine", err)
+	}
+
+	if _, err := engine.FindOrder("2"); err != nil {
+		t.Fatal("read-only queries must be served", err)
+	}
+
+	assertErr(t, engine.Restore(context.Background(), bytes.NewReader(buf.Bytes()), factory))
+
+	if engine.Corrupted() != nil {
+		t.Fatal("restore must clear the corrupted state")
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "2"))
+}
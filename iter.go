@@ -0,0 +1,51 @@
+//go:build go1.23
+// +build go1.23
+
+package fastme
+
+import "iter"
+
+// Levels returns the iterator over price levels of the side from the best
+// price. The engine is read-locked while ranging, so the loop body must not
+// call the engine. Breaking the loop releases the lock
+func (e *Engine) Levels(side Side) iter.Seq[Level] {
+	f := BookFilter{SkipBids: true}
+	if side == SideBuy {
+		f = BookFilter{SkipAsks: true}
+	}
+
+	return func(yield func(Level) bool) {
+		e.m.RLock()
+		defer e.m.RUnlock()
+
+		e.walkLevels(f, func(_ bool, q *queue) bool {
+			return yield(Level{
+				Price:  q.price,
+				Volume: q.volume,
+				Orders: q.orders.Len(),
+			})
+		})
+	}
+}
+
+// RestingOrders returns the iterator over read-only views of resting orders,
+// see OrderView. Orders are iterated in the order of Orders: asks first,
+// each side by ascending price, orders of the level by time. The engine is
+// read-locked while ranging, so the loop body must not call the engine.
+// Breaking the loop releases the lock
+func (e *Engine) RestingOrders() iter.Seq[Order] {
+	return func(yield func(Order) bool) {
+		e.m.RLock()
+		defer e.m.RUnlock()
+
+		for _, s := range []*side{e.asks, e.bids} {
+			for q := s.minPrice(); q != nil; q = s.greaterThan(q.price) {
+				for el := q.orders.Front(); el != nil; el = el.Next() {
+					if !yield(ViewOf(el.Value.(Order))) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//go:build go1.23
+// +build go1.23
+
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 1, 10)))
+
+	var prices []float64
+	for l := range engine.Levels(SideSell) {
+		prices = append(prices, float64(l.Price.(tFloat64)))
+		if l.Price.Cmp(tFloat64(11)) == 0 && (l.Orders != 2 || l.Volume.Cmp(tFloat64(5)) != 0) {
+			t.Fatal("invalid level", l)
+		}
+	}
+	for l := range engine.Levels(SideBuy) {
+		prices = append(prices, float64(l.Price.(tFloat64)))
+	}
+
+	if len(prices) != 4 || prices[0] != 11 || prices[1] != 12 || prices[2] != 10 || prices[3] != 9 {
+		t.Fatal("levels must be iterated from the best price", prices)
+	}
+
+	var ids []string
+	for o := range engine.RestingOrders() {
+		if _, ok := o.(*OrderView); !ok {
+			t.Fatalf("resting orders must be read-only views, got %T", o)
+		}
+
+		ids = append(ids, o.ID())
+		if len(ids) == 4 {
+			break
+		}
+	}
+
+	if len(ids) != 4 || ids[0] != "2" || ids[1] != "3" || ids[2] != "1" || ids[3] != "4" {
+		t.Fatal("orders must be iterated in the order of Orders until break", ids)
+	}
+
+	for i, o := range engine.Orders()[:4] {
+		if o.ID() != ids[i] {
+			t.Fatal("orders must be iterated in the order of Orders", ids)
+		}
+	}
+
+	// The lock is released after break
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+}
@@ -0,0 +1,326 @@
+package fastme
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrInvalidJournal is returned by Replay on unknown journal records
+var ErrInvalidJournal = errors.New("Invalid journal record")
+
+// Journal operations
+const (
+	journalPlace   = "place"
+	journalReplace = "replace"
+	journalAmend   = "amend"
+	journalCancel  = "cancel"
+	journalState   = "state"
+	journalUncross = "uncross"
+	journalReduce  = "reduce"
+	journalClear   = "clear"
+
+	// journalInterrupt follows the place or replace record if matching was
+	// interrupted by the context, Fills is the number of executed fills
+	journalInterrupt = "interrupt"
+)
+
+// Journal is the write-ahead command log. Every accepted mutating command is
+// encoded as a JSON line after validation and before it changes the order
+// book, so the order book could be rebuilt by Replay on top of the snapshot
+// taken before the first record. The command is rejected if the journal
+// write fails
+type Journal struct {
+	w   io.Writer
+	seq uint64
+}
+
+type journalRecord struct {
+	Seq   uint64        `json:"seq"`
+	Op    string        `json:"op"`
+	Time  time.Time     `json:"time"`
+	ID    string        `json:"id,omitempty"`
+	Order *journalOrder `json:"order,omitempty"`
+	State *TradingState `json:"state,omitempty"`
+	Fills int           `json:"fills,omitempty"`
+
+	// Quantity is the new quantity of the reduced order
+	Quantity string `json:"quantity,omitempty"`
+
+	// Refund is set if cleared orders were refunded
+	Refund bool `json:"refund,omitempty"`
+}
+
+type journalOrder struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id,omitempty"`
+	Sell     bool   `json:"sell"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// NewJournal creates journal appending records to w
+func NewJournal(w io.Writer) *Journal {
+	return &Journal{w: w}
+}
+
+// SetJournal enables command journaling, nil disables it
+func (e *Engine) SetJournal(j *Journal) {
+	e.m.Lock()
+	e.journal = j
+	e.m.Unlock()
+}
+
+// Replay applies journaled commands to the engine. Orders are created by
+// the factory, events are reported to the listener. Each command is applied
+// at its journaled time, so the replay is deterministic given the same
+// initial order book and wallet balances. Command errors (e.g. tripped circuit
+// breaker) are reproduced and ignored, decoding and factory errors stop the
+// replay. Replay must not run concurrently with other commands
+func (e *Engine) Replay(
+	ctx context.Context,
+	r io.Reader,
+	factory OrderFactory,
+	listener EventListener,
+) error {
+	e.m.Lock()
+	journal := e.journal
+	e.journal = nil
+	e.m.Unlock()
+
+	defer e.SetJournal(journal)
+
+	var (
+		dec  = json.NewDecoder(r)
+		next *journalRecord
+	)
+
+	for {
+		rec := next
+		next = nil
+
+		if rec == nil {
+			rec = new(journalRecord)
+			if err := dec.Decode(rec); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+
+		// Matching is not interrupted by the replay context, interrupted
+		// commands are stopped after the journaled number of fills
+		var (
+			rctx  = WithEventTime(ctx, rec.Time)
+			limit = -1
+		)
+
+		if rec.Op == journalPlace || rec.Op == journalReplace {
+			n := new(journalRecord)
+			if err := dec.Decode(n); err != nil && err != io.EOF {
+				return err
+			} else if err == nil {
+				if n.Op == journalInterrupt && rec.Order != nil && n.ID == rec.Order.ID {
+					limit = n.Fills
+				} else {
+					next = n
+				}
+			}
+		}
+
+		rctx = context.WithValue(rctx, fillLimitKey{}, limit)
+		if err := e.replay(rctx, *rec, factory, listener); err != nil {
+			return err
+		}
+	}
+}
+
+type fillLimitKey struct{}
+
+// interrupted returns true if matching must stop after the given number of
+// fills: the context is done or the replayed fill limit is reached
+func interrupted(ctx context.Context, fills int) bool {
+	if limit, ok := ctx.Value(fillLimitKey{}).(int); ok {
+		return limit >= 0 && fills >= limit
+	}
+	return ctx.Err() != nil
+}
+
+// replaying returns true for the context of the replayed command
+func replaying(ctx context.Context) bool {
+	_, ok := ctx.Value(fillLimitKey{}).(int)
+	return ok
+}
+
+func (e *Engine) replay(
+	ctx context.Context,
+	rec journalRecord,
+	factory OrderFactory,
+	listener EventListener,
+) error {
+	var (
+		o   Order
+		err error
+	)
+
+	if rec.Order != nil {
+		if o, err = rec.Order.order(ctx, factory); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case rec.Op == journalPlace && o != nil:
+		_ = e.PlaceOrder(ctx, listener, o)
+
+	case rec.Op == journalReplace && o != nil:
+		if resting, err := e.FindOrder(rec.ID); err == nil {
+			_, _ = e.ReplaceOrder(ctx, listener, resting, o)
+		}
+
+	case rec.Op == journalAmend && o != nil:
+		if resting, err := e.FindOrder(rec.ID); err == nil {
+			_ = e.AmendOrder(ctx, listener, resting, o)
+		}
+
+	case rec.Op == journalCancel:
+		_ = e.CancelOrderByID(ctx, listener, rec.ID)
+
+	case rec.Op == journalState && rec.State != nil:
+		_ = e.SetTradingState(ctx, listener, *rec.State)
+
+	case rec.Op == journalUncross:
+		_, _, _ = e.Uncross(ctx, listener)
+
+	case rec.Op == journalReduce:
+		quantity, err := factory.Value(rec.Quantity)
+		if err != nil {
+			return err
+		}
+		_ = e.ReduceOrder(ctx, listener, rec.ID, quantity)
+
+	case rec.Op == journalClear:
+		_, _ = e.Clear(ctx, listener, rec.Refund)
+
+	case rec.Op == journalInterrupt:
+		// Applied with the preceding record
+
+	default:
+		return ErrInvalidJournal
+	}
+
+	return nil
+}
+
+func (o *journalOrder) order(ctx context.Context, factory OrderFactory) (Order, error) {
+	price, err := factory.Value(o.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := factory.Value(o.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Order(ctx, OrderRecord{
+		ID:            o.ID,
+		Sell:          o.Sell,
+		Price:         price,
+		Quantity:      quantity,
+		ClientOrderID: o.ClientID,
+	})
+}
+
+// record writes the command to the journal if enabled
+func (e *Engine) record(ctx context.Context, op, id string, o Order, state *TradingState) error {
+	return e.recordFills(ctx, op, id, o, state, 0)
+}
+
+// recordInterrupt writes number of fills executed by the interrupted order
+func (e *Engine) recordInterrupt(ctx context.Context, o Order, fills int) {
+	if err := e.recordFills(ctx, journalInterrupt, o.ID(), nil, nil, fills); err != nil {
+		e.log(ctx, LogError, "journal write failed",
+			LogField{"order_id", o.ID()},
+			LogField{"error", err},
+		)
+	}
+}
+
+func (e *Engine) recordFills(
+	ctx context.Context,
+	op, id string,
+	o Order,
+	state *TradingState,
+	fills int,
+) error {
+	if e.journal == nil {
+		return nil
+	}
+
+	rec := journalRecord{
+		Seq:   e.journal.seq + 1,
+		Op:    op,
+		Time:  e.eventTime(ctx),
+		ID:    id,
+		State: state,
+		Fills: fills,
+	}
+
+	if o != nil {
+		rec.Order = &journalOrder{
+			ID:       o.ID(),
+			ClientID: OrderClientID(o),
+			Sell:     o.Sell(),
+			Price:    e.format(o.Price()),
+			Quantity: e.format(o.Quantity()),
+		}
+	}
+
+	return e.write(rec)
+}
+
+// recordReduce writes the new quantity of the reduced order
+func (e *Engine) recordReduce(ctx context.Context, id string, quantity Value) error {
+	if e.journal == nil {
+		return nil
+	}
+
+	return e.write(journalRecord{
+		Seq:      e.journal.seq + 1,
+		Op:       journalReduce,
+		Time:     e.eventTime(ctx),
+		ID:       id,
+		Quantity: e.format(quantity),
+	})
+}
+
+// recordClear writes the order book clearance
+func (e *Engine) recordClear(ctx context.Context, refund bool) error {
+	if e.journal == nil {
+		return nil
+	}
+
+	return e.write(journalRecord{
+		Seq:    e.journal.seq + 1,
+		Op:     journalClear,
+		Time:   e.eventTime(ctx),
+		Refund: refund,
+	})
+}
+
+func (e *Engine) write(rec journalRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.journal.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	e.journal.seq++
+	return nil
+}
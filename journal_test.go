@@ -0,0 +1,152 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestJournalReplay(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		buf            bytes.Buffer
+
+		run = func(play func(e *Engine, w1, w2 *tWallet)) (*Engine, *tWallet, *tWallet) {
+			w1, w2 := newWallet(), newWallet()
+			updateWalletBalance(w1, asset1, 10)
+			updateWalletBalance(w2, asset2, 100)
+
+			e := NewEngine(asset1, asset2)
+			play(e, w1, w2)
+			return e, w1, w2
+		}
+	)
+
+	engine1, wallet1, wallet2 := run(func(e *Engine, w1, w2 *tWallet) {
+		e.SetJournal(NewJournal(&buf))
+
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("1", w1, true, 2, 10)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("2", w1, true, 3, 11)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("3", w1, true, 1, 12)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("4", w2, false, 1, 10)))
+		assertErr(t, e.AmendOrder(context.Background(), nil, newOrder("2", w1, true, 3, 11), newOrder("2", w1, true, 2, 11)))
+		_, err := e.ReplaceOrder(context.Background(), nil, newOrder("1", w1, true, 1, 10), newOrder("5", w1, true, 1, 9))
+		assertErr(t, err)
+		assertErr(t, e.CancelOrderByID(context.Background(), nil, "3"))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("6", w2, false, 1, 5)))
+
+		if err := e.PlaceOrder(context.Background(), nil, newOrder("6", w2, false, 1, 5)); err != ErrOrderExists {
+			t.Fatal("duplicate order must be rejected")
+		}
+	})
+
+	engine2, wallet3, wallet4 := run(func(e *Engine, w1, w2 *tWallet) {
+		factory := &tOrderFactory{owners: map[string]*tWallet{
+			"1": w1, "2": w1, "3": w1, "5": w1, "4": w2, "6": w2,
+		}}
+
+		assertErr(t, e.Replay(context.Background(), bytes.NewReader(buf.Bytes()), factory, nil))
+	})
+
+	var expected, actual string
+	for _, o := range engine1.Orders() {
+		expected += o.ID() + ":" + o.Quantity().Hash() + ";"
+	}
+	for _, o := range engine2.Orders() {
+		actual += o.ID() + ":" + o.Quantity().Hash() + ";"
+	}
+
+	if expected != actual || expected != "5:1;2:2;6:1;" {
+		t.Fatal("invalid replayed orders", expected, actual)
+	}
+
+	for _, a := range []Asset{asset1, asset2} {
+		if walletBalance(wallet1, a) != walletBalance(wallet3, a) ||
+			walletBalance(wallet2, a) != walletBalance(wallet4, a) ||
+			walletInOrder(wallet1, a) != walletInOrder(wallet3, a) ||
+			walletInOrder(wallet2, a) != walletInOrder(wallet4, a) {
+			t.Fatal("invalid replayed balances")
+		}
+	}
+
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 8 {
+		t.Fatal("only accepted commands must be journaled")
+	}
+}
+
+// tCancelingListener cancels the context after the first maker fill
+type tCancelingListener struct {
+	*tEventListener
+	cancel context.CancelFunc
+}
+
+func (t *tCancelingListener) OnExistingOrderDone(ctx context.Context, o Order, v Volume) {
+	t.cancel()
+}
+
+func TestJournalReplayInterrupted(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		buf            bytes.Buffer
+
+		run = func(play func(e *Engine, w1, w2 *tWallet)) (*Engine, *tWallet) {
+			w1, w2 := newWallet(), newWallet()
+			updateWalletBalance(w1, asset1, 10)
+			updateWalletBalance(w2, asset2, 100)
+
+			e := NewEngine(asset1, asset2)
+			play(e, w1, w2)
+			return e, w2
+		}
+	)
+
+	engine1, wallet2 := run(func(e *Engine, w1, w2 *tWallet) {
+		e.SetJournal(NewJournal(&buf))
+
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("1", w1, true, 1, 10)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("2", w1, true, 1, 11)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("3", w1, true, 1, 12)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		listener := &tCancelingListener{tEventListener: newEventListener(), cancel: cancel}
+
+		order := newOrder("4", w2, false, 3, 12)
+		r, err := e.PlaceOrderReport(ctx, listener, order)
+		if err != context.Canceled {
+			t.Fatal("matching must be interrupted", err)
+		}
+
+		if len(r.Fills) != 1 || r.Status != StatusCanceled || r.Remaining != tFloat64(2) {
+			t.Fatal("executed fills must be kept and the remainder canceled", r)
+		}
+
+		if err := e.PlaceOrder(ctx, nil, newOrder("5", w2, false, 1, 12)); err != context.Canceled {
+			t.Fatal("order must be rejected if the context is done", err)
+		}
+	})
+
+	engine2, wallet4 := run(func(e *Engine, w1, w2 *tWallet) {
+		factory := &tOrderFactory{owners: map[string]*tWallet{
+			"1": w1, "2": w1, "3": w1, "4": w2,
+		}}
+
+		assertErr(t, e.Replay(context.Background(), bytes.NewReader(buf.Bytes()), factory, nil))
+	})
+
+	var expected, actual string
+	for _, o := range engine1.Orders() {
+		expected += o.ID() + ";"
+	}
+	for _, o := range engine2.Orders() {
+		actual += o.ID() + ";"
+	}
+
+	if expected != actual || expected != "2;3;" {
+		t.Fatal("interrupted matching must be replayed", expected, actual)
+	}
+
+	if walletBalance(wallet2, asset1) != walletBalance(wallet4, asset1) ||
+		walletBalance(wallet2, asset2) != walletBalance(wallet4, asset2) {
+		t.Fatal("invalid replayed balances")
+	}
+}
@@ -0,0 +1,67 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrWalletBlocked is returned for orders of the wallet blocked by the kill switch
+var ErrWalletBlocked = errors.New("Wallet is blocked")
+
+// KillSwitch blocks the wallet and cancels all its resting orders at once,
+// e.g. when the client session is disconnected. Placements, replacements and
+// amendments of the blocked wallet are rejected with ErrWalletBlocked until
+// Unblock is called, cancellations are accepted. Returns canceled orders.
+// Blocked wallets are neither journaled nor included in snapshots
+func (e *Engine) KillSwitch(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+) []Order {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, nil)
+
+	ctx = e.stamp(ctx)
+
+	if e.blocked == nil {
+		e.blocked = make(map[Wallet]struct{})
+	}
+	e.blocked[w] = struct{}{}
+
+	e.log(ctx, LogWarn, "kill switch triggered",
+		LogField{"wallet", w},
+	)
+
+	if e.unusable() != nil {
+		return nil
+	}
+
+	return e.cancelAllJournaled(ctx, listener, w, e.asks, e.bids)
+}
+
+// Unblock accepts orders of the wallet blocked by KillSwitch again
+func (e *Engine) Unblock(w Wallet) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	delete(e.blocked, w)
+}
+
+// Blocked returns true if the wallet is blocked by KillSwitch
+func (e *Engine) Blocked(w Wallet) bool {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	_, ok := e.blocked[w]
+	return ok
+}
+
+// checkBlocked returns ErrWalletBlocked for orders of the blocked wallet.
+// Journaled commands are replayed without the check, as they were accepted
+func (e *Engine) checkBlocked(ctx context.Context, w Wallet) error {
+	if _, ok := e.blocked[w]; ok && !replaying(ctx) {
+		return ErrWalletBlocked
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKillSwitch(t *testing.T) {
+	var (
+		wallet1, wallet2 = newWallet(), newWallet()
+		exchange         = NewExchange(nil)
+	)
+
+	updateWalletBalance(wallet1, "apples", 10)
+	updateWalletBalance(wallet1, "pears", 10)
+	updateWalletBalance(wallet2, "apples", 10)
+
+	engine, err := exchange.Register(Symbol{Base: "apples", Quote: "dollars"})
+	assertErr(t, err)
+
+	_, err = exchange.Register(Symbol{Base: "pears", Quote: "dollars"})
+	assertErr(t, err)
+
+	assertErr(t, exchange.PlaceOrder(context.Background(), "apples/dollars", newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, exchange.PlaceOrder(context.Background(), "apples/dollars", newOrder("2", wallet1, true, 1, 11)))
+	assertErr(t, exchange.PlaceOrder(context.Background(), "pears/dollars", newOrder("3", wallet1, true, 1, 10)))
+	assertErr(t, exchange.PlaceOrder(context.Background(), "apples/dollars", newOrder("4", wallet2, true, 1, 10)))
+
+	canceled := exchange.KillSwitch(context.Background(), wallet1)
+	if len(canceled) != 2 || len(canceled["apples/dollars"]) != 2 || len(canceled["pears/dollars"]) != 1 {
+		t.Fatal("resting orders of the wallet must be canceled", canceled)
+	}
+
+	if walletBalance(wallet1, "apples") != 10 || walletInOrder(wallet1, "apples") != 0 {
+		t.Fatal("canceled orders must be refunded")
+	}
+
+	if !engine.Blocked(wallet1) || engine.Blocked(wallet2) {
+		t.Fatal("only the wallet must be blocked")
+	}
+
+	if err := exchange.PlaceOrder(context.Background(), "apples/dollars", newOrder("5", wallet1, true, 1, 10)); err != ErrWalletBlocked {
+		t.Fatal("orders of the blocked wallet must be rejected", err)
+	}
+
+	if _, err := engine.FindOrder("4"); err != nil {
+		t.Fatal("orders of other wallets must rest", err)
+	}
+
+	exchange.Unblock(wallet1)
+	assertErr(t, exchange.PlaceOrder(context.Background(), "apples/dollars", newOrder("5", wallet1, true, 1, 10)))
+}
@@ -0,0 +1,83 @@
+package fastme
+
+// Side is the order book side of the order
+type Side int
+
+// Order sides
+const (
+	SideBuy Side = iota
+	SideSell
+)
+
+func (s Side) String() string {
+	if s == SideSell {
+		return "sell"
+	}
+	return "buy"
+}
+
+// OrderKind is the execution kind of the order
+type OrderKind int
+
+// Order kinds
+const (
+	// OrderKindLimit is executed at the order price or better, the remainder
+	// rests in the order book. Zero price is a valid limit price
+	OrderKindLimit OrderKind = iota
+
+	// OrderKindMarket is executed at the best available prices, the remainder
+	// is canceled. The order price is ignored
+	OrderKindMarket
+)
+
+func (k OrderKind) String() string {
+	if k == OrderKindMarket {
+		return "market"
+	}
+	return "limit"
+}
+
+// TypedOrder is an optional Order extension stating side and kind of the
+// order explicitly. Orders not implementing it are sell orders if Sell returns
+// true and market orders if the price is zero. Side must agree with Sell,
+// otherwise the order is rejected with ErrInvalidOrder
+type TypedOrder interface {
+	Side() Side
+	Kind() OrderKind
+}
+
+// isMarket returns true if the order is the market order
+func isMarket(o Order) bool {
+	if t, ok := o.(TypedOrder); ok {
+		return t.Kind() == OrderKindMarket
+	}
+
+	price := o.Price()
+	return price != nil && price.Sign() == 0
+}
+
+// checkKind validates side and kind of the typed order
+func checkKind(o Order) error {
+	t, ok := o.(TypedOrder)
+	if !ok {
+		return nil
+	}
+
+	if (t.Side() == SideSell) != o.Sell() {
+		return ErrInvalidOrder
+	}
+
+	switch t.Side() {
+	case SideBuy, SideSell:
+	default:
+		return ErrInvalidOrder
+	}
+
+	switch t.Kind() {
+	case OrderKindLimit, OrderKindMarket:
+	default:
+		return ErrInvalidOrder
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tTypedOrder struct {
+	*tOrder
+	side Side
+	kind OrderKind
+}
+
+func (t *tTypedOrder) Side() Side {
+	return t.side
+}
+
+func (t *tTypedOrder) Kind() OrderKind {
+	return t.kind
+}
+
+func newTypedOrder(id string, owner *tWallet, side Side, kind OrderKind, qty, price float64) *tTypedOrder {
+	return &tTypedOrder{
+		tOrder: newOrder(id, owner, side == SideSell, qty, price),
+		side:   side,
+		kind:   kind,
+	}
+}
+
+func TestTypedOrders(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	// Zero price limit order rests in the order book
+	free := newTypedOrder("1", wallet1, SideSell, OrderKindLimit, 2, 0)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, free))
+
+	if _, err := engine.FindOrder("1"); err != nil {
+		t.Fatal("zero price limit order must rest in the order book", err)
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 10)))
+
+	// Market order price is ignored
+	market := newTypedOrder("3", wallet2, SideBuy, OrderKindMarket, 4, 5)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, market))
+
+	if market.Quantity() != tFloat64(0) || wallet2.balance[asset1] != tFloat64(4) {
+		t.Fatal("market order must be executed", market.Quantity(), wallet2.balance)
+	}
+
+	if _, err := engine.FindOrder("3"); err != ErrOrderNotFound {
+		t.Fatal("market order must not rest in the order book", err)
+	}
+
+	// Side must agree with Sell
+	invalid := newTypedOrder("4", wallet1, SideSell, OrderKindLimit, 1, 10)
+	invalid.sell = false
+
+	if err := engine.PlaceOrder(context.Background(), nil, invalid); err != ErrInvalidOrder {
+		t.Fatal("order with inconsistent side must be rejected", err)
+	}
+
+	// Market order could not replace resting one
+	o, err := engine.FindOrder("2")
+	assertErr(t, err)
+
+	n := newTypedOrder("5", wallet1, SideSell, OrderKindMarket, 1, 10)
+	if err := engine.AmendOrder(context.Background(), nil, o, n); !errors.Is(err, ErrInvalidPrice) {
+		t.Fatal("market order must not rest in the order book", err)
+	}
+}
@@ -0,0 +1,190 @@
+package fastme
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// L2Book is the order book aggregated by price levels in the common L2
+// format: {"asks": [[price, quantity], ...], "bids": [...]}. Levels are sorted
+// from the best price, values are rendered by the engine Formatter. Use
+// encoding/json to import L2Book produced by Engine.ExportL2
+type L2Book struct {
+	Asks [][2]string `json:"asks"`
+	Bids [][2]string `json:"bids"`
+}
+
+// Level is the order book price level
+type Level struct {
+	Price  Value
+	Volume Value
+
+	// Orders is the number of orders resting at the price
+	Orders int
+}
+
+// Depth returns up to n best price levels of each side, all levels are
+// returned if n <= 0. Levels are sorted from the best price
+func (e *Engine) Depth(n int) (asks, bids []Level) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return depthLevels(e.asks.minPrice, e.asks.greaterThan, n),
+		depthLevels(e.bids.maxPrice, e.bids.lessThan, n)
+}
+
+func depthLevels(best func() *queue, next func(Value) *queue, n int) (levels []Level) {
+	for q := best(); q != nil && (n <= 0 || len(levels) < n); q = next(q.price) {
+		levels = append(levels, Level{
+			Price:  q.price,
+			Volume: q.volume,
+			Orders: q.orders.Len(),
+		})
+	}
+	return
+}
+
+// QuantityAt returns quantity resting on the given side at exactly the
+// price, nil if there is no such price level
+func (e *Engine) QuantityAt(asks bool, price Value) Value {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	s := e.bids
+	if asks {
+		s = e.asks
+	}
+
+	if q := s.level(price); q != nil {
+		return q.volume
+	}
+	return nil
+}
+
+// CumulativeDepthTo returns volume resting on the given side from the best
+// price up to the limit price inclusive. Volume.Price is the cost of the
+// levels, so sweeping them executes at Price/Quantity on average
+func (e *Engine) CumulativeDepthTo(asks bool, priceLim Value) (v Volume) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	best, next, worse := e.bids.maxPrice, e.bids.lessThan, -1
+	if asks {
+		best, next, worse = e.asks.minPrice, e.asks.greaterThan, 1
+	}
+
+	for q := best(); q != nil && q.price.Cmp(priceLim) != worse; q = next(q.price) {
+		v.Price = q.price.Mul(q.volume).Add(v.Price)
+		v.Quantity = q.volume.Add(v.Quantity)
+	}
+	return
+}
+
+// BookFilter restricts order book iteration of WalkOrderBook
+type BookFilter struct {
+	// SkipAsks and SkipBids exclude the side from iteration
+	SkipAsks, SkipBids bool
+
+	// Levels limits the number of best price levels of each side, 0 for all
+	Levels int
+}
+
+// WalkOrderBook calls iter for price levels selected by the filter, asks
+// first. Unlike OrderBook, each side is walked from the best price. The
+// iteration stops when iter returns false
+func (e *Engine) WalkOrderBook(
+	f BookFilter,
+	iter func(asks bool, price, volume Value, len int) bool,
+) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	e.walkLevels(f, func(asks bool, q *queue) bool {
+		return iter(asks, q.price, q.volume, q.orders.Len())
+	})
+}
+
+// walkLevels calls iter for price levels selected by the filter until it
+// returns false, the lock must be held
+func (e *Engine) walkLevels(f BookFilter, iter func(asks bool, q *queue) bool) {
+	walk := func(asks bool, best func() *queue, next func(Value) *queue) bool {
+		n := 0
+		for q := best(); q != nil && (f.Levels <= 0 || n < f.Levels); q = next(q.price) {
+			if !iter(asks, q) {
+				return false
+			}
+			n++
+		}
+		return true
+	}
+
+	if !f.SkipAsks && !walk(true, e.asks.minPrice, e.asks.greaterThan) {
+		return
+	}
+
+	if !f.SkipBids {
+		walk(false, e.bids.maxPrice, e.bids.lessThan)
+	}
+}
+
+// L2 returns up to depth best price levels of each side, all levels are
+// returned if depth <= 0
+func (e *Engine) L2(depth int) L2Book {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return L2Book{
+		Asks: e.l2Levels(e.asks.minPrice, e.asks.greaterThan, depth),
+		Bids: e.l2Levels(e.bids.maxPrice, e.bids.lessThan, depth),
+	}
+}
+
+// ExportL2 writes up to depth best price levels of each side as JSON
+func (e *Engine) ExportL2(w io.Writer, depth int) error {
+	return json.NewEncoder(w).Encode(e.L2(depth))
+}
+
+// Checksum returns CRC32 (IEEE) of up to levels best price levels of each
+// side of the order book, see L2Book.Checksum
+func (e *Engine) Checksum(levels int) uint32 {
+	return e.L2(levels).Checksum(levels)
+}
+
+// Checksum returns CRC32 (IEEE) of up to levels best price levels of each
+// side, all levels are used if levels <= 0. Levels are interleaved from the
+// best price, bid first, as "bidPrice:bidQty:askPrice:askQty:...". The side
+// which has run out of levels is skipped. Feed consumers calculate the same
+// checksum of their mirrored book to detect drift, values must be rendered
+// the same way
+func (b L2Book) Checksum(levels int) uint32 {
+	var parts []string
+	for i := 0; i < len(b.Bids) || i < len(b.Asks); i++ {
+		if levels > 0 && i >= levels {
+			break
+		}
+
+		if i < len(b.Bids) {
+			parts = append(parts, b.Bids[i][0], b.Bids[i][1])
+		}
+
+		if i < len(b.Asks) {
+			parts = append(parts, b.Asks[i][0], b.Asks[i][1])
+		}
+	}
+
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))
+}
+
+func (e *Engine) l2Levels(
+	best func() *queue,
+	next func(Value) *queue,
+	depth int,
+) [][2]string {
+	levels := [][2]string{}
+	for q := best(); q != nil && (depth <= 0 || len(levels) < depth); q = next(q.price) {
+		levels = append(levels, [2]string{e.format(q.price), e.format(q.volume)})
+	}
+	return levels
+}
@@ -0,0 +1,189 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"hash/crc32"
+	"testing"
+)
+
+func TestExportL2(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		buf    bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 2, 8)))
+
+	assertErr(t, engine.ExportL2(&buf, 1))
+
+	if buf.String() != `{"asks":[["11","5"]],"bids":[["9","1"]]}`+"\n" {
+		t.Fatal("invalid result", buf.String())
+	}
+
+	var book L2Book
+	assertErr(t, json.Unmarshal([]byte(`{"asks":[["11","5"],["12","1"]],"bids":[["9","1"],["8","2"]]}`), &book))
+
+	if l2 := engine.L2(0); len(l2.Asks) != 2 || len(l2.Bids) != 2 ||
+		l2.Asks[1] != book.Asks[1] ||
+		l2.Bids[1] != book.Bids[1] {
+		t.Fatal("invalid result", l2)
+	}
+
+	if l2 := NewEngine(asset1, asset2).L2(10); l2.Asks == nil || len(l2.Asks) != 0 {
+		t.Fatal("empty side must be exported as empty array")
+	}
+}
+
+func TestDepth(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 2, 8)))
+
+	asks, bids := engine.Depth(1)
+	if len(asks) != 1 ||
+		asks[0] != (Level{Price: tFloat64(11), Volume: tFloat64(5), Orders: 2}) ||
+		len(bids) != 1 ||
+		bids[0] != (Level{Price: tFloat64(9), Volume: tFloat64(1), Orders: 1}) {
+		t.Fatal("invalid top of book", asks, bids)
+	}
+
+	asks, bids = engine.Depth(0)
+	if len(asks) != 2 || asks[1].Price != tFloat64(12) || len(bids) != 2 || bids[1].Price != tFloat64(8) {
+		t.Fatal("all levels must be returned", asks, bids)
+	}
+}
+
+func TestWalkOrderBook(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 2, 8)))
+
+	var prices []Value
+	collect := func(asks bool, price, volume Value, len int) bool {
+		prices = append(prices, price)
+		return true
+	}
+
+	engine.WalkOrderBook(BookFilter{Levels: 1}, collect)
+	if len(prices) != 2 || prices[0] != tFloat64(11) || prices[1] != tFloat64(9) {
+		t.Fatal("best levels must be walked", prices)
+	}
+
+	prices = nil
+	engine.WalkOrderBook(BookFilter{SkipAsks: true}, collect)
+	if len(prices) != 2 || prices[0] != tFloat64(9) || prices[1] != tFloat64(8) {
+		t.Fatal("bids must be walked from the best price", prices)
+	}
+
+	prices = nil
+	engine.WalkOrderBook(BookFilter{}, func(asks bool, price, volume Value, len int) bool {
+		prices = append(prices, price)
+		return price != tFloat64(12)
+	})
+	if len(prices) != 2 || prices[1] != tFloat64(12) {
+		t.Fatal("iteration must stop", prices)
+	}
+}
+
+func TestDepthQueries(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 2, 8)))
+
+	if engine.QuantityAt(true, tFloat64(11)) != tFloat64(5) ||
+		engine.QuantityAt(false, tFloat64(8)) != tFloat64(2) ||
+		engine.QuantityAt(false, tFloat64(11)) != nil {
+		t.Fatal("invalid quantity at price")
+	}
+
+	if v := engine.CumulativeDepthTo(true, tFloat64(11.5)); v.Quantity != tFloat64(5) || v.Price != tFloat64(55) {
+		t.Fatal("invalid asks depth", v)
+	}
+
+	if v := engine.CumulativeDepthTo(false, tFloat64(8)); v.Quantity != tFloat64(3) || v.Price != tFloat64(25) {
+		t.Fatal("invalid bids depth", v)
+	}
+
+	if v := engine.CumulativeDepthTo(true, tFloat64(10)); v.Quantity != nil {
+		t.Fatal("levels worse than the limit must be skipped", v)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	book := L2Book{
+		Asks: [][2]string{{"11", "1"}, {"12", "2"}},
+		Bids: [][2]string{{"9", "3"}},
+	}
+
+	if book.Checksum(0) != crc32.ChecksumIEEE([]byte("9:3:11:1:12:2")) {
+		t.Fatal("levels must be interleaved from the best price")
+	}
+
+	if book.Checksum(1) != crc32.ChecksumIEEE([]byte("9:3:11:1")) {
+		t.Fatal("levels must be limited")
+	}
+
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 3, 9)))
+
+	if engine.Checksum(10) != book.Checksum(10) {
+		t.Fatal("engine checksum must match the L2 book")
+	}
+}
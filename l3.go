@@ -0,0 +1,76 @@
+package fastme
+
+// L3Order is the resting order of the market-by-order book
+type L3Order struct {
+	ID       string
+	Owner    Wallet
+	Price    Value
+	Quantity Value
+
+	// Position is the zero-based position in the price level queue
+	Position int
+}
+
+// WalkOrders calls iter for resting orders of the price levels selected by
+// the filter in priority order: asks first, each side from the best price,
+// orders of the level in queue order. The iteration stops when iter returns
+// false. Owners must be anonymized before publishing the market-by-order data
+func (e *Engine) WalkOrders(f BookFilter, iter func(asks bool, o L3Order) bool) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	e.walkLevels(f, func(asks bool, q *queue) bool {
+		i := 0
+		for el := q.orders.Front(); el != nil; el = el.Next() {
+			o := el.Value.(Order)
+			if !iter(asks, L3Order{
+				ID:       o.ID(),
+				Owner:    o.Owner(),
+				Price:    q.price,
+				Quantity: o.Quantity(),
+				Position: i,
+			}) {
+				return false
+			}
+			i++
+		}
+		return true
+	})
+}
+
+// QueuePosition is the place of the resting order in the price level queue
+type QueuePosition struct {
+	Price Value
+
+	// Position is the zero-based position in the price level queue
+	Position int
+
+	// Ahead is the total quantity of orders ahead in the queue, zero for the
+	// first order of the level
+	Ahead Value
+}
+
+// OrderPosition returns the queue position of the resting order with given
+// ID. Returns ErrOrderNotFound if the order is not in the order book
+func (e *Engine) OrderPosition(id string) (QueuePosition, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	orderEl, ok := e.orders[id]
+	if !ok {
+		return QueuePosition{}, ErrOrderNotFound
+	}
+
+	var (
+		o     = orderEl.Value.(Order)
+		ahead = o.Quantity().Sub(o.Quantity())
+		pos   int
+	)
+
+	for el := orderEl.Prev(); el != nil; el = el.Prev() {
+		ahead = el.Value.(Order).Quantity().Add(ahead)
+		pos++
+	}
+
+	return QueuePosition{Price: o.Price(), Position: pos, Ahead: ahead}, nil
+}
@@ -0,0 +1,94 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWalkOrders(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 9)))
+
+	var orders []L3Order
+	engine.WalkOrders(BookFilter{}, func(asks bool, o L3Order) bool {
+		orders = append(orders, o)
+		return true
+	})
+
+	if len(orders) != 4 ||
+		orders[0].ID != "2" ||
+		orders[1].ID != "3" ||
+		orders[1].Position != 1 ||
+		orders[1].Quantity != tFloat64(3) ||
+		orders[2].ID != "1" ||
+		orders[2].Position != 0 ||
+		orders[3].ID != "4" ||
+		orders[3].Owner != wallet2 {
+		t.Fatal("orders must be walked in priority order", orders)
+	}
+
+	orders = nil
+	engine.WalkOrders(BookFilter{Levels: 1}, func(asks bool, o L3Order) bool {
+		orders = append(orders, o)
+		return len(orders) < 1
+	})
+
+	if len(orders) != 1 || orders[0].ID != "2" {
+		t.Fatal("iteration must stop", orders)
+	}
+}
+
+func TestOrderPosition(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet, true, 1, 12)))
+
+	p, err := engine.OrderPosition("3")
+	assertErr(t, err)
+
+	if p.Price != tFloat64(11) || p.Position != 2 || p.Ahead != tFloat64(3) {
+		t.Fatal("invalid queue position", p)
+	}
+
+	p, err = engine.OrderPosition("4")
+	assertErr(t, err)
+
+	if p.Price != tFloat64(12) || p.Position != 0 || p.Ahead != tFloat64(0) {
+		t.Fatal("invalid queue position", p)
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+
+	p, err = engine.OrderPosition("3")
+	assertErr(t, err)
+
+	if p.Position != 1 || p.Ahead != tFloat64(2) {
+		t.Fatal("queue position must move on cancel", p)
+	}
+
+	if _, err := engine.OrderPosition("5"); err != ErrOrderNotFound {
+		t.Fatal("unknown order must not be found", err)
+	}
+}
@@ -0,0 +1,140 @@
+package fastme
+
+// ----------------------------------------------------------
+// Price ladder implementation
+// ----------------------------------------------------------
+
+// PriceLadder configures the flat array price index for instruments with
+// known tick size and bounded price range. Level lookup takes constant time,
+// prices outside the ladder are kept in the red-black tree. The ladder takes
+// precedence over the index type set by SetPriceIndex
+type PriceLadder struct {
+	// Size is the number of ticks in the ladder
+	Size int
+
+	// Slot returns position of the price in [0, Size) or false if the price
+	// is outside the ladder. Positions must follow the price order and prices
+	// inside the ladder must form a single range
+	Slot func(Value) (int, bool)
+}
+
+// SetPriceLadder switches both order book sides to the price ladder index.
+// Nil returns them to the index type set by SetPriceIndex. Resting orders
+// are kept
+func (e *Engine) SetPriceLadder(l *PriceLadder) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if l != nil {
+		c := *l
+		l = &c
+	}
+
+	e.ladder = l
+	e.reindex()
+}
+
+// ladderIndex is the price index backed by the array of price levels
+type ladderIndex struct {
+	slot   func(Value) (int, bool)
+	levels []*queue
+	count  int
+
+	// outside keeps price levels which have no position in the ladder
+	outside *treeIndex
+}
+
+func newLadderIndex(l *PriceLadder) *ladderIndex {
+	return &ladderIndex{
+		slot:    l.Slot,
+		levels:  make([]*queue, l.Size),
+		outside: newTreeIndex(),
+	}
+}
+
+// position returns ladder position of the price
+func (l *ladderIndex) position(price Value) (int, bool) {
+	i, ok := l.slot(price)
+	return i, ok && i >= 0 && i < len(l.levels)
+}
+
+func (l *ladderIndex) get(price Value) *queue {
+	if i, ok := l.position(price); ok {
+		return l.levels[i]
+	}
+	return l.outside.get(price)
+}
+
+func (l *ladderIndex) put(q *queue) {
+	if i, ok := l.position(q.price); ok {
+		l.levels[i] = q
+		l.count++
+		return
+	}
+	l.outside.put(q)
+}
+
+func (l *ladderIndex) remove(price Value) {
+	if i, ok := l.position(price); ok {
+		if l.levels[i] != nil {
+			l.levels[i] = nil
+			l.count--
+		}
+		return
+	}
+	l.outside.remove(price)
+}
+
+func (l *ladderIndex) greaterThan(price Value) *queue {
+	var q *queue
+	if l.count > 0 {
+		if i, ok := l.position(price); ok {
+			q = l.scanUp(i + 1)
+		} else if first := l.scanUp(0); price.Cmp(first.price) < 0 {
+			q = first
+		}
+	}
+
+	if o := l.outside.greaterThan(price); o != nil &&
+		(q == nil || o.price.Cmp(q.price) < 0) {
+		return o
+	}
+	return q
+}
+
+func (l *ladderIndex) lessThan(price Value) *queue {
+	var q *queue
+	if l.count > 0 {
+		if i, ok := l.position(price); ok {
+			q = l.scanDown(i - 1)
+		} else if last := l.scanDown(len(l.levels) - 1); price.Cmp(last.price) > 0 {
+			q = last
+		}
+	}
+
+	if o := l.outside.lessThan(price); o != nil &&
+		(q == nil || o.price.Cmp(q.price) > 0) {
+		return o
+	}
+	return q
+}
+
+// scanUp returns the first price level starting from the position
+func (l *ladderIndex) scanUp(i int) *queue {
+	for ; i < len(l.levels); i++ {
+		if l.levels[i] != nil {
+			return l.levels[i]
+		}
+	}
+	return nil
+}
+
+// scanDown returns the first price level going down from the position
+func (l *ladderIndex) scanDown(i int) *queue {
+	for ; i >= 0; i-- {
+		if l.levels[i] != nil {
+			return l.levels[i]
+		}
+	}
+	return nil
+}
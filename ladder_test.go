@@ -0,0 +1,106 @@
+package fastme
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// tLadder covers prices from 5 to 24 with tick 1
+var tLadder = &PriceLadder{
+	Size: 20,
+	Slot: func(v Value) (int, bool) {
+		i := int(v.(tFloat64)) - 5
+		return i, i >= 0 && i < 20
+	},
+}
+
+func bookString(e *Engine) (book string) {
+	e.OrderBook(func(asks bool, price, volume Value, len int) {
+		book += fmt.Sprintf("%v:%v:%v:%d;", asks, price, volume, len)
+	})
+	return
+}
+
+func TestPriceLadder(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		tree           = NewEngine(asset1, asset2)
+		ladder         = NewEngine(asset1, asset2, WithPriceLadder(tLadder))
+		wallets        = []*tWallet{newWallet(), newWallet()}
+		rnd            = rand.New(rand.NewSource(1))
+		ids            []string
+	)
+
+	for _, w := range wallets {
+		updateWalletBalance(w, asset1, 1e6)
+		updateWalletBalance(w, asset2, 1e6)
+	}
+
+	for i := 0; i < 2000; i++ {
+		if len(ids) > 0 && rnd.Intn(4) == 0 {
+			j := rnd.Intn(len(ids))
+			for _, e := range []*Engine{tree, ladder} {
+				err := e.CancelOrderByID(context.Background(), nil, ids[j])
+				if err != nil && err != ErrOrderNotFound {
+					t.Fatal(err)
+				}
+			}
+			ids = append(ids[:j], ids[j+1:]...)
+			continue
+		}
+
+		var (
+			id    = strconv.Itoa(i)
+			sell  = rnd.Intn(2) == 0
+			qty   = float64(1 + rnd.Intn(5))
+			price = float64(1 + rnd.Intn(30))
+		)
+
+		for k, e := range []*Engine{tree, ladder} {
+			assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder(id, wallets[k], sell, qty, price)))
+		}
+		ids = append(ids, id)
+
+		if bookString(tree) != bookString(ladder) {
+			t.Fatal("ladder order book must match the tree", bookString(tree), bookString(ladder))
+		}
+	}
+}
+
+func TestSetPriceLadder(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+		engine         = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 1, 30)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet, true, 1, 7)))
+
+	book := bookString(engine)
+
+	engine.SetPriceLadder(tLadder)
+
+	if _, ok := engine.asks.index.(*ladderIndex); !ok || bookString(engine) != book {
+		t.Fatal("resting orders must be moved to the ladder")
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "3"))
+
+	if engine.asks.minPrice().price.Cmp(tFloat64(10)) != 0 ||
+		engine.asks.greaterThan(tFloat64(10)).price.Cmp(tFloat64(30)) != 0 {
+		t.Fatal("invalid ladder levels")
+	}
+
+	engine.SetPriceLadder(nil)
+
+	if _, ok := engine.asks.index.(*treeIndex); !ok || engine.asks.level(tFloat64(30)) == nil {
+		t.Fatal("resting orders must be moved back to the tree")
+	}
+}
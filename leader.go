@@ -0,0 +1,344 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotLeader is returned by FencedEngine on mutating commands when the
+// engine instance is not the leader
+var ErrNotLeader = errors.New("Engine instance is not the leader")
+
+// FencedEngine wraps Engine and accepts mutating commands only while the
+// instance is the leader according to an external leader election. Read-only
+// methods and configuration setters are promoted from the Engine as is.
+// Followers replicate the book by applying commands to the underlying Engine
+// directly
+type FencedEngine struct {
+	*Engine
+
+	leader bool
+	m      sync.RWMutex
+}
+
+// NewFencedEngine creates wrapper for given engine. The instance starts as
+// a follower
+func NewFencedEngine(e *Engine) *FencedEngine {
+	return &FencedEngine{Engine: e}
+}
+
+// SetLeader promotes or demotes the instance. Demotion waits for in-flight
+// mutating commands, so no write is accepted after SetLeader(false) returns
+func (f *FencedEngine) SetLeader(leader bool) {
+	f.m.Lock()
+	f.leader = leader
+	f.m.Unlock()
+}
+
+// IsLeader returns true if the instance accepts mutating commands
+func (f *FencedEngine) IsLeader() bool {
+	f.m.RLock()
+	defer f.m.RUnlock()
+
+	return f.leader
+}
+
+// Follow applies leadership changes received from the election signal until
+// the channel is closed or the context is done. The instance is demoted on return
+func (f *FencedEngine) Follow(ctx context.Context, signal <-chan bool) {
+	defer f.SetLeader(false)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case leader, ok := <-signal:
+			if !ok {
+				return
+			}
+			f.SetLeader(leader)
+		}
+	}
+}
+
+// fence runs the command if the instance is the leader
+func (f *FencedEngine) fence(cmd func()) error {
+	f.m.RLock()
+	defer f.m.RUnlock()
+
+	if !f.leader {
+		return ErrNotLeader
+	}
+
+	cmd()
+	return nil
+}
+
+// PlaceOrder calls Engine.PlaceOrder if the instance is the leader
+func (f *FencedEngine) PlaceOrder(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.PlaceOrder(ctx, listener, o)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// PlaceOrders calls Engine.PlaceOrders if the instance is the leader. All
+// orders are rejected with ErrNotLeader otherwise
+func (f *FencedEngine) PlaceOrders(
+	ctx context.Context,
+	listener EventListener,
+	orders []Order,
+) (errs []error) {
+	if ferr := f.fence(func() {
+		errs = f.Engine.PlaceOrders(ctx, listener, orders)
+	}); ferr != nil {
+		errs = make([]error, len(orders))
+		for i := range errs {
+			errs[i] = ferr
+		}
+	}
+	return
+}
+
+// PlaceOrderReport calls Engine.PlaceOrderReport if the instance is the leader
+func (f *FencedEngine) PlaceOrderReport(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) (r Report, err error) {
+	if ferr := f.fence(func() {
+		r, err = f.Engine.PlaceOrderReport(ctx, listener, o)
+	}); ferr != nil {
+		return Report{Remaining: o.Quantity(), Status: StatusRejected}, ferr
+	}
+	return
+}
+
+// ReplaceOrder calls Engine.ReplaceOrder if the instance is the leader
+func (f *FencedEngine) ReplaceOrder(
+	ctx context.Context,
+	listener EventListener,
+	o, n Order,
+) (fills []Fill, err error) {
+	if ferr := f.fence(func() {
+		fills, err = f.Engine.ReplaceOrder(ctx, listener, o, n)
+	}); ferr != nil {
+		return nil, ferr
+	}
+	return
+}
+
+// AmendOrder calls Engine.AmendOrder if the instance is the leader
+func (f *FencedEngine) AmendOrder(
+	ctx context.Context,
+	listener EventListener,
+	o, n Order,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.AmendOrder(ctx, listener, o, n)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// CancelOrder calls Engine.CancelOrder if the instance is the leader
+func (f *FencedEngine) CancelOrder(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.CancelOrder(ctx, listener, o)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// CancelOrderByID calls Engine.CancelOrderByID if the instance is the leader
+func (f *FencedEngine) CancelOrderByID(
+	ctx context.Context,
+	listener EventListener,
+	id string,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.CancelOrderByID(ctx, listener, id)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// CancelAll calls Engine.CancelAll if the instance is the leader
+func (f *FencedEngine) CancelAll(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+) (orders []Order, err error) {
+	err = f.fence(func() {
+		orders = f.Engine.CancelAll(ctx, listener, w)
+	})
+	return
+}
+
+// CancelAllSide calls Engine.CancelAllSide if the instance is the leader
+func (f *FencedEngine) CancelAllSide(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	sell bool,
+) (orders []Order, err error) {
+	err = f.fence(func() {
+		orders = f.Engine.CancelAllSide(ctx, listener, w, sell)
+	})
+	return
+}
+
+// PushOrder calls Engine.PushOrder if the instance is the leader
+func (f *FencedEngine) PushOrder(ctx context.Context, o Order) error {
+	return f.fence(func() {
+		f.Engine.PushOrder(ctx, o)
+	})
+}
+
+// Uncross calls Engine.Uncross if the instance is the leader
+func (f *FencedEngine) Uncross(
+	ctx context.Context,
+	listener EventListener,
+) (price Value, total Volume, err error) {
+	if ferr := f.fence(func() {
+		price, total, err = f.Engine.Uncross(ctx, listener)
+	}); ferr != nil {
+		return nil, Volume{}, ferr
+	}
+	return
+}
+
+// SetTradingState calls Engine.SetTradingState if the instance is the leader
+func (f *FencedEngine) SetTradingState(
+	ctx context.Context,
+	listener EventListener,
+	state TradingState,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.SetTradingState(ctx, listener, state)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// Apply calls Engine.Apply if the instance is the leader
+func (f *FencedEngine) Apply(
+	ctx context.Context,
+	factory OrderFactory,
+	op Op,
+) (events []Event, err error) {
+	if ferr := f.fence(func() {
+		events, err = f.Engine.Apply(ctx, factory, op)
+	}); ferr != nil {
+		return nil, ferr
+	}
+	return
+}
+
+// KillSwitch calls Engine.KillSwitch if the instance is the leader
+func (f *FencedEngine) KillSwitch(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+) (orders []Order, err error) {
+	err = f.fence(func() {
+		orders = f.Engine.KillSwitch(ctx, listener, w)
+	})
+	return
+}
+
+// Unblock calls Engine.Unblock if the instance is the leader
+func (f *FencedEngine) Unblock(w Wallet) error {
+	return f.fence(func() {
+		f.Engine.Unblock(w)
+	})
+}
+
+// Clear calls Engine.Clear if the instance is the leader
+func (f *FencedEngine) Clear(
+	ctx context.Context,
+	listener EventListener,
+	refund bool,
+) (orders []Order, err error) {
+	if ferr := f.fence(func() {
+		orders, err = f.Engine.Clear(ctx, listener, refund)
+	}); ferr != nil {
+		return nil, ferr
+	}
+	return
+}
+
+// Drain calls Engine.Drain if the instance is the leader
+func (f *FencedEngine) Drain(ctx context.Context, listener EventListener) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.Drain(ctx, listener)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// Close calls Engine.Close if the instance is the leader. The follower is
+// shut down by closing the underlying Engine
+func (f *FencedEngine) Close() (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.Close()
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// ReduceOrder calls Engine.ReduceOrder if the instance is the leader
+func (f *FencedEngine) ReduceOrder(
+	ctx context.Context,
+	listener EventListener,
+	id string,
+	quantity Value,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.ReduceOrder(ctx, listener, id, quantity)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// CancelOrderByClientID calls Engine.CancelOrderByClientID if the instance
+// is the leader
+func (f *FencedEngine) CancelOrderByClientID(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	clientID string,
+) (err error) {
+	if ferr := f.fence(func() {
+		err = f.Engine.CancelOrderByClientID(ctx, listener, w, clientID)
+	}); ferr != nil {
+		return ferr
+	}
+	return
+}
+
+// Run executes commands sent to the Commands channel as Engine.Run does,
+// but through the fenced methods, so commands are rejected with
+// ErrNotLeader while the instance is not the leader
+func (f *FencedEngine) Run(ctx context.Context) error {
+	return f.Engine.run(ctx, f)
+}
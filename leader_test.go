@@ -0,0 +1,129 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFencedEngine(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine = NewFencedEngine(NewEngine(asset1, asset2))
+		order1 = newOrder("1", wallet1, true, 1, 10)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+
+	if err := engine.PlaceOrder(context.Background(), nil, order1); err != ErrNotLeader {
+		t.Fatal("follower must reject mutating commands")
+	}
+
+	if errs := engine.PlaceOrders(context.Background(), nil, []Order{order1}); errs[0] != ErrNotLeader {
+		t.Fatal("follower must reject batches")
+	}
+
+	if _, err := engine.Apply(context.Background(), &tOrderFactory{}, Op{Type: OpCancel, ID: "1"}); err != ErrNotLeader {
+		t.Fatal("follower must reject applied ops", err)
+	}
+
+	if _, err := engine.KillSwitch(context.Background(), nil, wallet1); err != ErrNotLeader || engine.Blocked(wallet1) {
+		t.Fatal("follower must reject kill switch", err)
+	}
+
+	if err := engine.Unblock(wallet1); err != ErrNotLeader {
+		t.Fatal("follower must reject unblock", err)
+	}
+
+	if _, err := engine.Clear(context.Background(), nil, true); err != ErrNotLeader {
+		t.Fatal("follower must reject clear", err)
+	}
+
+	if err := engine.Drain(context.Background(), nil); err != ErrNotLeader || engine.TradingState() != StateOpen {
+		t.Fatal("follower must reject drain", err)
+	}
+
+	if err := engine.Close(); err != ErrNotLeader {
+		t.Fatal("follower must reject close", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signal := make(chan bool)
+	done := make(chan struct{})
+
+	go func() {
+		engine.Follow(ctx, signal)
+		close(done)
+	}()
+
+	signal <- true
+	signal <- true // ensures the first signal is applied
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order1))
+
+	signal <- false
+	signal <- false
+
+	if _, err := engine.CancelAll(context.Background(), nil, wallet1); err != ErrNotLeader {
+		t.Fatal("demoted engine must reject mutating commands")
+	}
+
+	if err := engine.ReduceOrder(context.Background(), nil, "1", tFloat64(0.5)); err != ErrNotLeader {
+		t.Fatal("demoted engine must reject reductions", err)
+	}
+
+	if err := engine.CancelOrderByClientID(context.Background(), nil, wallet1, "a"); err != ErrNotLeader {
+		t.Fatal("demoted engine must reject cancellations by client order ID", err)
+	}
+
+	if len(engine.Orders()) != 1 {
+		t.Fatal("read-only commands must be accepted")
+	}
+
+	signal <- true
+	signal <- true
+	cancel()
+	<-done
+
+	if engine.IsLeader() {
+		t.Fatal("engine must be demoted when the signal is lost")
+	}
+
+	if err := engine.CancelOrder(context.Background(), nil, order1); err != ErrNotLeader {
+		t.Fatal("demoted engine must reject mutating commands")
+	}
+}
+
+func TestFencedEngineRun(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine      = NewFencedEngine(NewEngine(asset1, asset2))
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan error)
+		places      = make(chan PlaceResult, 1)
+	)
+
+	updateWalletBalance(wallet1, asset1, 2)
+
+	go func() { done <- engine.Run(ctx) }()
+
+	engine.Commands() <- PlaceCommand{Order: newOrder("1", wallet1, true, 1, 10), Reply: places}
+	if r := <-places; r.Err != ErrNotLeader || len(engine.Orders()) != 0 {
+		t.Fatal("follower must reject commands", r)
+	}
+
+	engine.SetLeader(true)
+
+	engine.Commands() <- PlaceCommand{Order: newOrder("1", wallet1, true, 1, 10), Reply: places}
+	if r := <-places; r.Err != nil || len(engine.Orders()) != 1 {
+		t.Fatal("leader must execute commands", r)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatal("run loop must stop with the context", err)
+	}
+}
@@ -0,0 +1,100 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEngineClosed is returned by mutating commands of the closed engine
+var ErrEngineClosed = errors.New("Engine is closed")
+
+// Clear removes all resting orders, asks first, in the price-time order.
+// With refund set the orders are canceled as CancelAll does: reserved funds
+// are refunded and OnExistingOrderCanceled is reported. Otherwise the orders
+// are dropped without wallet calls and events, e.g. when balances are kept
+// by the external ledger. Returns removed orders
+func (e *Engine) Clear(
+	ctx context.Context,
+	listener EventListener,
+	refund bool,
+) (orders []Order, err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	if err := e.unusable(); err != nil {
+		return nil, err
+	}
+
+	if err := e.recordClear(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	listener = e.listenerOf(listener)
+
+	for _, s := range []*side{e.asks, e.bids} {
+		for _, q := range s.ascending() {
+			for el := q.orders.Front(); el != nil; el = el.Next() {
+				orders = append(orders, el.Value.(Order))
+			}
+		}
+	}
+
+	for _, o := range orders {
+		if !refund {
+			e.pull(ctx, o)
+			e.archive(ctx, o, StatusCanceled)
+			e.activity.canceled++
+			continue
+		}
+
+		e.cancel(ctx, listener, o)
+		listener.OnExistingOrderCanceled(ctx, o)
+	}
+
+	return orders, nil
+}
+
+// Drain stops accepting new orders while cancellations are accepted. It
+// switches the engine to the StateCancelOnly trading state, so orders are
+// accepted again if the state is changed
+func (e *Engine) Drain(ctx context.Context, listener EventListener) error {
+	return e.SetTradingState(ctx, listener, StateCancelOnly)
+}
+
+// Close stops the Run loop and rejects further mutating commands with
+// ErrEngineClosed. Commands left in the Commands channel are not executed.
+// Resting orders are kept and read-only methods are served, so the final
+// snapshot may be taken after Close. Returns ErrEngineClosed if the engine
+// is already closed
+func (e *Engine) Close() error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.closed {
+		return ErrEngineClosed
+	}
+
+	e.closed = true
+	close(e.closing())
+	return nil
+}
+
+// closing returns the channel closed by Close
+func (e *Engine) closing() chan struct{} {
+	if e.done == nil {
+		e.done = make(chan struct{})
+	}
+	return e.done
+}
+
+// unusable returns the error rejecting mutating commands: ErrEngineClosed
+// or the error of the command which corrupted the engine
+func (e *Engine) unusable() error {
+	if e.closed {
+		return ErrEngineClosed
+	}
+	return e.corrupted
+}
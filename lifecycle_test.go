@@ -0,0 +1,152 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClear(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		buf              bytes.Buffer
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	engine.SetJournal(NewJournal(&buf))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 2, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 5, 9)))
+
+	orders, err := engine.Clear(context.Background(), nil, true)
+	assertErr(t, err)
+
+	if len(orders) != 3 || orders[0].ID() != "2" || orders[1].ID() != "1" || orders[2].ID() != "3" {
+		t.Fatal("resting orders must be cleared in order", orders)
+	}
+
+	if len(engine.Orders()) != 0 {
+		t.Fatal("order book must be empty")
+	}
+
+	if walletBalance(wallet1, asset1) != 10 || walletInOrder(wallet1, asset1) != 0 ||
+		walletBalance(wallet2, asset2) != 100 || walletInOrder(wallet2, asset2) != 0 {
+		t.Fatal("cleared orders must be refunded")
+	}
+
+	wallet3, wallet4 := newWallet(), newWallet()
+	updateWalletBalance(wallet3, asset1, 10)
+	updateWalletBalance(wallet4, asset2, 100)
+
+	replayed := NewEngine(asset1, asset2)
+	assertErr(t, replayed.Replay(context.Background(), bytes.NewReader(buf.Bytes()),
+		&tOrderFactory{owners: map[string]*tWallet{"1": wallet3, "2": wallet3, "3": wallet4}}, nil))
+
+	if len(replayed.Orders()) != 0 || walletInOrder(wallet3, asset1) != 0 {
+		t.Fatal("clearance must be replayed")
+	}
+}
+
+func TestClearWithoutRefund(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 2, 10)))
+
+	orders, err := engine.Clear(context.Background(), nil, false)
+	assertErr(t, err)
+
+	if len(orders) != 1 {
+		t.Fatal("resting order must be cleared", orders)
+	}
+
+	if _, err := engine.FindOrder("1"); err != ErrOrderNotFound {
+		t.Fatal("cleared order must not be found", err)
+	}
+
+	if walletBalance(wallet, asset1) != 8 || walletInOrder(wallet, asset1) != 2 {
+		t.Fatal("cleared orders must not be refunded")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 2, 10)))
+	assertErr(t, engine.Drain(context.Background(), nil))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 2, 10)); err == nil {
+		t.Fatal("new orders must be rejected while draining")
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+}
+
+func TestClose(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		done   = make(chan error)
+		places = make(chan PlaceResult, 1)
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	go func() { done <- engine.Run(context.Background()) }()
+
+	engine.Commands() <- PlaceCommand{Order: newOrder("1", wallet, true, 2, 10), Reply: places}
+	if r := <-places; r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	assertErr(t, engine.Close())
+
+	select {
+	case err := <-done:
+		assertErr(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run loop must stop on close")
+	}
+
+	if err := engine.Close(); err != ErrEngineClosed {
+		t.Fatal("engine must be closed once", err)
+	}
+
+	if err := engine.Run(context.Background()); err != ErrEngineClosed {
+		t.Fatal("closed engine must not run", err)
+	}
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 2, 10)); err != ErrEngineClosed {
+		t.Fatal("closed engine must reject orders", err)
+	}
+
+	if err := engine.CancelOrderByID(context.Background(), nil, "1"); err != ErrEngineClosed {
+		t.Fatal("closed engine must reject cancellations", err)
+	}
+
+	if _, err := engine.FindOrder("1"); err != nil {
+		t.Fatal("resting orders must be kept", err)
+	}
+}
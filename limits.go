@@ -0,0 +1,140 @@
+package fastme
+
+import "errors"
+
+// Wallet limit errors, wrapped by LimitError
+var (
+	ErrOpenOrdersLimit = errors.New("Open orders limit exceeded")
+
+	ErrNotionalLimit = errors.New("Notional limit exceeded")
+
+	ErrOrderSizeLimit = errors.New("Order size limit exceeded")
+)
+
+// WalletLimits bound orders of the wallet. Orders exceeding limits are
+// rejected on placement, replacement and amendment with LimitError
+type WalletLimits struct {
+	// OpenOrders is the maximum number of resting orders, 0 disables the check
+	OpenOrders int
+
+	// Notional is the maximum total price * quantity of resting orders
+	// including the limit order being placed, nil disables the check
+	Notional Value
+
+	// OrderSize is the maximum order quantity, nil disables the check
+	OrderSize Value
+}
+
+// LimitError is returned when the order exceeds the wallet limit. Reason is
+// ErrOpenOrdersLimit, ErrNotionalLimit or ErrOrderSizeLimit
+type LimitError struct {
+	Reason error
+
+	// Limit and Requested are the exceeded limit and the requested value,
+	// both nil for ErrOpenOrdersLimit
+	Limit, Requested Value
+}
+
+func (e *LimitError) Error() string {
+	return e.Reason.Error()
+}
+
+// Unwrap returns the reason
+func (e *LimitError) Unwrap() error {
+	return e.Reason
+}
+
+type walletLimits struct {
+	all     *WalletLimits
+	wallets map[Wallet]*WalletLimits
+}
+
+// SetLimits sets limits applied to wallets without own limits, nil removes them
+func (e *Engine) SetLimits(l *WalletLimits) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if l == nil {
+		e.limits.all = nil
+		return
+	}
+
+	limits := *l
+	e.limits.all = &limits
+}
+
+// SetWalletLimits sets limits of the wallet overriding ones set by
+// SetLimits, nil removes them
+func (e *Engine) SetWalletLimits(w Wallet, l *WalletLimits) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if l == nil {
+		delete(e.limits.wallets, w)
+		return
+	}
+
+	if e.limits.wallets == nil {
+		e.limits.wallets = make(map[Wallet]*WalletLimits)
+	}
+
+	limits := *l
+	e.limits.wallets[w] = &limits
+}
+
+// checkLimits returns LimitError if the order exceeds limits of its owner.
+// Resting order replaced by it is excluded, it's nil for new orders
+func (e *Engine) checkLimits(o, replaced Order) error {
+	l, ok := e.limits.wallets[o.Owner()]
+	if !ok {
+		l = e.limits.all
+	}
+
+	if l == nil {
+		return nil
+	}
+
+	if l.OrderSize != nil && o.Quantity().Cmp(l.OrderSize) > 0 {
+		return &LimitError{
+			Reason:    ErrOrderSizeLimit,
+			Limit:     l.OrderSize,
+			Requested: o.Quantity(),
+		}
+	}
+
+	if l.OpenOrders > 0 {
+		open := len(e.owned[o.Owner()])
+		if replaced != nil {
+			open--
+		}
+
+		if open >= l.OpenOrders {
+			return &LimitError{Reason: ErrOpenOrdersLimit}
+		}
+	}
+
+	// Market orders never rest
+	if l.Notional == nil || isMarket(o) {
+		return nil
+	}
+
+	// Summed in the price-time order, as rounding may depend on the order
+	notional := o.Price().Mul(o.Quantity())
+	for _, r := range e.ordersOf(o.Owner(), e.asks, e.bids) {
+		if replaced != nil && r.ID() == replaced.ID() {
+			continue
+		}
+
+		notional = r.Price().Mul(r.Quantity()).Add(notional)
+	}
+
+	if notional.Cmp(l.Notional) > 0 {
+		return &LimitError{
+			Reason:    ErrNotionalLimit,
+			Limit:     l.Notional,
+			Requested: notional,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWalletLimits(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2, WithLimits(&WalletLimits{
+			OpenOrders: 2,
+			Notional:   tFloat64(50),
+			OrderSize:  tFloat64(3),
+		}))
+	)
+
+	updateWalletBalance(wallet1, asset2, 1000)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	var le *LimitError
+
+	err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, false, 4, 10))
+	if !errors.As(err, &le) || le.Reason != ErrOrderSizeLimit || le.Requested.Cmp(tFloat64(4)) != 0 {
+		t.Fatal("order size limit must be checked", err)
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, false, 3, 10)))
+
+	err = engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, false, 3, 10))
+	if !errors.Is(err, ErrNotionalLimit) || !errors.As(err, &le) || le.Requested.Cmp(tFloat64(60)) != 0 {
+		t.Fatal("notional limit must be checked", err)
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, false, 2, 10)))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, false, 1, 1)); !errors.Is(err, ErrOpenOrdersLimit) {
+		t.Fatal("open orders limit must be checked", err)
+	}
+
+	// The replaced order is excluded
+	_, err = engine.ReplaceOrder(context.Background(), nil,
+		newOrder("2", wallet1, false, 2, 10), newOrder("3", wallet1, false, 1, 20))
+	assertErr(t, err)
+
+	if err := engine.AmendOrder(context.Background(), nil,
+		newOrder("3", wallet1, false, 1, 20), newOrder("3", wallet1, false, 2, 20)); !errors.Is(err, ErrNotionalLimit) {
+		t.Fatal("amendment must be checked", err)
+	}
+
+	// Wallet limits override the default ones
+	engine.SetWalletLimits(wallet2, &WalletLimits{OpenOrders: 1})
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 10, 10)))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 1, 1)); !errors.Is(err, ErrOpenOrdersLimit) {
+		t.Fatal("wallet limits must be applied", err)
+	}
+}
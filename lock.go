@@ -0,0 +1,62 @@
+package fastme
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// engineMutex is the RWMutex of the engine counting contended acquisitions
+// for DebugHandler. The acquisition is contended if the lock is held or
+// awaited by the conflicting side at the attempt: by anyone for Lock and by
+// a writer for RLock. Counters are approximate, they are not synchronized
+// with the lock itself
+type engineMutex struct {
+	sync.RWMutex
+
+	// writers and readers are holding or waiting for the lock
+	writers int32
+	readers int32
+
+	// waits is allocated separately to keep counters 64-bit aligned
+	waits *lockWaits
+}
+
+// lockWaits counts contended acquisitions of the lock
+type lockWaits struct {
+	writes uint64
+	reads  uint64
+}
+
+// Lock locks the mutex for writing
+func (m *engineMutex) Lock() {
+	if atomic.AddInt32(&m.writers, 1) > 1 || atomic.LoadInt32(&m.readers) > 0 {
+		atomic.AddUint64(&m.waits.writes, 1)
+	}
+	m.RWMutex.Lock()
+}
+
+// Unlock unlocks the mutex for writing
+func (m *engineMutex) Unlock() {
+	atomic.AddInt32(&m.writers, -1)
+	m.RWMutex.Unlock()
+}
+
+// RLock locks the mutex for reading
+func (m *engineMutex) RLock() {
+	atomic.AddInt32(&m.readers, 1)
+	if atomic.LoadInt32(&m.writers) > 0 {
+		atomic.AddUint64(&m.waits.reads, 1)
+	}
+	m.RWMutex.RLock()
+}
+
+// RUnlock undoes a single RLock call
+func (m *engineMutex) RUnlock() {
+	atomic.AddInt32(&m.readers, -1)
+	m.RWMutex.RUnlock()
+}
+
+// contended returns the numbers of contended write and read acquisitions
+func (m *engineMutex) contended() (writes, reads uint64) {
+	return atomic.LoadUint64(&m.waits.writes), atomic.LoadUint64(&m.waits.reads)
+}
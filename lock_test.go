@@ -0,0 +1,54 @@
+package fastme
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEngineMutex(t *testing.T) {
+	engine := NewEngine("apples", "dollars")
+
+	// Readers don't contend with each other
+	engine.m.RLock()
+	engine.Spread()
+	engine.m.RUnlock()
+
+	if writes, reads := engine.m.contended(); writes != 0 || reads != 0 {
+		t.Fatal("uncontended lock must not be counted", writes, reads)
+	}
+
+	wait := func(n *int32, v int32) {
+		for atomic.LoadInt32(n) != v {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The reader waits for the writer, the writer waits for the reader
+	engine.m.Lock()
+	done := make(chan struct{})
+	go func() {
+		engine.Spread()
+		close(done)
+	}()
+
+	wait(&engine.m.readers, 1)
+	engine.m.Unlock()
+	<-done
+
+	engine.m.RLock()
+	done = make(chan struct{})
+	go func() {
+		engine.m.Lock()
+		engine.m.Unlock()
+		close(done)
+	}()
+
+	wait(&engine.m.writers, 1)
+	engine.m.RUnlock()
+	<-done
+
+	if writes, reads := engine.m.contended(); writes != 1 || reads != 1 {
+		t.Fatal("contended acquisitions must be counted", writes, reads)
+	}
+}
@@ -0,0 +1,95 @@
+package fastme
+
+import "context"
+
+// LogLevel is the severity of the log record
+type LogLevel uint8
+
+// Log levels
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+var logLevelNames = [...]string{
+	LogDebug: "debug",
+	LogInfo:  "info",
+	LogWarn:  "warn",
+	LogError: "error",
+}
+
+// String returns log level name
+func (l LogLevel) String() string {
+	if int(l) < len(logLevelNames) {
+		return logLevelNames[l]
+	}
+	return "unknown"
+}
+
+// LogField is the key-value attribute of the log record
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger receives engine log records: rejected commands (LogWarn), trading
+// state transitions (LogInfo) and violated invariants such as negative wallet
+// balances (LogError). Records carry order and wallet identifiers as fields.
+// Logger is called with the engine locked and must not call the engine
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, fields ...LogField)
+}
+
+// LoggerFunc is an adapter to allow the use of ordinary functions as Logger
+type LoggerFunc func(context.Context, LogLevel, string, ...LogField)
+
+// Log calls f(ctx, level, msg, fields...)
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, fields ...LogField) {
+	f(ctx, level, msg, fields...)
+}
+
+// SetLogger enables logging, nil disables it
+func (e *Engine) SetLogger(l Logger) {
+	e.m.Lock()
+	e.logger = l
+	e.m.Unlock()
+}
+
+func (e *Engine) log(ctx context.Context, level LogLevel, msg string, fields ...LogField) {
+	if e.logger != nil {
+		e.logger.Log(ctx, level, msg, fields...)
+	}
+}
+
+// reject informs RejectedListener and logs rejected command on the order.
+// Returns err
+func (e *Engine) reject(ctx context.Context, listener EventListener, o Order, err error) error {
+	listener = e.listenerOf(listener)
+
+	if l, ok := listener.(RejectedListener); ok && o != nil {
+		l.OnOrderRejected(ctx, o, err)
+	}
+
+	if e.logger != nil && o != nil {
+		e.log(ctx, LogWarn, "order rejected",
+			LogField{"order_id", o.ID()},
+			LogField{"sell", o.Sell()},
+			LogField{"wallet", o.Owner()},
+			LogField{"error", err},
+		)
+	}
+	return err
+}
+
+// checkBalance logs negative wallet balance or amount in order
+func (e *Engine) checkBalance(ctx context.Context, w Wallet, asset Asset, kind string, v Value) {
+	if e.logger != nil && v.Sign() < 0 {
+		e.log(ctx, LogError, "negative "+kind,
+			LogField{"wallet", w},
+			LogField{"asset", asset},
+			LogField{"value", e.format(v)},
+		)
+	}
+}
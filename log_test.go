@@ -0,0 +1,58 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tLogRecord struct {
+	level  LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+func TestLogger(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+
+		engine  = NewEngine(asset1, asset2)
+		records []tLogRecord
+	)
+
+	engine.SetLogger(LoggerFunc(func(ctx context.Context, level LogLevel, msg string, fields ...LogField) {
+		r := tLogRecord{level: level, msg: msg, fields: make(map[string]interface{})}
+		for _, f := range fields {
+			r.fields[f.Key] = f.Value
+		}
+		records = append(records, r)
+	}))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 1, 10)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("order must be rejected")
+	}
+
+	assertErr(t, engine.SetTradingState(context.Background(), nil, StateHalted))
+
+	if err := engine.CancelOrderByID(context.Background(), nil, "1"); err != ErrOrderNotFound {
+		t.Fatal("order must not be found")
+	}
+
+	if len(records) != 3 {
+		t.Fatal("rejections and state transitions must be logged")
+	}
+
+	if r := records[0]; r.level != LogWarn || r.fields["order_id"] != "1" ||
+		r.fields["wallet"] != wallet || !errors.Is(r.fields["error"].(error), ErrInsufficientFunds) {
+		t.Fatal("invalid rejection record")
+	}
+
+	if r := records[1]; r.level != LogInfo || r.fields["from"] != StateOpen || r.fields["to"] != StateHalted {
+		t.Fatal("invalid state record")
+	}
+
+	if r := records[2]; r.level != LogWarn || r.fields["order_id"] != "1" {
+		t.Fatal("invalid cancel record")
+	}
+}
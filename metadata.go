@@ -0,0 +1,102 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClientOrderIDExists is returned for orders reusing the client order ID
+// of the resting order of the same wallet
+var ErrClientOrderIDExists = errors.New("Order with given client order ID already exists")
+
+// MetadataOrder is an optional Order extension carrying application data,
+// e.g. the strategy tag. The engine keeps the order as is, so the metadata
+// reaches listeners, reports and the archive with the order, see
+// OrderMetadata. It's neither journaled nor included in snapshots
+type MetadataOrder interface {
+	Metadata() interface{}
+}
+
+// ClientOrder is an optional Order extension carrying the order ID assigned
+// by the client, e.g. FIX ClOrdID. Client order IDs are unique among resting
+// orders of the wallet, resting orders are looked up and canceled by them.
+// The client order ID is journaled and included in snapshots, see OrderRecord
+type ClientOrder interface {
+	ClientOrderID() string
+}
+
+// OrderMetadata returns the metadata of the order, nil if the order doesn't
+// implement MetadataOrder
+func OrderMetadata(o Order) interface{} {
+	if m, ok := o.(MetadataOrder); ok {
+		return m.Metadata()
+	}
+	return nil
+}
+
+// OrderClientID returns the client order ID, empty if the order doesn't
+// implement ClientOrder
+func OrderClientID(o Order) string {
+	if c, ok := o.(ClientOrder); ok {
+		return c.ClientOrderID()
+	}
+	return ""
+}
+
+type clientKey struct {
+	owner Wallet
+	id    string
+}
+
+// FindOrderByClientID returns the resting order of the wallet with given
+// client order ID. Returns ErrOrderNotFound if there is no such order
+func (e *Engine) FindOrderByClientID(w Wallet, clientID string) (Order, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	id, ok := e.clients[clientKey{w, clientID}]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+
+	return e.orders[id].Value.(Order), nil
+}
+
+// CancelOrderByClientID is the same as CancelOrderByID, but the order is
+// looked up by the wallet and the client order ID
+func (e *Engine) CancelOrderByClientID(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+	clientID string,
+) (err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	if err := e.unusable(); err != nil {
+		return err
+	}
+
+	id, ok := e.clients[clientKey{w, clientID}]
+	if !ok {
+		return ErrOrderNotFound
+	}
+
+	return e.cancelOrderByID(e.stamp(ctx), listener, id)
+}
+
+// checkClientID returns ErrClientOrderIDExists if the client order ID of the
+// order is used by another resting order of the wallet than replaced
+func (e *Engine) checkClientID(o, replaced Order) error {
+	clientID := OrderClientID(o)
+	if clientID == "" {
+		return nil
+	}
+
+	id, ok := e.clients[clientKey{o.Owner(), clientID}]
+	if ok && (replaced == nil || id != replaced.ID()) {
+		return ErrClientOrderIDExists
+	}
+	return nil
+}
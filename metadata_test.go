@@ -0,0 +1,128 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type tClientOrder struct {
+	*tOrder
+	clientID string
+	meta     interface{}
+}
+
+func (t *tClientOrder) ClientOrderID() string {
+	return t.clientID
+}
+
+func (t *tClientOrder) Metadata() interface{} {
+	return t.meta
+}
+
+type tClientOrderFactory struct {
+	tOrderFactory
+}
+
+func (t *tClientOrderFactory) Order(ctx context.Context, r OrderRecord) (Order, error) {
+	o, err := t.tOrderFactory.Order(ctx, r)
+	return &tClientOrder{tOrder: o.(*tOrder), clientID: r.ClientOrderID}, err
+}
+
+func TestClientOrderID(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+		buf              bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset1, 10)
+
+	engine.SetJournal(NewJournal(&buf))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil,
+		&tClientOrder{tOrder: newOrder("1", wallet1, true, 1, 10), clientID: "a", meta: 42}))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil,
+		&tClientOrder{tOrder: newOrder("2", wallet2, true, 1, 10), clientID: "a"}))
+
+	if err := engine.PlaceOrder(context.Background(), nil,
+		&tClientOrder{tOrder: newOrder("3", wallet1, true, 1, 10), clientID: "a"}); err != ErrClientOrderIDExists {
+		t.Fatal("client order ID must be unique within the wallet", err)
+	}
+
+	o, err := engine.FindOrderByClientID(wallet1, "a")
+	if err != nil || o.ID() != "1" || OrderMetadata(o) != 42 {
+		t.Fatal("order must be found by client order ID", o, err)
+	}
+
+	// Replacement takes the client order ID over
+	_, err = engine.ReplaceOrder(context.Background(), nil, o,
+		&tClientOrder{tOrder: newOrder("4", wallet1, true, 1, 11), clientID: "b"})
+	assertErr(t, err)
+
+	if _, err := engine.FindOrderByClientID(wallet1, "a"); err != ErrOrderNotFound {
+		t.Fatal("replaced client order ID must be released", err)
+	}
+
+	wallet3, wallet4 := newWallet(), newWallet()
+	updateWalletBalance(wallet3, asset1, 10)
+	updateWalletBalance(wallet4, asset1, 10)
+
+	replayed := NewEngine(asset1, asset2)
+	assertErr(t, replayed.Replay(context.Background(), bytes.NewReader(buf.Bytes()), &tClientOrderFactory{
+		tOrderFactory{owners: map[string]*tWallet{"1": wallet3, "2": wallet4, "4": wallet3}},
+	}, nil))
+
+	if o, err := replayed.FindOrderByClientID(wallet3, "b"); err != nil || o.ID() != "4" {
+		t.Fatal("client order ID must be replayed", o, err)
+	}
+
+	assertErr(t, engine.CancelOrderByClientID(context.Background(), nil, wallet1, "b"))
+
+	if _, err := engine.FindOrder("4"); err != ErrOrderNotFound {
+		t.Fatal("order must be canceled by client order ID", err)
+	}
+
+	if err := engine.CancelOrderByClientID(context.Background(), nil, wallet1, "b"); err != ErrOrderNotFound {
+		t.Fatal("canceled order must not be found", err)
+	}
+
+	buf.Reset()
+	assertErr(t, engine.Snapshot(context.Background(), &buf))
+
+	restored := NewEngine(asset1, asset2)
+	assertErr(t, restored.Restore(context.Background(), &buf, &tClientOrderFactory{
+		tOrderFactory{owners: map[string]*tWallet{"2": wallet2}},
+	}))
+
+	if o, err := restored.FindOrderByClientID(wallet2, "a"); err != nil || o.ID() != "2" {
+		t.Fatal("client order ID must be restored", o, err)
+	}
+}
+
+func TestReplaceClientOrderID(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+		engine         = NewEngine(asset1, asset2)
+		order1         = &tClientOrder{tOrder: newOrder("1", wallet1, true, 1, 10), clientID: "a"}
+		order2         = &tClientOrder{tOrder: newOrder("2", wallet1, true, 1, 10), clientID: "b"}
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order1))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order2))
+
+	// Same price replacement is amended in place
+	if _, err := engine.ReplaceOrder(context.Background(), nil, order2,
+		&tClientOrder{tOrder: newOrder("2", wallet1, true, 2, 10), clientID: "a"}); err != ErrClientOrderIDExists {
+		t.Fatal("replacement must not take client order ID of other order", err)
+	}
+
+	if o, err := engine.FindOrderByClientID(wallet1, "a"); err != nil || o != order1 {
+		t.Fatal("client order ID index must be intact", o, err)
+	}
+}
@@ -0,0 +1,172 @@
+package fastme
+
+import "context"
+
+// ListenerMux dispatches events to multiple listeners in order. Optional
+// EventListener extensions are forwarded to the listeners implementing them.
+// A panic in one listener doesn't prevent delivery to the rest
+type ListenerMux struct {
+	// Listeners receive events in the slice order
+	Listeners []EventListener
+
+	// Recover is called with the listener and the recovered value if the
+	// listener panics. Panics are swallowed if it's nil
+	Recover func(EventListener, interface{})
+}
+
+// NewListenerMux creates dispatcher to given listeners
+func NewListenerMux(listeners ...EventListener) *ListenerMux {
+	return &ListenerMux{Listeners: listeners}
+}
+
+func (m *ListenerMux) each(fn func(EventListener)) {
+	for _, l := range m.Listeners {
+		m.call(l, fn)
+	}
+}
+
+func (m *ListenerMux) call(l EventListener, fn func(EventListener)) {
+	defer func() {
+		if v := recover(); v != nil && m.Recover != nil {
+			m.Recover(l, v)
+		}
+	}()
+
+	fn(l)
+}
+
+// OnIncomingOrderPartial dispatches event to all listeners
+func (m *ListenerMux) OnIncomingOrderPartial(ctx context.Context, o Order, v Volume) {
+	m.each(func(l EventListener) { l.OnIncomingOrderPartial(ctx, o, v) })
+}
+
+// OnIncomingOrderDone dispatches event to all listeners
+func (m *ListenerMux) OnIncomingOrderDone(ctx context.Context, o Order, v Volume) {
+	m.each(func(l EventListener) { l.OnIncomingOrderDone(ctx, o, v) })
+}
+
+// OnIncomingOrderPlaced dispatches event to all listeners
+func (m *ListenerMux) OnIncomingOrderPlaced(ctx context.Context, o Order) {
+	m.each(func(l EventListener) { l.OnIncomingOrderPlaced(ctx, o) })
+}
+
+// OnExistingOrderPartial dispatches event to all listeners
+func (m *ListenerMux) OnExistingOrderPartial(ctx context.Context, o Order, v Volume) {
+	m.each(func(l EventListener) { l.OnExistingOrderPartial(ctx, o, v) })
+}
+
+// OnExistingOrderDone dispatches event to all listeners
+func (m *ListenerMux) OnExistingOrderDone(ctx context.Context, o Order, v Volume) {
+	m.each(func(l EventListener) { l.OnExistingOrderDone(ctx, o, v) })
+}
+
+// OnExistingOrderCanceled dispatches event to all listeners
+func (m *ListenerMux) OnExistingOrderCanceled(ctx context.Context, o Order) {
+	m.each(func(l EventListener) { l.OnExistingOrderCanceled(ctx, o) })
+}
+
+// OnBalanceChanged dispatches event to all listeners
+func (m *ListenerMux) OnBalanceChanged(ctx context.Context, w Wallet, a Asset, v Value) {
+	m.each(func(l EventListener) { l.OnBalanceChanged(ctx, w, a, v) })
+}
+
+// OnInOrderChanged dispatches event to all listeners
+func (m *ListenerMux) OnInOrderChanged(ctx context.Context, w Wallet, a Asset, v Value) {
+	m.each(func(l EventListener) { l.OnInOrderChanged(ctx, w, a, v) })
+}
+
+// OnIncomingOrderCanceled dispatches event to IncomingCanceledListener implementations
+func (m *ListenerMux) OnIncomingOrderCanceled(ctx context.Context, o Order) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(IncomingCanceledListener); ok {
+			l.OnIncomingOrderCanceled(ctx, o)
+		}
+	})
+}
+
+// OnExistingOrderExpired dispatches event to ExpiredListener implementations.
+// Other listeners receive OnExistingOrderCanceled
+func (m *ListenerMux) OnExistingOrderExpired(ctx context.Context, o Order) {
+	m.each(func(l EventListener) {
+		if expired, ok := l.(ExpiredListener); ok {
+			expired.OnExistingOrderExpired(ctx, o)
+		} else {
+			l.OnExistingOrderCanceled(ctx, o)
+		}
+	})
+}
+
+// OnExistingOrderUnderfunded dispatches event to UnderfundedListener
+// implementations. Other listeners receive OnExistingOrderCanceled
+func (m *ListenerMux) OnExistingOrderUnderfunded(ctx context.Context, o Order) {
+	m.each(func(l EventListener) {
+		if underfunded, ok := l.(UnderfundedListener); ok {
+			underfunded.OnExistingOrderUnderfunded(ctx, o)
+		} else {
+			l.OnExistingOrderCanceled(ctx, o)
+		}
+	})
+}
+
+// OnExistingOrderReduced dispatches event to ReducedListener implementations
+func (m *ListenerMux) OnExistingOrderReduced(ctx context.Context, o Order, reduced Value) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(ReducedListener); ok {
+			l.OnExistingOrderReduced(ctx, o, reduced)
+		}
+	})
+}
+
+// OnTradingStateChanged dispatches event to StateListener implementations
+func (m *ListenerMux) OnTradingStateChanged(ctx context.Context, from, to TradingState) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(StateListener); ok {
+			l.OnTradingStateChanged(ctx, from, to)
+		}
+	})
+}
+
+// OnMakerProtectionTriggered dispatches event to ProtectionListener implementations
+func (m *ListenerMux) OnMakerProtectionTriggered(ctx context.Context, w Wallet) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(ProtectionListener); ok {
+			l.OnMakerProtectionTriggered(ctx, w)
+		}
+	})
+}
+
+// OnOrderRejected dispatches event to RejectedListener implementations
+func (m *ListenerMux) OnOrderRejected(ctx context.Context, o Order, err error) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(RejectedListener); ok {
+			l.OnOrderRejected(ctx, o, err)
+		}
+	})
+}
+
+// OnFeeCharged dispatches event to FeeListener implementations
+func (m *ListenerMux) OnFeeCharged(ctx context.Context, o Order, f Fee) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(FeeListener); ok {
+			l.OnFeeCharged(ctx, o, f)
+		}
+	})
+}
+
+// OnRebatePaid dispatches event to RebateListener implementations
+func (m *ListenerMux) OnRebatePaid(ctx context.Context, o Order, a Asset, v Value) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(RebateListener); ok {
+			l.OnRebatePaid(ctx, o, a, v)
+		}
+	})
+}
+
+// OnTrade dispatches event to TradeListener implementations
+func (m *ListenerMux) OnTrade(ctx context.Context, t Trade) {
+	m.each(func(l EventListener) {
+		if l, ok := l.(TradeListener); ok {
+			l.OnTrade(ctx, t)
+		}
+	})
+}
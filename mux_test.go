@@ -0,0 +1,48 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tPanicListener struct {
+	*tEventListener
+}
+
+func (t *tPanicListener) OnIncomingOrderDone(ctx context.Context, o Order, v Volume) {
+	panic("listener failure")
+}
+
+func TestListenerMux(t *testing.T) {
+	var (
+		failing          = &tPanicListener{newEventListener()}
+		trades           = &tTradeListener{tEventListener: newEventListener()}
+		mux              = NewListenerMux(failing, trades)
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine    = NewEngine(asset1, asset2)
+		recovered []interface{}
+	)
+
+	mux.Recover = func(l EventListener, v interface{}) {
+		if l != failing {
+			t.Fatal("invalid panicking listener")
+		}
+		recovered = append(recovered, v)
+	}
+
+	updateWalletBalance(wallet1, asset1, 1)
+	updateWalletBalance(wallet2, asset2, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), mux, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), mux, newOrder("2", wallet2, false, 1, 10)))
+
+	if len(recovered) != 1 || recovered[0] != "listener failure" {
+		t.Fatal("panic must be recovered", recovered)
+	}
+
+	if failing.done != 1 || trades.done != 2 || len(trades.trades) != 1 {
+		t.Fatal("events must be delivered to all listeners")
+	}
+}
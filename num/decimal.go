@@ -0,0 +1,235 @@
+package num
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/newity/fastme"
+)
+
+// DivisionPrecision is the number of digits after the decimal point kept by
+// Decimal.Div, the quotient is rounded half away from zero
+var DivisionPrecision = 16
+
+// MaxExponent bounds the exponent of Decimal, so powers of ten built by the
+// math stay small. ParseDecimal rejects values out of the bound, results of
+// Mul and Div are normalised into it: larger exponents are moved to the
+// coefficient, smaller ones are rounded half away from zero to the
+// 10^-MaxExponent step
+const MaxExponent = 1000
+
+// Decimal is the arbitrary precision decimal Value: the coefficient
+// multiplied by the power of ten. Add, Sub and Mul are exact, Div is rounded
+// to DivisionPrecision digits. The zero Decimal is 0
+type Decimal struct {
+	coef *big.Int
+	exp  int32
+}
+
+// NewDecimal returns coef * 10^exp, e.g. NewDecimal(125, -2) is 1.25.
+// Panics if exp is out of ±MaxExponent
+func NewDecimal(coef int64, exp int32) Decimal {
+	if exp > MaxExponent || exp < -MaxExponent {
+		panic(ErrExponentRange)
+	}
+	return Decimal{big.NewInt(coef), exp}
+}
+
+// ParseDecimal parses the decimal "1.25", "-3" or "1e-3". Values with the
+// exponent out of ±MaxExponent are rejected with ErrExponentRange
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, &ParseError{Value: s, Err: err}
+		}
+		mantissa, exp = s[:i], e
+	}
+
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		exp -= int64(len(mantissa) - i - 1)
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+
+	coef, ok := new(big.Int).SetString(mantissa, 10)
+	if !ok {
+		return Decimal{}, &ParseError{Value: s}
+	}
+
+	if exp > MaxExponent || exp < -MaxExponent {
+		return Decimal{}, &ParseError{Value: s, Err: ErrExponentRange}
+	}
+
+	return Decimal{coef, int32(exp)}, nil
+}
+
+// Rat returns the value as big.Rat
+func (x Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(x.int())
+	if x.exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(pow10(x.exp)))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(pow10(-x.exp)))
+}
+
+// Add is an "+" operation
+func (x Decimal) Add(y fastme.Value) fastme.Value {
+	a, b, exp := align(x, decimalOf(y))
+	return Decimal{a.Add(a, b), exp}
+}
+
+// Sub is an "-" operation
+func (x Decimal) Sub(y fastme.Value) fastme.Value {
+	a, b, exp := align(x, decimalOf(y))
+	return Decimal{a.Sub(a, b), exp}
+}
+
+// Mul is an "*" operation
+func (x Decimal) Mul(y fastme.Value) fastme.Value {
+	d := decimalOf(y)
+	return normalize(new(big.Int).Mul(x.int(), d.int()), int64(x.exp)+int64(d.exp))
+}
+
+// Div is an "/" operation rounded to DivisionPrecision digits after the
+// decimal point, the divisor must not be zero
+func (x Decimal) Div(y fastme.Value) fastme.Value {
+	var (
+		prec = int32(DivisionPrecision)
+		num  = x.Rat()
+		q    = num.Quo(num, decimalOf(y).Rat())
+	)
+
+	if prec > MaxExponent {
+		prec = MaxExponent
+	}
+
+	// Scale by 10^prec and round half away from zero
+	q.Mul(q, new(big.Rat).SetInt(pow10(prec)))
+
+	return Decimal{quo(q.Num(), q.Denom()), -prec}
+}
+
+// Neg returns -x
+func (x Decimal) Neg() fastme.Value {
+	return Decimal{new(big.Int).Neg(x.int()), x.exp}
+}
+
+// Cmp returns 1 if x > y, -1 if x < y and 0 if x == y
+func (x Decimal) Cmp(y fastme.Value) int {
+	a, b, _ := align(x, decimalOf(y))
+	return a.Cmp(b)
+}
+
+// Sign returns 1 if x > 0, -1 if x < 0 and 0 if x == 0
+func (x Decimal) Sign() int {
+	return x.int().Sign()
+}
+
+// Hash returns the shortest decimal representation without exponent, e.g.
+// "1.25", so equal values have equal hashes
+func (x Decimal) Hash() string {
+	coef, exp := new(big.Int).Set(x.int()), x.exp
+
+	if coef.Sign() == 0 {
+		return "0"
+	}
+
+	// Trailing zeros are removed from the coefficient
+	ten, mod := big.NewInt(10), new(big.Int)
+	for exp < 0 {
+		q, r := new(big.Int).QuoRem(coef, ten, mod)
+		if r.Sign() != 0 {
+			break
+		}
+		coef, exp = q, exp+1
+	}
+
+	if exp >= 0 {
+		return coef.Mul(coef, pow10(exp)).String()
+	}
+
+	var (
+		digits = new(big.Int).Abs(coef).String()
+		frac   = int(-exp)
+		sign   = ""
+	)
+
+	if coef.Sign() < 0 {
+		sign = "-"
+	}
+
+	if len(digits) <= frac {
+		digits = strings.Repeat("0", frac-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-frac] + "." + digits[len(digits)-frac:]
+}
+
+func (x Decimal) String() string {
+	return x.Hash()
+}
+
+// Mod is an "%" operation, the result has the sign of x
+func (x Decimal) Mod(y fastme.Value) fastme.Value {
+	a, b, exp := align(x, decimalOf(y))
+	return Decimal{a.Rem(a, b), exp}
+}
+
+var zeroInt = new(big.Int)
+
+// decimalOf returns the value of y, nil is zero
+func decimalOf(y fastme.Value) Decimal {
+	if y == nil {
+		return Decimal{}
+	}
+	return y.(Decimal)
+}
+
+func (x Decimal) int() *big.Int {
+	if x.coef == nil {
+		return zeroInt
+	}
+	return x.coef
+}
+
+// normalize returns coef * 10^exp with the exponent within ±MaxExponent
+func normalize(coef *big.Int, exp int64) Decimal {
+	switch {
+	case exp > MaxExponent:
+		coef.Mul(coef, pow10(int32(exp-MaxExponent)))
+		exp = MaxExponent
+	case exp < -MaxExponent:
+		coef = quo(coef, pow10(int32(-MaxExponent-exp)))
+		exp = -MaxExponent
+	}
+	return Decimal{coef, int32(exp)}
+}
+
+// quo returns n / d rounded half away from zero, d must be positive
+func quo(n, d *big.Int) *big.Int {
+	q, rem := new(big.Int).QuoRem(n, d, new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(rem, 1)).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(int64(rem.Sign())))
+	}
+	return q
+}
+
+// align returns copies of coefficients of x and y scaled to the common exponent
+func align(x, y Decimal) (a, b *big.Int, exp int32) {
+	a, b = new(big.Int).Set(x.int()), new(big.Int).Set(y.int())
+
+	switch {
+	case x.exp > y.exp:
+		a.Mul(a, pow10(x.exp-y.exp))
+		return a, b, y.exp
+	case x.exp < y.exp:
+		b.Mul(b, pow10(y.exp-x.exp))
+	}
+	return a, b, x.exp
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
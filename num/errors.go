@@ -0,0 +1,25 @@
+package num
+
+import "errors"
+
+// ErrExponentRange is returned for Decimal values with the exponent out of
+// ±MaxExponent
+var ErrExponentRange = errors.New("Exponent out of range")
+
+// ParseError is returned when the string is not a valid value
+type ParseError struct {
+	Value string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err != nil {
+		return "Invalid value " + e.Value + ": " + e.Err.Error()
+	}
+	return "Invalid value " + e.Value
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
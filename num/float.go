@@ -0,0 +1,115 @@
+package num
+
+import (
+	"math/big"
+
+	"github.com/newity/fastme"
+)
+
+// FloatPrecision is the mantissa precision in bits of Float values
+const FloatPrecision = 256
+
+// Float is the arbitrary precision binary floating point Value. Decimal
+// fractions like 0.1 are not exact, prefer Decimal or Rat for prices and
+// quantities given in decimals. The zero Float is 0
+type Float struct {
+	f *big.Float
+}
+
+// NewFloat returns x, x must not be NaN
+func NewFloat(x float64) Float {
+	return Float{new(big.Float).SetPrec(FloatPrecision).SetFloat64(x)}
+}
+
+// ParseFloat parses the decimal "1.25" or "1e-3"
+func ParseFloat(s string) (Float, error) {
+	f, _, err := big.ParseFloat(s, 10, FloatPrecision, big.ToNearestEven)
+	if err != nil {
+		return Float{}, &ParseError{Value: s, Err: err}
+	}
+	return Float{f}, nil
+}
+
+// Big returns the copy of the value as big.Float
+func (x Float) Big() *big.Float {
+	return new(big.Float).Copy(x.float())
+}
+
+// Add is an "+" operation
+func (x Float) Add(y fastme.Value) fastme.Value {
+	return Float{newFloat().Add(x.float(), floatOf(y))}
+}
+
+// Sub is an "-" operation
+func (x Float) Sub(y fastme.Value) fastme.Value {
+	return Float{newFloat().Sub(x.float(), floatOf(y))}
+}
+
+// Mul is an "*" operation
+func (x Float) Mul(y fastme.Value) fastme.Value {
+	return Float{newFloat().Mul(x.float(), floatOf(y))}
+}
+
+// Div is an "/" operation, the divisor must not be zero
+func (x Float) Div(y fastme.Value) fastme.Value {
+	return Float{newFloat().Quo(x.float(), floatOf(y))}
+}
+
+// Neg returns -x
+func (x Float) Neg() fastme.Value {
+	return Float{newFloat().Neg(x.float())}
+}
+
+// Cmp returns 1 if x > y, -1 if x < y and 0 if x == y
+func (x Float) Cmp(y fastme.Value) int {
+	return x.float().Cmp(floatOf(y))
+}
+
+// Sign returns 1 if x > 0, -1 if x < 0 and 0 if x == 0
+func (x Float) Sign() int {
+	return x.float().Sign()
+}
+
+// Hash returns the shortest decimal representation identifying the value
+func (x Float) Hash() string {
+	if x.Sign() == 0 {
+		return "0"
+	}
+	return x.float().Text('g', -1)
+}
+
+func (x Float) String() string {
+	return x.Hash()
+}
+
+// Mod is an "%" operation, the result has the sign of x
+func (x Float) Mod(y fastme.Value) fastme.Value {
+	var (
+		d    = floatOf(y)
+		q    = newFloat().Quo(x.float(), d)
+		n, _ = q.Int(nil)
+	)
+
+	return Float{newFloat().Sub(x.float(), q.Mul(d, q.SetInt(n)))}
+}
+
+var zeroFloat = newFloat()
+
+// floatOf returns the value of y, nil is zero
+func floatOf(y fastme.Value) *big.Float {
+	if y == nil {
+		return zeroFloat
+	}
+	return y.(Float).float()
+}
+
+func (x Float) float() *big.Float {
+	if x.f == nil {
+		return zeroFloat
+	}
+	return x.f
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(FloatPrecision)
+}
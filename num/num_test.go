@@ -0,0 +1,274 @@
+package num
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/newity/fastme"
+)
+
+type parser struct {
+	name  string
+	parse func(string) (fastme.Value, error)
+}
+
+var parsers = []parser{
+	{"rat", func(s string) (fastme.Value, error) { return ParseRat(s) }},
+	{"decimal", func(s string) (fastme.Value, error) { return ParseDecimal(s) }},
+	{"float", func(s string) (fastme.Value, error) { return ParseFloat(s) }},
+}
+
+// samples are exactly representable by all types
+var samples = []string{"0", "1", "-2", "0.5", "3.25", "-0.125", "1024", "1e2"}
+
+func values(t *testing.T, p parser) []fastme.Value {
+	vs := make([]fastme.Value, len(samples))
+	for i, s := range samples {
+		v, err := p.parse(s)
+		if err != nil {
+			t.Fatal(p.name, s, err)
+		}
+		vs[i] = v
+	}
+	return vs
+}
+
+func TestArithmeticProperties(t *testing.T) {
+	for _, p := range parsers {
+		vs := values(t, p)
+		zero := vs[0].Sub(vs[0])
+
+		for _, x := range vs {
+			hash := x.Hash()
+
+			if x.Add(zero).Cmp(x) != 0 || x.Mul(vs[1]).Cmp(x) != 0 {
+				t.Fatal(p.name, "identity", x)
+			}
+
+			if x.Sub(x).Sign() != 0 {
+				t.Fatal(p.name, "x-x must be zero", x)
+			}
+
+			if n := x.(interface{ Neg() fastme.Value }).Neg(); n.Add(x).Sign() != 0 || n.Sign() != -x.Sign() {
+				t.Fatal(p.name, "negation", x)
+			}
+
+			for _, y := range vs {
+				if x.Add(y).Cmp(y.Add(x)) != 0 || x.Mul(y).Cmp(y.Mul(x)) != 0 {
+					t.Fatal(p.name, "commutativity", x, y)
+				}
+
+				if x.Cmp(y) != x.Sub(y).Sign() || x.Cmp(y) != -y.Cmp(x) {
+					t.Fatal(p.name, "comparison", x, y)
+				}
+
+				if x.Add(y).Sub(y).Cmp(x) != 0 {
+					t.Fatal(p.name, "subtraction", x, y)
+				}
+
+				if y.Sign() != 0 {
+					if q := x.Mul(y).(fastme.DivValue).Div(y); q.Cmp(x) != 0 {
+						t.Fatal(p.name, "division", x, y, q)
+					}
+				}
+
+				for _, z := range vs {
+					if x.Add(y).Add(z).Cmp(x.Add(y.Add(z))) != 0 {
+						t.Fatal(p.name, "associativity", x, y, z)
+					}
+
+					if x.Mul(y.Add(z)).Cmp(x.Mul(y).Add(x.Mul(z))) != 0 {
+						t.Fatal(p.name, "distributivity", x, y, z)
+					}
+				}
+			}
+
+			if x.Add(nil).Cmp(x) != 0 || x.Mul(nil).Sign() != 0 || x.Cmp(nil) != x.Sign() {
+				t.Fatal(p.name, "nil must be zero", x)
+			}
+
+			if x.Hash() != hash {
+				t.Fatal(p.name, "operands must not change", hash, x.Hash())
+			}
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	for _, p := range parsers {
+		vs := values(t, p)
+
+		for i, x := range vs {
+			// Equal values have equal hashes regardless of the way they were computed
+			if y := x.Mul(vs[3]).Add(x.Mul(vs[3])); y.Hash() != x.Hash() {
+				t.Fatal(p.name, "equal values must have equal hashes", x.Hash(), y.Hash())
+			}
+
+			r, ok := new(big.Rat).SetString(x.Hash())
+			expected, _ := new(big.Rat).SetString(samples[i])
+			if !ok || r.Cmp(expected) != 0 {
+				t.Fatal(p.name, "hash must be parsed as the value", x.Hash())
+			}
+
+			if s := (fastme.DecimalFormatter{Decimals: 4, TrimZeros: true}).Format(x); s != expected.FloatString(4) &&
+				s != trim(expected.FloatString(4)) {
+				t.Fatal(p.name, "invalid formatting", s)
+			}
+		}
+
+		if _, err := p.parse("1.2.3"); err == nil {
+			t.Fatal(p.name, "invalid value must be rejected")
+		}
+	}
+
+	if h := NewDecimal(1500, -3).Hash(); h != "1.5" {
+		t.Fatal("decimal hash must be normalized", h)
+	}
+
+	if h := NewDecimal(-5, -3).Hash(); h != "-0.005" {
+		t.Fatal("invalid decimal hash", h)
+	}
+
+	if h := NewRat(2, 4).Hash(); h != "1/2" {
+		t.Fatal("invalid rat hash", h)
+	}
+}
+
+func trim(s string) string {
+	for len(s) > 0 && (s[len(s)-1] == '0' || s[len(s)-1] == '.') {
+		if s[len(s)-1] == '.' {
+			return s[:len(s)-1]
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func TestDecimalDiv(t *testing.T) {
+	for _, c := range []struct {
+		x, y, q string
+	}{
+		{"1", "3", "0.3333333333333333"},
+		{"2", "3", "0.6666666666666667"},
+		{"-2", "3", "-0.6666666666666667"},
+		{"10", "4", "2.5"},
+	} {
+		x, _ := ParseDecimal(c.x)
+		y, _ := ParseDecimal(c.y)
+
+		if q := x.Div(y).Hash(); q != c.q {
+			t.Fatal("invalid quotient", c, q)
+		}
+	}
+
+	if q := NewRat(1, 1).Div(NewRat(3, 1)).Hash(); q != "1/3" {
+		t.Fatal("rat division must be exact", q)
+	}
+}
+
+func TestDecimalExponent(t *testing.T) {
+	for _, s := range []string{"1e300000000", "1e-300000000", "1e1001", "0.5e-1000", "1e99999999999"} {
+		if _, err := ParseDecimal(s); err == nil {
+			t.Fatal("exponent out of range must be rejected", s)
+		}
+	}
+
+	if _, err := ParseDecimal("1e1001"); !errors.Is(err, ErrExponentRange) {
+		t.Fatal("invalid error", err)
+	}
+
+	var (
+		max, _  = ParseDecimal("1e1000")
+		min, _  = ParseDecimal("1e-1000")
+		half, _ = ParseDecimal("5e-1000")
+		one     = NewDecimal(1, 0)
+	)
+
+	// Exponents are added without wrapping, the product is normalised
+	if p := max.Mul(max).(Decimal); p.exp != MaxExponent || p.Hash() != "1"+strings.Repeat("0", 2000) {
+		t.Fatal("large product must be exact", p.exp)
+	}
+
+	if p := min.Mul(max); p.Cmp(one) != 0 {
+		t.Fatal("invalid product", p)
+	}
+
+	if p := min.Mul(min); p.Sign() != 0 {
+		t.Fatal("product below the step must be rounded to zero", p)
+	}
+
+	if p := half.Mul(NewDecimal(3, -1)).(Decimal); p.exp != -MaxExponent || p.coef.Int64() != 2 {
+		t.Fatal("product must be rounded half away from zero", p.coef, p.exp)
+	}
+
+	defer func(prec int) { DivisionPrecision = prec }(DivisionPrecision)
+	DivisionPrecision = 5000
+
+	if q := one.Div(NewDecimal(3, 0)).(Decimal); q.exp != -MaxExponent {
+		t.Fatal("quotient must be rounded to the exponent bound", q.exp)
+	}
+}
+
+func TestMod(t *testing.T) {
+	for _, p := range parsers {
+		for _, c := range []struct {
+			x, y, r string
+		}{
+			{"7.5", "2", "1.5"},
+			{"-7.5", "2", "-1.5"},
+			{"1.25", "0.25", "0"},
+		} {
+			x, _ := p.parse(c.x)
+			y, _ := p.parse(c.y)
+			r, _ := p.parse(c.r)
+
+			if m := x.(fastme.ModValue).Mod(y); m.Cmp(r) != 0 {
+				t.Fatal(p.name, "invalid remainder", c, m)
+			}
+		}
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	for _, v := range []fastme.Value{Rat{}, Decimal{}, Float{}} {
+		if v.Sign() != 0 || v.Hash() != "0" || v.Add(v).Sign() != 0 {
+			t.Fatal("zero value must be zero", v)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	for _, p := range parsers {
+		var (
+			base, quote = fastme.Asset("apples"), fastme.Asset("dollars")
+			value       = func(s string) fastme.Value { v, _ := p.parse(s); return v }
+			zero        = value("0")
+
+			seller, buyer = fastme.NewMemoryWallet(zero), fastme.NewMemoryWallet(zero)
+			engine        = fastme.NewEngine(base, quote)
+		)
+
+		seller.Deposit(base, value("10"))
+		buyer.Deposit(quote, value("100"))
+
+		sell := fastme.NewLimitOrder("1", seller, fastme.SideSell, value("10.5"), value("2"))
+		buy := fastme.NewLimitOrder("2", buyer, fastme.SideBuy, value("11"), value("1.5"))
+
+		if err := engine.PlaceOrder(context.Background(), nil, sell); err != nil {
+			t.Fatal(p.name, err)
+		}
+
+		if err := engine.PlaceOrder(context.Background(), nil, buy); err != nil {
+			t.Fatal(p.name, err)
+		}
+
+		if sell.Quantity().Cmp(value("0.5")) != 0 ||
+			seller.Balance(context.Background(), quote).Cmp(value("15.75")) != 0 ||
+			buyer.Balance(context.Background(), quote).Cmp(value("84.25")) != 0 {
+			t.Fatal(p.name, "invalid execution", sell.Quantity(), seller.Balance(context.Background(), quote))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package num provides Value implementations backed by math/big: exact
+// rationals, decimals and arbitrary precision floats. Values of different
+// types must not be mixed within the engine
+package num
+
+import (
+	"math/big"
+
+	"github.com/newity/fastme"
+)
+
+// Rat is the exact rational Value. Every operation including Div is exact,
+// so it suits engines which must never round. The zero Rat is 0
+type Rat struct {
+	r *big.Rat
+}
+
+// NewRat returns a/b, b must not be zero
+func NewRat(a, b int64) Rat {
+	return Rat{big.NewRat(a, b)}
+}
+
+// ParseRat parses the fraction "a/b" or the decimal "1.25", "1e-3"
+func ParseRat(s string) (Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Rat{}, &ParseError{Value: s}
+	}
+	return Rat{r}, nil
+}
+
+// Big returns the copy of the value as big.Rat
+func (x Rat) Big() *big.Rat {
+	return new(big.Rat).Set(x.rat())
+}
+
+// Add is an "+" operation
+func (x Rat) Add(y fastme.Value) fastme.Value {
+	return Rat{new(big.Rat).Add(x.rat(), ratOf(y))}
+}
+
+// Sub is an "-" operation
+func (x Rat) Sub(y fastme.Value) fastme.Value {
+	return Rat{new(big.Rat).Sub(x.rat(), ratOf(y))}
+}
+
+// Mul is an "*" operation
+func (x Rat) Mul(y fastme.Value) fastme.Value {
+	return Rat{new(big.Rat).Mul(x.rat(), ratOf(y))}
+}
+
+// Div is an "/" operation, the divisor must not be zero
+func (x Rat) Div(y fastme.Value) fastme.Value {
+	return Rat{new(big.Rat).Quo(x.rat(), ratOf(y))}
+}
+
+// Neg returns -x
+func (x Rat) Neg() fastme.Value {
+	return Rat{new(big.Rat).Neg(x.rat())}
+}
+
+// Cmp returns 1 if x > y, -1 if x < y and 0 if x == y
+func (x Rat) Cmp(y fastme.Value) int {
+	return x.rat().Cmp(ratOf(y))
+}
+
+// Sign returns 1 if x > 0, -1 if x < 0 and 0 if x == 0
+func (x Rat) Sign() int {
+	return x.rat().Sign()
+}
+
+// Hash returns the fraction "a/b" in lowest terms or the integer "a"
+func (x Rat) Hash() string {
+	return x.rat().RatString()
+}
+
+func (x Rat) String() string {
+	return x.Hash()
+}
+
+// Mod is an "%" operation, the result has the sign of x
+func (x Rat) Mod(y fastme.Value) fastme.Value {
+	d := ratOf(y)
+	q := new(big.Rat).Quo(x.rat(), d)
+	n := new(big.Int).Quo(q.Num(), q.Denom())
+
+	return Rat{q.Sub(x.rat(), q.Mul(d, q.SetInt(n)))}
+}
+
+var zeroRat = new(big.Rat)
+
+// ratOf returns the value of y, nil is zero
+func ratOf(y fastme.Value) *big.Rat {
+	if y == nil {
+		return zeroRat
+	}
+	return y.(Rat).rat()
+}
+
+func (x Rat) rat() *big.Rat {
+	if x.r == nil {
+		return zeroRat
+	}
+	return x.r
+}
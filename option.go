@@ -0,0 +1,129 @@
+package fastme
+
+// Option configures the engine created by NewEngine
+type Option func(*Engine)
+
+// WithFeeHandler sets fee handler, see SetFeeHandler
+func WithFeeHandler(h FeeHandler) Option {
+	return func(e *Engine) { e.SetFeeHandler(h) }
+}
+
+// WithFormatter sets value formatter, see SetFormatter
+func WithFormatter(f Formatter) Option {
+	return func(e *Engine) { e.SetFormatter(f) }
+}
+
+// WithClock sets event clock, see SetClock
+func WithClock(c Clock) Option {
+	return func(e *Engine) { e.SetClock(c) }
+}
+
+// WithListener sets the listener receiving events of commands called with
+// nil listener
+func WithListener(l EventListener) Option {
+	return func(e *Engine) {
+		if l != nil {
+			e.listener = l
+		}
+	}
+}
+
+// WithSymbolSpec sets instrument restrictions, see SetSymbolSpec
+func WithSymbolSpec(spec *SymbolSpec) Option {
+	return func(e *Engine) { e.SetSymbolSpec(spec) }
+}
+
+// WithPriceIndex sets the price index type, see SetPriceIndex
+func WithPriceIndex(t IndexType) Option {
+	return func(e *Engine) { e.SetPriceIndex(t) }
+}
+
+// WithPriceLadder sets the price ladder index, see SetPriceLadder
+func WithPriceLadder(l *PriceLadder) Option {
+	return func(e *Engine) { e.SetPriceLadder(l) }
+}
+
+// WithCircuitBreaker enables circuit breaker, see SetCircuitBreaker
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(e *Engine) { e.SetCircuitBreaker(cb) }
+}
+
+// WithTakerPolicy sets marketable limit orders policy, see SetTakerPolicy
+func WithTakerPolicy(p TakerPolicy) Option {
+	return func(e *Engine) { e.SetTakerPolicy(p) }
+}
+
+// WithStrictFunds enables the strict funds mode, see SetStrictFunds
+func WithStrictFunds() Option {
+	return func(e *Engine) { e.SetStrictFunds(true) }
+}
+
+// WithFeeWallet sets the wallet collecting fees, see SetFeeWallet
+func WithFeeWallet(w Wallet) Option {
+	return func(e *Engine) { e.SetFeeWallet(w) }
+}
+
+// WithRebateWallet sets the wallet paying rebates, see SetRebateWallet
+func WithRebateWallet(w Wallet) Option {
+	return func(e *Engine) { e.SetRebateWallet(w) }
+}
+
+// WithRiskChecker sets the pre-trade risk checker, see SetRiskChecker
+func WithRiskChecker(rc RiskChecker) Option {
+	return func(e *Engine) { e.SetRiskChecker(rc) }
+}
+
+// WithLimits sets limits applied to all wallets, see SetLimits
+func WithLimits(l *WalletLimits) Option {
+	return func(e *Engine) { e.SetLimits(l) }
+}
+
+// WithRateLimit sets the order flow rate limit, see SetRateLimit
+func WithRateLimit(l *RateLimit) Option {
+	return func(e *Engine) { e.SetRateLimit(l) }
+}
+
+// WithOrderViews passes read-only order views to listeners, see SetOrderViews
+func WithOrderViews() Option {
+	return func(e *Engine) { e.SetOrderViews(true) }
+}
+
+// WithoutAccounting disables wallet accounting, see SetAccounting
+func WithoutAccounting() Option {
+	return func(e *Engine) { e.SetAccounting(false) }
+}
+
+// WithReferencePrices enables VWAP and TWAP, see SetReferencePrices
+func WithReferencePrices(r *ReferencePrices) Option {
+	return func(e *Engine) { e.SetReferencePrices(r) }
+}
+
+// WithDepthListener sets level changes listener, see SetDepthListener
+func WithDepthListener(l DepthListener) Option {
+	return func(e *Engine) { e.SetDepthListener(l) }
+}
+
+// WithTickerStats enables rolling ticker statistics, see SetTickerStats
+func WithTickerStats(s *TickerStats) Option {
+	return func(e *Engine) { e.SetTickerStats(s) }
+}
+
+// WithArchive enables completed orders archive, see SetArchive
+func WithArchive(a *Archive) Option {
+	return func(e *Engine) { e.SetArchive(a) }
+}
+
+// WithJournal enables command journaling, see SetJournal
+func WithJournal(j *Journal) Option {
+	return func(e *Engine) { e.SetJournal(j) }
+}
+
+// WithTracer enables operation tracing, see SetTracer
+func WithTracer(t Tracer) Option {
+	return func(e *Engine) { e.SetTracer(t) }
+}
+
+// WithLogger enables logging, see SetLogger
+func WithLogger(l Logger) Option {
+	return func(e *Engine) { e.SetLogger(l) }
+}
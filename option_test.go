@@ -0,0 +1,35 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+		listener       = &tTimeListener{tTradeListener: &tTradeListener{tEventListener: newEventListener()}}
+		now            = time.Unix(100, 0)
+
+		engine = NewEngine(asset1, asset2,
+			WithClock(ClockFunc(func() time.Time { return now })),
+			WithListener(listener),
+			WithSymbolSpec(&SymbolSpec{Lot: tFloat64(2)}),
+		)
+	)
+
+	updateWalletBalance(wallet, asset1, 10)
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 1, 10)); !errors.Is(err, ErrInvalidLot) {
+		t.Fatal("symbol spec must be applied")
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 2, 10)))
+
+	if len(listener.times) != 1 || !listener.times[0].Equal(now) {
+		t.Fatal("default listener and clock must be used")
+	}
+}
@@ -0,0 +1,79 @@
+package fastme
+
+// LimitOrder is the ready to use limit order. The quantity is changed by the
+// engine under its lock, so read it from listeners or after the command
+// returns
+type LimitOrder struct {
+	id       string
+	owner    Wallet
+	side     Side
+	price    Value
+	quantity Value
+
+	// ClientID is the order ID assigned by the client, see ClientOrder
+	ClientID string
+
+	// Meta is any data of the application, see MetadataOrder
+	Meta interface{}
+}
+
+// NewLimitOrder creates the limit order. Zero price is a valid limit price
+func NewLimitOrder(id string, owner Wallet, side Side, price, quantity Value) *LimitOrder {
+	return &LimitOrder{
+		id:       id,
+		owner:    owner,
+		side:     side,
+		price:    price,
+		quantity: quantity,
+	}
+}
+
+// ID returns the order ID
+func (o *LimitOrder) ID() string {
+	return o.id
+}
+
+// Owner returns the wallet of the order
+func (o *LimitOrder) Owner() Wallet {
+	return o.owner
+}
+
+// Sell returns true for sell orders
+func (o *LimitOrder) Sell() bool {
+	return o.side == SideSell
+}
+
+// Price returns the limit price
+func (o *LimitOrder) Price() Value {
+	return o.price
+}
+
+// Quantity returns the remaining quantity
+func (o *LimitOrder) Quantity() Value {
+	return o.quantity
+}
+
+// UpdateQuantity is called by the engine to set the remaining quantity
+func (o *LimitOrder) UpdateQuantity(v Value) {
+	o.quantity = v
+}
+
+// Side returns the order side
+func (o *LimitOrder) Side() Side {
+	return o.side
+}
+
+// Kind returns OrderKindLimit
+func (o *LimitOrder) Kind() OrderKind {
+	return OrderKindLimit
+}
+
+// ClientOrderID returns ClientID
+func (o *LimitOrder) ClientOrderID() string {
+	return o.ClientID
+}
+
+// Metadata returns Meta
+func (o *LimitOrder) Metadata() interface{} {
+	return o.Meta
+}
@@ -0,0 +1,48 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLimitOrder(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = NewMemoryWallet(tFloat64(0)), NewMemoryWallet(tFloat64(0))
+		engine           = NewEngine(asset1, asset2)
+
+		sell = NewLimitOrder("1", wallet1, SideSell, tFloat64(10), tFloat64(3))
+		buy  = NewLimitOrder("2", wallet2, SideBuy, tFloat64(11), tFloat64(2))
+	)
+
+	wallet1.Deposit(asset1, tFloat64(5))
+	wallet2.Deposit(asset2, tFloat64(100))
+
+	sell.Meta = "strategy-1"
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, sell))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, buy))
+
+	if sell.Quantity() != tFloat64(1) || buy.Quantity() != tFloat64(0) {
+		t.Fatal("quantities must be updated by the engine", sell.Quantity(), buy.Quantity())
+	}
+
+	if o, err := engine.FindOrder("1"); err != nil || OrderMetadata(o) != "strategy-1" {
+		t.Fatal("resting order must keep metadata", o, err)
+	}
+
+	if wallet1.Balance(context.Background(), asset1) != tFloat64(2) ||
+		wallet1.InOrder(context.Background(), asset1) != tFloat64(1) ||
+		wallet1.Balance(context.Background(), asset2) != tFloat64(20) ||
+		wallet2.Balance(context.Background(), asset1) != tFloat64(2) ||
+		wallet2.Balance(context.Background(), asset2) != tFloat64(80) {
+		t.Fatal("invalid balances")
+	}
+
+	zero := NewLimitOrder("3", wallet1, SideSell, tFloat64(0), tFloat64(1))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, zero))
+
+	if _, err := engine.FindOrder("3"); err != nil {
+		t.Fatal("zero price limit order must rest", err)
+	}
+}
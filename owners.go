@@ -0,0 +1,110 @@
+package fastme
+
+import (
+	"container/list"
+	"sort"
+)
+
+// OrdersByOwner returns resting orders of the wallet sorted by side (asks
+// first), price and time. Only price levels holding the wallet orders are
+// visited
+func (e *Engine) OrdersByOwner(w Wallet) []Order {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return e.ordersOf(w, e.asks, e.bids)
+}
+
+// OrderCountByOwner returns the number of resting orders of the wallet
+func (e *Engine) OrderCountByOwner(w Wallet) int {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return len(e.owned[w])
+}
+
+// own adds the order book element to the owner and client order ID indexes
+func (e *Engine) own(el *list.Element) {
+	o := el.Value.(Order)
+	w := o.Owner()
+
+	orders, ok := e.owned[w]
+	if !ok {
+		orders = make(map[string]*list.Element)
+		e.owned[w] = orders
+	}
+	orders[o.ID()] = el
+
+	if clientID := OrderClientID(o); clientID != "" {
+		e.clients[clientKey{w, clientID}] = o.ID()
+	}
+}
+
+// disown removes the order from the owner and client order ID indexes
+func (e *Engine) disown(o Order) {
+	w := o.Owner()
+
+	if clientID := OrderClientID(o); clientID != "" {
+		key := clientKey{w, clientID}
+		if e.clients[key] == o.ID() {
+			delete(e.clients, key)
+		}
+	}
+
+	if orders, ok := e.owned[w]; ok {
+		delete(orders, o.ID())
+		if len(orders) == 0 {
+			delete(e.owned, w)
+		}
+	}
+}
+
+// reown rebuilds the owner and client order ID indexes from the order book
+func (e *Engine) reown() {
+	e.owned = make(map[Wallet]map[string]*list.Element)
+	e.clients = make(map[clientKey]string)
+	for _, el := range e.orders {
+		e.own(el)
+	}
+}
+
+// ordersOf returns resting orders of the wallet on given sides sorted by
+// side, price and time
+func (e *Engine) ordersOf(w Wallet, sides ...*side) (orders []Order) {
+	owned := e.owned[w]
+	if len(owned) == 0 {
+		return nil
+	}
+
+	for _, s := range sides {
+		var (
+			levels []*queue
+			seen   = make(map[*queue]bool)
+		)
+
+		for _, el := range owned {
+			o := el.Value.(Order)
+			if o.Sell() != (s == e.asks) {
+				continue
+			}
+
+			if q := s.level(o.Price()); q != nil && !seen[q] {
+				seen[q] = true
+				levels = append(levels, q)
+			}
+		}
+
+		sort.Slice(levels, func(i, j int) bool {
+			return levels[i].price.Cmp(levels[j].price) < 0
+		})
+
+		for _, q := range levels {
+			for el := q.orders.Front(); el != nil; el = el.Next() {
+				if o := el.Value.(Order); o.Owner() == w {
+					orders = append(orders, o)
+				}
+			}
+		}
+	}
+	return
+}
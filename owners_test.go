@@ -0,0 +1,82 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func ownerOrderIDs(orders []Order) (ids string) {
+	for _, o := range orders {
+		ids += o.ID()
+	}
+	return
+}
+
+func TestOrdersByOwner(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet1, asset2, 100)
+	updateWalletBalance(wallet2, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet2, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet1, false, 1, 8)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet1, false, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("6", wallet2, false, 1, 9)))
+
+	if ids := ownerOrderIDs(engine.OrdersByOwner(wallet1)); ids != "3145" {
+		t.Fatal("orders must be sorted by side, price and time", ids)
+	}
+
+	if engine.OrderCountByOwner(wallet1) != 4 || engine.OrderCountByOwner(wallet2) != 2 {
+		t.Fatal("invalid order count")
+	}
+
+	// Fill the ask of the second wallet at 11 and the ask of the first one
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("7", wallet2, false, 2, 11)))
+	if ids := ownerOrderIDs(engine.OrdersByOwner(wallet1)); ids != "145" {
+		t.Fatal("filled order must be removed", ids)
+	}
+
+	if ids := ownerOrderIDs(engine.OrdersByOwner(wallet2)); ids != "6" {
+		t.Fatal("filled orders must be removed", ids)
+	}
+
+	o, err := engine.FindOrder("5")
+	assertErr(t, err)
+	assertErr(t, engine.AmendOrder(context.Background(), nil, o, newOrder("8", wallet1, false, 2, 9)))
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "4"))
+
+	if ids := ownerOrderIDs(engine.OrdersByOwner(wallet1)); ids != "18" {
+		t.Fatal("amended and cancelled orders must be reindexed", ids)
+	}
+
+	var (
+		buf      bytes.Buffer
+		restored = NewEngine(asset1, asset2)
+		factory  = &tOrderFactory{owners: map[string]*tWallet{
+			"1": wallet1, "6": wallet2, "8": wallet1,
+		}}
+	)
+
+	assertErr(t, engine.Snapshot(context.Background(), &buf))
+	assertErr(t, restored.Restore(context.Background(), bytes.NewReader(buf.Bytes()), factory))
+
+	if ids := ownerOrderIDs(restored.OrdersByOwner(wallet1)); ids != "18" {
+		t.Fatal("index must be rebuilt on restore", ids)
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "6"))
+	if engine.OrderCountByOwner(wallet2) != 0 || engine.OrdersByOwner(wallet2) != nil {
+		t.Fatal("wallet must have no orders")
+	}
+}
@@ -0,0 +1,270 @@
+// Package pb implements protobuf encoding of fastme orders, events, order book
+// levels, snapshots and service messages according to fastme.proto. Values
+// are transferred as strings rendered by the engine Formatter
+package pb
+
+import "github.com/newity/fastme"
+
+// Order is the resting or incoming order
+type Order struct {
+	ID       string
+	Sell     bool
+	Price    string
+	Quantity string
+}
+
+// NewOrder converts the order using given formatter. Nil formatter renders
+// values by Hash
+func NewOrder(o fastme.Order, f fastme.Formatter) Order {
+	return Order{
+		ID:       o.ID(),
+		Sell:     o.Sell(),
+		Price:    format(f, o.Price()),
+		Quantity: format(f, o.Quantity()),
+	}
+}
+
+// Record parses order values with the factory
+func (o *Order) Record(factory fastme.OrderFactory) (r fastme.OrderRecord, err error) {
+	r.ID, r.Sell = o.ID, o.Sell
+
+	if r.Price, err = factory.Value(o.Price); err != nil {
+		return
+	}
+
+	r.Quantity, err = factory.Value(o.Quantity)
+	return
+}
+
+// Marshal encodes the message
+func (o *Order) Marshal() []byte {
+	return o.append(nil)
+}
+
+func (o *Order) append(b []byte) []byte {
+	b = appendString(b, 1, o.ID)
+	b = appendBool(b, 2, o.Sell)
+	b = appendString(b, 3, o.Price)
+	return appendString(b, 4, o.Quantity)
+}
+
+// Unmarshal decodes the message
+func (o *Order) Unmarshal(b []byte) error {
+	*o = Order{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			o.ID, err = f.text()
+		case 2:
+			var v uint64
+			v, err = f.uint()
+			o.Sell = v != 0
+		case 3:
+			o.Price, err = f.text()
+		case 4:
+			o.Quantity, err = f.text()
+		}
+		return
+	})
+}
+
+// Volume is the executed volume
+type Volume struct {
+	Price    string
+	Quantity string
+}
+
+// NewVolume converts the volume using given formatter
+func NewVolume(v fastme.Volume, f fastme.Formatter) Volume {
+	return Volume{
+		Price:    format(f, v.Price),
+		Quantity: format(f, v.Quantity),
+	}
+}
+
+// Volume parses volume values with the factory
+func (v *Volume) Volume(factory fastme.OrderFactory) (r fastme.Volume, err error) {
+	if r.Price, err = factory.Value(v.Price); err != nil {
+		return
+	}
+
+	r.Quantity, err = factory.Value(v.Quantity)
+	return
+}
+
+// Marshal encodes the message
+func (v *Volume) Marshal() []byte {
+	b := appendString(nil, 1, v.Price)
+	return appendString(b, 2, v.Quantity)
+}
+
+// Unmarshal decodes the message
+func (v *Volume) Unmarshal(b []byte) error {
+	*v = Volume{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			v.Price, err = f.text()
+		case 2:
+			v.Quantity, err = f.text()
+		}
+		return
+	})
+}
+
+// Trade is the executed trade
+type Trade struct {
+	ID           uint64
+	MakerOrderID string
+	TakerOrderID string
+	Price        string
+	Quantity     string
+
+	// Timestamp is the execution time in Unix nanoseconds
+	Timestamp int64
+}
+
+// NewTrade converts the trade using given formatter
+func NewTrade(t fastme.Trade, f fastme.Formatter) Trade {
+	r := Trade{
+		ID:       t.ID,
+		Price:    format(f, t.Price),
+		Quantity: format(f, t.Quantity),
+	}
+
+	if t.MakerOrder != nil {
+		r.MakerOrderID = t.MakerOrder.ID()
+	}
+
+	if t.TakerOrder != nil {
+		r.TakerOrderID = t.TakerOrder.ID()
+	}
+
+	if !t.Timestamp.IsZero() {
+		r.Timestamp = t.Timestamp.UnixNano()
+	}
+
+	return r
+}
+
+// Marshal encodes the message
+func (t *Trade) Marshal() []byte {
+	b := appendUint(nil, 1, t.ID)
+	b = appendString(b, 2, t.MakerOrderID)
+	b = appendString(b, 3, t.TakerOrderID)
+	b = appendString(b, 4, t.Price)
+	b = appendString(b, 5, t.Quantity)
+	return appendUint(b, 6, uint64(t.Timestamp))
+}
+
+// Unmarshal decodes the message
+func (t *Trade) Unmarshal(b []byte) error {
+	*t = Trade{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			t.ID, err = f.uint()
+		case 2:
+			t.MakerOrderID, err = f.text()
+		case 3:
+			t.TakerOrderID, err = f.text()
+		case 4:
+			t.Price, err = f.text()
+		case 5:
+			t.Quantity, err = f.text()
+		case 6:
+			var v uint64
+			v, err = f.uint()
+			t.Timestamp = int64(v)
+		}
+		return
+	})
+}
+
+// Level is the order book price level. Volume and Count are set for market
+// data levels, Orders are set for snapshot levels
+type Level struct {
+	Price  string
+	Volume string
+	Count  uint32
+	Orders []Order
+}
+
+// Levels returns price levels of the order book using given formatter.
+// Asks and bids are sorted from the best price
+func Levels(e *fastme.Engine, f fastme.Formatter) (asks, bids []Level) {
+	e.OrderBook(func(ask bool, price, volume fastme.Value, count int) {
+		level := Level{
+			Price:  format(f, price),
+			Volume: format(f, volume),
+			Count:  uint32(count),
+		}
+
+		if ask {
+			asks = append(asks, level)
+		} else {
+			bids = append(bids, level)
+		}
+	})
+
+	// Asks are iterated from the highest price
+	for i, j := 0, len(asks)-1; i < j; i, j = i+1, j-1 {
+		asks[i], asks[j] = asks[j], asks[i]
+	}
+
+	return
+}
+
+// Marshal encodes the message
+func (l *Level) Marshal() []byte {
+	return l.append(nil)
+}
+
+func (l *Level) append(b []byte) []byte {
+	b = appendString(b, 1, l.Price)
+	b = appendString(b, 2, l.Volume)
+	b = appendUint(b, 3, uint64(l.Count))
+	for i := range l.Orders {
+		b = appendBytes(b, 4, l.Orders[i].Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes the message
+func (l *Level) Unmarshal(b []byte) error {
+	*l = Level{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			l.Price, err = f.text()
+		case 2:
+			l.Volume, err = f.text()
+		case 3:
+			var v uint64
+			v, err = f.uint()
+			l.Count = uint32(v)
+		case 4:
+			if f.typ != wireBytes {
+				return ErrInvalidWire
+			}
+
+			var o Order
+			if err = o.Unmarshal(f.bytes); err == nil {
+				l.Orders = append(l.Orders, o)
+			}
+		}
+		return
+	})
+}
+
+func format(f fastme.Formatter, v fastme.Value) string {
+	if v == nil {
+		return ""
+	}
+
+	if f == nil {
+		return v.Hash()
+	}
+
+	return f.Format(v)
+}
@@ -0,0 +1,139 @@
+package pb
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+type tValue float64
+
+func (t tValue) Add(n fastme.Value) fastme.Value { return t + t.checkNil(n) }
+func (t tValue) Sub(n fastme.Value) fastme.Value { return t - t.checkNil(n) }
+func (t tValue) Mul(n fastme.Value) fastme.Value { return t * t.checkNil(n) }
+func (t tValue) Hash() string                    { return strconv.FormatFloat(float64(t), 'f', -1, 64) }
+
+func (t tValue) Cmp(n fastme.Value) int {
+	switch v := t.checkNil(n); {
+	case t > v:
+		return 1
+	case t < v:
+		return -1
+	}
+	return 0
+}
+
+func (t tValue) Sign() int {
+	return t.Cmp(tValue(0))
+}
+
+func (t tValue) checkNil(v fastme.Value) tValue {
+	if v == nil {
+		return 0
+	}
+	return v.(tValue)
+}
+
+type tOrder struct {
+	id              string
+	sell            bool
+	price, quantity fastme.Value
+}
+
+func (t *tOrder) ID() string                    { return t.id }
+func (t *tOrder) Owner() fastme.Wallet          { return nil }
+func (t *tOrder) Sell() bool                    { return t.sell }
+func (t *tOrder) Price() fastme.Value           { return t.price }
+func (t *tOrder) Quantity() fastme.Value        { return t.quantity }
+func (t *tOrder) UpdateQuantity(v fastme.Value) { t.quantity = v }
+
+type tFactory struct{}
+
+func (tFactory) Value(s string) (fastme.Value, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	return tValue(v), err
+}
+
+func (tFactory) Order(ctx context.Context, r fastme.OrderRecord) (fastme.Order, error) {
+	return &tOrder{id: r.ID, sell: r.Sell, price: r.Price, quantity: r.Quantity}, nil
+}
+
+func TestTradeMarshal(t *testing.T) {
+	trade := NewTrade(fastme.Trade{
+		ID:         7,
+		MakerOrder: &tOrder{id: "1"},
+		TakerOrder: &tOrder{id: "2"},
+		Price:      tValue(10.5),
+		Quantity:   tValue(2),
+		Timestamp:  time.Unix(0, 42),
+	}, nil)
+
+	var decoded Trade
+	if err := decoded.Unmarshal(trade.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != trade ||
+		decoded.Price != "10.5" ||
+		decoded.MakerOrderID != "1" ||
+		decoded.Timestamp != 42 {
+		t.Fatal("invalid result", decoded)
+	}
+
+	if err := decoded.Unmarshal([]byte{0x0a, 0x05, 'a'}); err != ErrInvalidWire {
+		t.Fatal("truncated message must be rejected")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		engine   = fastme.NewEngine("apples", "dollars")
+		restored = fastme.NewEngine("apples", "dollars")
+	)
+
+	engine.PushOrder(ctx, &tOrder{id: "1", sell: true, price: tValue(11), quantity: tValue(1)})
+	engine.PushOrder(ctx, &tOrder{id: "2", sell: true, price: tValue(11), quantity: tValue(2)})
+	engine.PushOrder(ctx, &tOrder{id: "3", sell: false, price: tValue(9), quantity: tValue(3)})
+
+	s, err := NewSnapshot(ctx, engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Snapshot
+	if err := decoded.Unmarshal(s.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded.Asks) != 1 ||
+		len(decoded.Asks[0].Orders) != 2 ||
+		decoded.Asks[0].Orders[1].ID != "2" ||
+		len(decoded.Bids) != 1 ||
+		decoded.Bids[0].Orders[0].Quantity != "3" {
+		t.Fatal("invalid result", decoded)
+	}
+
+	if err := decoded.Restore(ctx, restored, tFactory{}); err != nil {
+		t.Fatal(err)
+	}
+
+	asks, bids := Levels(restored, nil)
+	if len(asks) != 1 ||
+		asks[0].Price != "11" ||
+		asks[0].Volume != "3" ||
+		asks[0].Count != 2 ||
+		len(bids) != 1 ||
+		bids[0].Volume != "3" {
+		t.Fatal("invalid restored levels", asks, bids)
+	}
+
+	var buf bytes.Buffer
+	if err := restored.Snapshot(ctx, &buf); err != nil || buf.Len() == 0 {
+		t.Fatal("invalid restored snapshot", err)
+	}
+}
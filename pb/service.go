@@ -0,0 +1,296 @@
+package pb
+
+import "github.com/newity/fastme"
+
+// PlaceOrderRequest is the request of OrderEntry.PlaceOrder
+type PlaceOrderRequest struct {
+	WalletID string
+	Order    Order
+}
+
+// Marshal encodes the message
+func (r *PlaceOrderRequest) Marshal() []byte {
+	b := appendString(nil, 1, r.WalletID)
+	return appendBytes(b, 2, r.Order.Marshal())
+}
+
+// Unmarshal decodes the message
+func (r *PlaceOrderRequest) Unmarshal(b []byte) error {
+	*r = PlaceOrderRequest{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			r.WalletID, err = f.text()
+		case 2:
+			err = f.message(&r.Order)
+		}
+		return
+	})
+}
+
+// ReplaceOrderRequest is the request of OrderEntry.ReplaceOrder
+type ReplaceOrderRequest struct {
+	WalletID string
+	OrderID  string
+	Order    Order
+}
+
+// Marshal encodes the message
+func (r *ReplaceOrderRequest) Marshal() []byte {
+	b := appendString(nil, 1, r.WalletID)
+	b = appendString(b, 2, r.OrderID)
+	return appendBytes(b, 3, r.Order.Marshal())
+}
+
+// Unmarshal decodes the message
+func (r *ReplaceOrderRequest) Unmarshal(b []byte) error {
+	*r = ReplaceOrderRequest{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			r.WalletID, err = f.text()
+		case 2:
+			r.OrderID, err = f.text()
+		case 3:
+			err = f.message(&r.Order)
+		}
+		return
+	})
+}
+
+// CancelOrderRequest is the request of OrderEntry.CancelOrder
+type CancelOrderRequest struct {
+	WalletID string
+	OrderID  string
+}
+
+// Marshal encodes the message
+func (r *CancelOrderRequest) Marshal() []byte {
+	b := appendString(nil, 1, r.WalletID)
+	return appendString(b, 2, r.OrderID)
+}
+
+// Unmarshal decodes the message
+func (r *CancelOrderRequest) Unmarshal(b []byte) error {
+	*r = CancelOrderRequest{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			r.WalletID, err = f.text()
+		case 2:
+			r.OrderID, err = f.text()
+		}
+		return
+	})
+}
+
+// CancelOrderResponse is the empty response of OrderEntry.CancelOrder
+type CancelOrderResponse struct{}
+
+// Marshal encodes the message
+func (r *CancelOrderResponse) Marshal() []byte {
+	return nil
+}
+
+// Unmarshal decodes the message
+func (r *CancelOrderResponse) Unmarshal(b []byte) error {
+	return fields(b, func(field) error { return nil })
+}
+
+// Fill is the match against resting order
+type Fill struct {
+	MakerOrderID string
+	Price        string
+	Quantity     string
+}
+
+// Marshal encodes the message
+func (m *Fill) Marshal() []byte {
+	b := appendString(nil, 1, m.MakerOrderID)
+	b = appendString(b, 2, m.Price)
+	return appendString(b, 3, m.Quantity)
+}
+
+// Unmarshal decodes the message
+func (m *Fill) Unmarshal(b []byte) error {
+	*m = Fill{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.MakerOrderID, err = f.text()
+		case 2:
+			m.Price, err = f.text()
+		case 3:
+			m.Quantity, err = f.text()
+		}
+		return
+	})
+}
+
+// OrderReport is the response of OrderEntry.PlaceOrder and ReplaceOrder
+type OrderReport struct {
+	Fills     []Fill
+	Volume    Volume
+	Remaining string
+
+	// Status is fastme.OrderStatus, e.g. "placed"
+	Status string
+
+	// Error is set if the order is accepted, but matching is interrupted,
+	// e.g. by the circuit breaker
+	Error string
+}
+
+// NewOrderReport converts the report using given formatter
+func NewOrderReport(r fastme.Report, f fastme.Formatter) OrderReport {
+	report := OrderReport{
+		Volume:    NewVolume(r.Volume, f),
+		Remaining: format(f, r.Remaining),
+		Status:    r.Status.String(),
+	}
+
+	for _, fill := range r.Fills {
+		report.Fills = append(report.Fills, Fill{
+			MakerOrderID: fill.MakerID,
+			Price:        format(f, fill.Price),
+			Quantity:     format(f, fill.Quantity),
+		})
+	}
+
+	return report
+}
+
+// Marshal encodes the message
+func (r *OrderReport) Marshal() []byte {
+	var b []byte
+	for i := range r.Fills {
+		b = appendBytes(b, 1, r.Fills[i].Marshal())
+	}
+	b = appendBytes(b, 2, r.Volume.Marshal())
+	b = appendString(b, 3, r.Remaining)
+	b = appendString(b, 4, r.Status)
+	return appendString(b, 5, r.Error)
+}
+
+// Unmarshal decodes the message
+func (r *OrderReport) Unmarshal(b []byte) error {
+	*r = OrderReport{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			var fill Fill
+			if err = f.message(&fill); err == nil {
+				r.Fills = append(r.Fills, fill)
+			}
+		case 2:
+			err = f.message(&r.Volume)
+		case 3:
+			r.Remaining, err = f.text()
+		case 4:
+			r.Status, err = f.text()
+		case 5:
+			r.Error, err = f.text()
+		}
+		return
+	})
+}
+
+// SubscribeRequest is the request of MarketData.Subscribe
+type SubscribeRequest struct {
+	// Depth is the number of levels per side of the initial book, 0 for all
+	Depth uint32
+}
+
+// Marshal encodes the message
+func (r *SubscribeRequest) Marshal() []byte {
+	return appendUint(nil, 1, uint64(r.Depth))
+}
+
+// Unmarshal decodes the message
+func (r *SubscribeRequest) Unmarshal(b []byte) error {
+	*r = SubscribeRequest{}
+	return fields(b, func(f field) (err error) {
+		if f.num == 1 {
+			var v uint64
+			v, err = f.uint()
+			r.Depth = uint32(v)
+		}
+		return
+	})
+}
+
+// Book is the order book sent first on subscription, asks and bids are
+// sorted from the best price
+type Book struct {
+	Asks []Level
+	Bids []Level
+}
+
+// Marshal encodes the message
+func (m *Book) Marshal() []byte {
+	var b []byte
+	for i := range m.Asks {
+		b = appendBytes(b, 1, m.Asks[i].Marshal())
+	}
+	for i := range m.Bids {
+		b = appendBytes(b, 2, m.Bids[i].Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *Book) Unmarshal(b []byte) error {
+	*m = Book{}
+	return fields(b, func(f field) (err error) {
+		var l Level
+		switch f.num {
+		case 1:
+			if err = f.message(&l); err == nil {
+				m.Asks = append(m.Asks, l)
+			}
+		case 2:
+			if err = f.message(&l); err == nil {
+				m.Bids = append(m.Bids, l)
+			}
+		}
+		return
+	})
+}
+
+// MarketDataEvent is the message of MarketData.Subscribe stream, exactly
+// one of Book and Trade is set
+type MarketDataEvent struct {
+	Book  *Book
+	Trade *Trade
+}
+
+// Marshal encodes the message
+func (e *MarketDataEvent) Marshal() []byte {
+	switch {
+	case e.Book != nil:
+		return appendBytes(nil, 1, e.Book.Marshal())
+	case e.Trade != nil:
+		return appendBytes(nil, 2, e.Trade.Marshal())
+	}
+	return nil
+}
+
+// Unmarshal decodes the message, the last event of the oneof wins
+func (e *MarketDataEvent) Unmarshal(b []byte) error {
+	*e = MarketDataEvent{}
+	return fields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			book := new(Book)
+			if err = f.message(book); err == nil {
+				e.Book, e.Trade = book, nil
+			}
+		case 2:
+			trade := new(Trade)
+			if err = f.message(trade); err == nil {
+				e.Book, e.Trade = nil, trade
+			}
+		}
+		return
+	})
+}
@@ -0,0 +1,54 @@
+package pb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/newity/fastme"
+)
+
+func TestServiceMessages(t *testing.T) {
+	report := NewOrderReport(fastme.Report{
+		Fills: []fastme.Fill{
+			{MakerID: "1", Price: tValue(10), Quantity: tValue(1)},
+			{MakerID: "2", Price: tValue(11), Quantity: tValue(2)},
+		},
+		Volume:    fastme.Volume{Price: tValue(32), Quantity: tValue(3)},
+		Remaining: tValue(0.5),
+		Status:    fastme.StatusPlaced,
+	}, nil)
+	report.Error = "interrupted"
+
+	if report.Status != "placed" || report.Remaining != "0.5" || report.Fills[1].Price != "11" {
+		t.Fatal("invalid report", report)
+	}
+
+	for _, c := range []struct {
+		msg, decoded interface {
+			Marshal() []byte
+			Unmarshal([]byte) error
+		}
+	}{
+		{&PlaceOrderRequest{WalletID: "w", Order: Order{ID: "1", Sell: true, Price: "10", Quantity: "2"}}, new(PlaceOrderRequest)},
+		{&ReplaceOrderRequest{WalletID: "w", OrderID: "1", Order: Order{ID: "2", Price: "9", Quantity: "1"}}, new(ReplaceOrderRequest)},
+		{&CancelOrderRequest{WalletID: "w", OrderID: "1"}, new(CancelOrderRequest)},
+		{&CancelOrderResponse{}, new(CancelOrderResponse)},
+		{&report, new(OrderReport)},
+		{&SubscribeRequest{Depth: 5}, new(SubscribeRequest)},
+		{&MarketDataEvent{Book: &Book{Asks: []Level{{Price: "10", Volume: "1", Count: 1}}}}, new(MarketDataEvent)},
+		{&MarketDataEvent{Trade: &Trade{ID: 1, MakerOrderID: "1", Price: "10"}}, new(MarketDataEvent)},
+	} {
+		if err := c.decoded.Unmarshal(c.msg.Marshal()); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(c.decoded, c.msg) {
+			t.Fatal("invalid result", c.decoded, c.msg)
+		}
+	}
+
+	var req PlaceOrderRequest
+	if err := req.Unmarshal([]byte{0x10, 0x01}); err != ErrInvalidWire {
+		t.Fatal("embedded message of invalid wire type must be rejected", err)
+	}
+}
@@ -0,0 +1,201 @@
+package pb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/newity/fastme"
+)
+
+// Snapshot is the order book snapshot. It carries the same data as the
+// JSON snapshot of the engine
+type Snapshot struct {
+	Version   uint32
+	Base      string
+	Quote     string
+	State     uint32
+	TradeSeq  uint64
+	LastPrice string
+	Asks      []Level
+	Bids      []Level
+}
+
+// jsonSnapshot mirrors JSON representation of fastme.Engine.Snapshot
+type jsonSnapshot struct {
+	Version   uint32      `json:"version"`
+	Base      string      `json:"base"`
+	Quote     string      `json:"quote"`
+	State     uint32      `json:"state"`
+	TradeSeq  uint64      `json:"trade_seq"`
+	LastPrice *string     `json:"last_price,omitempty"`
+	Asks      []jsonLevel `json:"asks"`
+	Bids      []jsonLevel `json:"bids"`
+}
+
+type jsonLevel struct {
+	Price  string      `json:"price"`
+	Orders []jsonOrder `json:"orders"`
+}
+
+type jsonOrder struct {
+	ID       string `json:"id"`
+	Quantity string `json:"quantity"`
+}
+
+// NewSnapshot takes the snapshot of the engine
+func NewSnapshot(ctx context.Context, e *fastme.Engine) (*Snapshot, error) {
+	var buf bytes.Buffer
+	if err := e.Snapshot(ctx, &buf); err != nil {
+		return nil, err
+	}
+
+	var js jsonSnapshot
+	if err := json.Unmarshal(buf.Bytes(), &js); err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{
+		Version:  js.Version,
+		Base:     js.Base,
+		Quote:    js.Quote,
+		State:    js.State,
+		TradeSeq: js.TradeSeq,
+		Asks:     fromJSONLevels(js.Asks, true),
+		Bids:     fromJSONLevels(js.Bids, false),
+	}
+
+	if js.LastPrice != nil {
+		s.LastPrice = *js.LastPrice
+	}
+
+	return s, nil
+}
+
+// Restore replaces the order book of the engine with the snapshot
+func (s *Snapshot) Restore(
+	ctx context.Context,
+	e *fastme.Engine,
+	factory fastme.OrderFactory,
+) error {
+	js := jsonSnapshot{
+		Version:  s.Version,
+		Base:     s.Base,
+		Quote:    s.Quote,
+		State:    s.State,
+		TradeSeq: s.TradeSeq,
+		Asks:     toJSONLevels(s.Asks),
+		Bids:     toJSONLevels(s.Bids),
+	}
+
+	if s.LastPrice != "" {
+		js.LastPrice = &s.LastPrice
+	}
+
+	data, err := json.Marshal(js)
+	if err != nil {
+		return err
+	}
+
+	return e.Restore(ctx, bytes.NewReader(data), factory)
+}
+
+func fromJSONLevels(levels []jsonLevel, sell bool) []Level {
+	res := make([]Level, 0, len(levels))
+	for _, l := range levels {
+		level := Level{
+			Price:  l.Price,
+			Count:  uint32(len(l.Orders)),
+			Orders: make([]Order, 0, len(l.Orders)),
+		}
+
+		for _, o := range l.Orders {
+			level.Orders = append(level.Orders, Order{
+				ID:       o.ID,
+				Sell:     sell,
+				Price:    l.Price,
+				Quantity: o.Quantity,
+			})
+		}
+
+		res = append(res, level)
+	}
+	return res
+}
+
+func toJSONLevels(levels []Level) []jsonLevel {
+	res := make([]jsonLevel, 0, len(levels))
+	for _, l := range levels {
+		level := jsonLevel{
+			Price:  l.Price,
+			Orders: make([]jsonOrder, 0, len(l.Orders)),
+		}
+
+		for _, o := range l.Orders {
+			level.Orders = append(level.Orders, jsonOrder{
+				ID:       o.ID,
+				Quantity: o.Quantity,
+			})
+		}
+
+		res = append(res, level)
+	}
+	return res
+}
+
+// Marshal encodes the message
+func (s *Snapshot) Marshal() []byte {
+	b := appendUint(nil, 1, uint64(s.Version))
+	b = appendString(b, 2, s.Base)
+	b = appendString(b, 3, s.Quote)
+	b = appendUint(b, 4, uint64(s.State))
+	b = appendUint(b, 5, s.TradeSeq)
+	b = appendString(b, 6, s.LastPrice)
+	for i := range s.Asks {
+		b = appendBytes(b, 7, s.Asks[i].Marshal())
+	}
+	for i := range s.Bids {
+		b = appendBytes(b, 8, s.Bids[i].Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes the message
+func (s *Snapshot) Unmarshal(b []byte) error {
+	*s = Snapshot{}
+	return fields(b, func(f field) (err error) {
+		var v uint64
+		switch f.num {
+		case 1:
+			v, err = f.uint()
+			s.Version = uint32(v)
+		case 2:
+			s.Base, err = f.text()
+		case 3:
+			s.Quote, err = f.text()
+		case 4:
+			v, err = f.uint()
+			s.State = uint32(v)
+		case 5:
+			s.TradeSeq, err = f.uint()
+		case 6:
+			s.LastPrice, err = f.text()
+		case 7, 8:
+			if f.typ != wireBytes {
+				return ErrInvalidWire
+			}
+
+			var l Level
+			if err = l.Unmarshal(f.bytes); err != nil {
+				return
+			}
+
+			if f.num == 7 {
+				s.Asks = append(s.Asks, l)
+			} else {
+				s.Bids = append(s.Bids, l)
+			}
+		}
+		return
+	})
+}
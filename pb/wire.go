@@ -0,0 +1,137 @@
+package pb
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidWire is returned on malformed protobuf data
+var ErrInvalidWire = errors.New("Invalid protobuf wire data")
+
+// Protobuf wire types
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireBytes  = 2
+	wireI32    = 5
+)
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendTag(b []byte, num, typ int) []byte {
+	return appendUvarint(b, uint64(num)<<3|uint64(typ))
+}
+
+func appendUint(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, num, wireVarint)
+	return appendUvarint(b, v)
+}
+
+func appendBool(b []byte, num int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendUint(b, num, 1)
+}
+
+func appendString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendBytes(b, num, []byte(s))
+}
+
+func appendBytes(b []byte, num int, v []byte) []byte {
+	b = appendTag(b, num, wireBytes)
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// field is the decoded protobuf field
+type field struct {
+	num   int
+	typ   int
+	value uint64
+	bytes []byte
+}
+
+// fields iterates over the message fields
+func fields(b []byte, fn func(field) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return ErrInvalidWire
+		}
+		b = b[n:]
+
+		f := field{num: int(tag >> 3), typ: int(tag & 7)}
+		if f.num <= 0 {
+			return ErrInvalidWire
+		}
+
+		switch f.typ {
+		case wireVarint:
+			if f.value, n = binary.Uvarint(b); n <= 0 {
+				return ErrInvalidWire
+			}
+			b = b[n:]
+
+		case wireI64:
+			if len(b) < 8 {
+				return ErrInvalidWire
+			}
+			f.value, b = binary.LittleEndian.Uint64(b), b[8:]
+
+		case wireI32:
+			if len(b) < 4 {
+				return ErrInvalidWire
+			}
+			f.value, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return ErrInvalidWire
+			}
+			f.bytes, b = b[n:n+int(size)], b[n+int(size):]
+
+		default:
+			return ErrInvalidWire
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// text returns string value of the length-delimited field
+func (f field) text() (string, error) {
+	if f.typ != wireBytes {
+		return "", ErrInvalidWire
+	}
+	return string(f.bytes), nil
+}
+
+// uint returns value of the varint field
+func (f field) uint() (uint64, error) {
+	if f.typ != wireVarint {
+		return 0, ErrInvalidWire
+	}
+	return f.value, nil
+}
+
+// message decodes the embedded message of the length-delimited field
+func (f field) message(m interface{ Unmarshal([]byte) error }) error {
+	if f.typ != wireBytes {
+		return ErrInvalidWire
+	}
+	return m.Unmarshal(f.bytes)
+}
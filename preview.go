@@ -0,0 +1,85 @@
+package fastme
+
+import "context"
+
+// Preview is the expected execution of the order against the current order book
+type Preview struct {
+	// Fills are expected fills in the matching order
+	Fills []Fill
+
+	// Volume is the total executed volume, use AvgPrice to get the average price
+	Volume Volume
+
+	// Remaining is the quantity left unfilled
+	Remaining Value
+}
+
+// Preview simulates matching of the order against the current order book
+// without changing any state. Funds, risk checks, maker protection and
+// strict funds cancellations are not simulated, so the actual execution may
+// differ. The circuit breaker band stops the simulation as it stops
+// matching. Nothing is matched in the StateAuction trading state
+func (e *Engine) Preview(ctx context.Context, o Order) (Preview, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	p := Preview{Remaining: o.Quantity()}
+
+	if o.Quantity() == nil || o.Quantity().Sign() <= 0 {
+		return p, invalidQuantity(o.Quantity(), ErrInvalidQuantity)
+	}
+
+	if err := checkKind(o); err != nil {
+		return p, err
+	}
+
+	market := isMarket(o)
+	if !market && (o.Price() == nil || o.Price().Sign() < 0) {
+		return p, invalidPrice(o.Price(), ErrInvalidPrice)
+	}
+
+	if e.state == StateAuction {
+		return p, nil
+	}
+
+	var (
+		level *queue
+		iter  func(Value) *queue
+		band  = e.priceBand(e.eventTime(ctx))
+	)
+
+	if o.Sell() {
+		level, iter = e.bids.maxPrice(), e.bids.lessThan
+	} else {
+		level, iter = e.asks.minPrice(), e.asks.greaterThan
+	}
+
+	for ; level != nil && p.Remaining.Sign() > 0; level = iter(level.price) {
+		if !market && (o.Sell() && level.price.Cmp(o.Price()) < 0 ||
+			!o.Sell() && level.price.Cmp(o.Price()) > 0) {
+			break
+		}
+
+		if band != nil && !band.contains(level.price) {
+			break
+		}
+
+		for el := level.orders.Front(); el != nil && p.Remaining.Sign() > 0; el = el.Next() {
+			maker := el.Value.(Order)
+
+			quantity := minValue(maker.Quantity(), p.Remaining)
+			p.Remaining = p.Remaining.Sub(quantity)
+
+			p.Fills = append(p.Fills, Fill{
+				MakerID:  maker.ID(),
+				Price:    level.price,
+				Quantity: quantity,
+			})
+
+			p.Volume.Price = level.price.Mul(quantity).Add(p.Volume.Price)
+			p.Volume.Quantity = quantity.Add(p.Volume.Quantity)
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,59 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 2, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet1, true, 2, 15)))
+
+	p, err := engine.Preview(context.Background(), newOrder("5", wallet2, false, 4, 12))
+	assertErr(t, err)
+
+	if len(p.Fills) != 3 ||
+		p.Fills[0].MakerID != "1" || p.Fills[1].MakerID != "2" || p.Fills[2].MakerID != "3" ||
+		p.Fills[2].Quantity.Cmp(tFloat64(1)) != 0 {
+		t.Fatal("invalid preview fills", p.Fills)
+	}
+
+	if p.Volume.Price.Cmp(tFloat64(42)) != 0 || p.Volume.Quantity.Cmp(tFloat64(4)) != 0 || p.Remaining.Sign() != 0 {
+		t.Fatal("invalid preview volume", p.Volume, p.Remaining)
+	}
+
+	// Limit price stops the simulation
+	p, err = engine.Preview(context.Background(), newOrder("5", wallet2, false, 10, 12))
+	assertErr(t, err)
+
+	if len(p.Fills) != 3 || p.Remaining.Cmp(tFloat64(5)) != 0 {
+		t.Fatal("limit price must be respected", p.Fills, p.Remaining)
+	}
+
+	// Market order goes through the book
+	p, err = engine.Preview(context.Background(), newOrder("5", wallet2, false, 10, 0))
+	assertErr(t, err)
+
+	if len(p.Fills) != 4 || p.Remaining.Cmp(tFloat64(3)) != 0 {
+		t.Fatal("market order must walk the book", p.Fills, p.Remaining)
+	}
+
+	if _, err := engine.Preview(context.Background(), newOrder("5", wallet2, false, 0, 10)); err == nil {
+		t.Fatal("invalid quantity must be rejected")
+	}
+
+	if o, err := engine.FindOrder("3"); err != nil || o.Quantity().Cmp(tFloat64(2)) != 0 {
+		t.Fatal("preview must not change the order book")
+	}
+}
@@ -0,0 +1,104 @@
+package fastme
+
+import (
+	"context"
+	"time"
+)
+
+// MakerProtection limits how often market maker quotes may be hit. When the
+// limit is exceeded all resting orders of the maker are canceled
+type MakerProtection struct {
+	// Fills is the maximum number of fills within the window, 0 disables the check
+	Fills int
+
+	// Quantity is the maximum filled quantity within the window, nil disables the check
+	Quantity Value
+
+	// Window is the period fills are counted in
+	Window time.Duration
+}
+
+// ProtectionListener is an optional EventListener extension informing about
+// triggered market maker protection. It's called after maker orders are canceled
+type ProtectionListener interface {
+	OnMakerProtectionTriggered(context.Context, Wallet)
+}
+
+type makerFill struct {
+	at       time.Time
+	quantity Value
+}
+
+type makerGuard struct {
+	MakerProtection
+	fills []makerFill
+}
+
+// ProtectMaker registers market maker protection for the wallet, nil removes it
+func (e *Engine) ProtectMaker(w Wallet, p *MakerProtection) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if p == nil {
+		delete(e.protected, w)
+		return
+	}
+
+	if e.protected == nil {
+		e.protected = make(map[Wallet]*makerGuard)
+	}
+
+	e.protected[w] = &makerGuard{MakerProtection: *p}
+}
+
+// recordMakerFill registers the maker fill and returns true if the
+// protection of the maker has been triggered
+func (e *Engine) recordMakerFill(now time.Time, w Wallet, quantity Value) bool {
+	g, ok := e.protected[w]
+	if !ok {
+		return false
+	}
+
+	var (
+		total    Value
+		startIdx int
+	)
+
+	for startIdx < len(g.fills) && now.Sub(g.fills[startIdx].at) > g.Window {
+		startIdx++
+	}
+
+	g.fills = append(g.fills[startIdx:], makerFill{at: now, quantity: quantity})
+
+	if g.Fills > 0 && len(g.fills) > g.Fills {
+		g.fills = g.fills[:0]
+		return true
+	}
+
+	if g.Quantity != nil {
+		for _, f := range g.fills {
+			total = f.quantity.Add(total)
+		}
+
+		if total.Cmp(g.Quantity) > 0 {
+			g.fills = g.fills[:0]
+			return true
+		}
+	}
+
+	return false
+}
+
+// pullMaker cancels all resting orders of the triggered maker. It's safe to
+// call it during matching as the current queue element is already processed
+func (e *Engine) pullMaker(
+	ctx context.Context,
+	listener EventListener,
+	w Wallet,
+) {
+	e.cancelAll(ctx, listener, w, e.asks, e.bids)
+
+	if l, ok := listener.(ProtectionListener); ok {
+		l.OnMakerProtectionTriggered(ctx, w)
+	}
+}
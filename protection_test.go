@@ -0,0 +1,89 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type tProtectionListener struct {
+	*tEventListener
+	triggered []Wallet
+}
+
+func (t *tProtectionListener) OnMakerProtectionTriggered(ctx context.Context, w Wallet) {
+	t.triggered = append(t.triggered, w)
+}
+
+func TestMakerProtection(t *testing.T) {
+	var (
+		processor        = &tProtectionListener{tEventListener: newEventListener()}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	engine.ProtectMaker(wallet1, &MakerProtection{Fills: 2, Window: time.Minute})
+
+	for i, price := range []float64{10, 11, 12, 13} {
+		assertErr(t, engine.PlaceOrder(
+			context.Background(),
+			processor,
+			newOrder(string(rune('a'+i)), wallet1, true, 1, price),
+		))
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet2, false, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet2, false, 1, 11)))
+
+	if len(processor.triggered) != 0 || len(engine.Orders()) != 2 {
+		t.Fatal("protection must not be triggered yet")
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet2, false, 1, 12)))
+
+	if len(processor.triggered) != 1 ||
+		processor.triggered[0] != wallet1 ||
+		len(engine.Orders()) != 0 ||
+		walletBalance(wallet1, asset1) != 7 ||
+		walletInOrder(wallet1, asset1) != 0 {
+		t.Fatal("maker quotes must be pulled")
+	}
+}
+
+func TestMakerProtectionQuantity(t *testing.T) {
+	var (
+		processor        = &tProtectionListener{tEventListener: newEventListener()}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	engine.ProtectMaker(wallet1, &MakerProtection{Quantity: tFloat64(3), Window: time.Minute})
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet1, true, 2, 20)))
+
+	// Sweep stops hitting the maker once the protection is triggered
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet2, false, 6, 20)))
+
+	if len(processor.triggered) != 1 ||
+		walletBalance(wallet2, asset1) != 4 ||
+		len(engine.Orders()) != 1 {
+		t.Fatal("invalid result")
+	}
+
+	engine.ProtectMaker(wallet1, nil)
+	if len(engine.protected) != 0 {
+		t.Fatal("protection must be removed")
+	}
+}
@@ -0,0 +1,98 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRateLimited is returned when the order flow exceeds the rate limit
+var ErrRateLimited = errors.New("Order rate limit exceeded")
+
+// RateLimit is the token bucket limiting the order flow per wallet. Every
+// placement, replacement, amendment and cancellation takes a token. Tokens
+// are refilled at Rate per second up to Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type rateKey struct{}
+
+// WithRateKey returns context keying the rate limit of the command by the
+// given key instead of the order owner, e.g. by the session or the API key.
+// The key must be comparable
+func WithRateKey(ctx context.Context, key interface{}) context.Context {
+	return context.WithValue(ctx, rateKey{}, key)
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+type rateLimiter struct {
+	RateLimit
+	buckets map[interface{}]*bucket
+}
+
+// SetRateLimit sets the order flow rate limit, nil disables it
+func (e *Engine) SetRateLimit(l *RateLimit) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if l == nil {
+		e.limiter = nil
+		return
+	}
+
+	e.limiter = &rateLimiter{
+		RateLimit: *l,
+		buckets:   make(map[interface{}]*bucket),
+	}
+}
+
+// checkRate takes the token of the command from the bucket of the owner or
+// the context key. Journaled commands are replayed without limits
+func (e *Engine) checkRate(ctx context.Context, owner Wallet) error {
+	if e.limiter == nil || replaying(ctx) {
+		return nil
+	}
+
+	var key interface{} = owner
+	if k := ctx.Value(rateKey{}); k != nil {
+		key = k
+	}
+
+	if !e.limiter.take(key, e.eventTime(ctx)) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// take refills the bucket of the key and takes the token
+func (l *rateLimiter) take(key interface{}, now time.Time) bool {
+	burst := float64(l.Burst)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, at: now}
+		l.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.at); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * l.Rate
+		b.at = now
+	}
+
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
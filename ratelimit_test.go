@@ -0,0 +1,51 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		now              = time.Unix(100, 0)
+
+		engine = NewEngine(asset1, asset2, WithRateLimit(&RateLimit{Rate: 1, Burst: 2}))
+	)
+
+	engine.SetClock(ClockFunc(func() time.Time { return now }))
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 1, 11)))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 12)); err != ErrRateLimited {
+		t.Fatal("placement must be limited", err)
+	}
+
+	if err := engine.CancelOrderByID(context.Background(), nil, "1"); err != ErrRateLimited {
+		t.Fatal("cancellation must be limited", err)
+	}
+
+	// Buckets are kept per wallet
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, true, 1, 10)))
+
+	// Tokens are refilled over time
+	now = now.Add(time.Second)
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+
+	if err := engine.CancelOrderByID(context.Background(), nil, "2"); err != ErrRateLimited {
+		t.Fatal("cancellation must be limited", err)
+	}
+
+	// Context key overrides the wallet
+	ctx := WithRateKey(context.Background(), "session")
+	assertErr(t, engine.CancelOrderByID(ctx, nil, "2"))
+
+	engine.SetRateLimit(nil)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 12)))
+}
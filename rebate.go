@@ -0,0 +1,52 @@
+package fastme
+
+import "context"
+
+// RebateListener is an optional EventListener extension informing about
+// rebates paid from the rebate wallet
+type RebateListener interface {
+	OnRebatePaid(ctx context.Context, o Order, a Asset, v Value)
+}
+
+// SetRebateWallet sets the wallet paying rebates. FeeHandler returning more
+// than the credited value pays the rebate: the difference is debited from
+// the rebate wallet. The rebate is dropped and the credited value is paid
+// only if the wallet is not set or its balance doesn't cover the rebate
+func (e *Engine) SetRebateWallet(w Wallet) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.rebates = w
+}
+
+// rebate debits the rebate wallet for out exceeding in. Returns the value
+// to credit to the order owner
+func (e *Engine) rebate(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+	asset Asset,
+	in, out Value,
+) Value {
+	rebate := out.Sub(in)
+	if rebate.Sign() <= 0 {
+		return out
+	}
+
+	if e.rebates == nil ||
+		balanceOf(ctx, e.rebates, asset).Cmp(rebate) < 0 ||
+		!e.debit(ctx, listener, e.rebates, asset, rebate) {
+		e.log(ctx, LogWarn, "rebate is not covered",
+			LogField{"order_id", o.ID()},
+			LogField{"asset", asset},
+			LogField{"rebate", e.format(rebate)},
+		)
+		return in
+	}
+
+	if l, ok := listener.(RebateListener); ok {
+		l.OnRebatePaid(ctx, o, asset, rebate)
+	}
+
+	return out
+}
@@ -0,0 +1,79 @@
+package fastme
+
+import "context"
+
+// ReducedListener is an optional EventListener extension informing about
+// resting orders reduced by ReduceOrder
+type ReducedListener interface {
+	OnExistingOrderReduced(ctx context.Context, o Order, reduced Value)
+}
+
+// ReduceOrder decreases the quantity of the resting order in place. The
+// order keeps its queue priority and the funds reserved for the reduced
+// quantity are refunded to the owner. Returns ErrOrderNotFound if the order
+// is not in the order book and InvalidQuantityError if the quantity is not
+// positive or not below the current one, use CancelOrderByID to cancel the
+// order completely
+func (e *Engine) ReduceOrder(
+	ctx context.Context,
+	listener EventListener,
+	id string,
+	quantity Value,
+) (err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	if err := e.unusable(); err != nil {
+		return err
+	}
+
+	el, ok := e.orders[id]
+	if !ok {
+		return ErrOrderNotFound
+	}
+
+	o := el.Value.(Order)
+	if quantity == nil || quantity.Sign() <= 0 || quantity.Cmp(o.Quantity()) >= 0 {
+		return invalidQuantity(quantity, ErrInvalidQuantity)
+	}
+
+	if err := e.checkSpec(quantity, o.Price()); err != nil {
+		return err
+	}
+
+	if err := e.checkRate(ctx, o.Owner()); err != nil {
+		return err
+	}
+
+	if err := e.recordReduce(ctx, id, quantity); err != nil {
+		return err
+	}
+
+	listener = e.listenerOf(listener)
+
+	var (
+		reduced = o.Quantity().Sub(quantity)
+		asset   = e.base
+		refund  = reduced
+		level   = e.bids.level(o.Price())
+	)
+
+	if o.Sell() {
+		level = e.asks.level(o.Price())
+	} else {
+		asset = e.quote
+		refund = o.Price().Mul(reduced)
+	}
+
+	level.updateQuantity(ctx, el, quantity)
+	e.release(ctx, listener, o.Owner(), asset, refund)
+
+	if l, ok := listener.(ReducedListener); ok {
+		l.OnExistingOrderReduced(ctx, o, reduced)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type tReducedListener struct {
+	*tEventListener
+	reduced []Value
+}
+
+func (t *tReducedListener) OnExistingOrderReduced(ctx context.Context, o Order, reduced Value) {
+	t.reduced = append(t.reduced, reduced)
+}
+
+func TestReduceOrder(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		listener         = &tReducedListener{tEventListener: newEventListener()}
+		buf              bytes.Buffer
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	engine.SetJournal(NewJournal(&buf))
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 3, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 5, 9)))
+
+	assertErr(t, engine.ReduceOrder(context.Background(), listener, "1", tFloat64(1)))
+	assertErr(t, engine.ReduceOrder(context.Background(), listener, "3", tFloat64(2)))
+
+	if len(listener.reduced) != 2 || listener.reduced[0].Cmp(tFloat64(2)) != 0 {
+		t.Fatal("reduction must be reported", listener.reduced)
+	}
+
+	if p, err := engine.OrderPosition("1"); err != nil || p.Position != 0 {
+		t.Fatal("reduced order must keep priority", p, err)
+	}
+
+	if walletBalance(wallet1, asset1) != 6 || walletInOrder(wallet1, asset1) != 4 ||
+		walletBalance(wallet2, asset2) != 82 || walletInOrder(wallet2, asset2) != 18 {
+		t.Fatal("reduced funds must be refunded",
+			walletBalance(wallet1, asset1), walletInOrder(wallet1, asset1),
+			walletBalance(wallet2, asset2), walletInOrder(wallet2, asset2))
+	}
+
+	if err := engine.ReduceOrder(context.Background(), nil, "1", tFloat64(1)); !errors.Is(err, ErrInvalidQuantity) {
+		t.Fatal("quantity must be reduced", err)
+	}
+
+	if err := engine.ReduceOrder(context.Background(), nil, "4", tFloat64(1)); err != ErrOrderNotFound {
+		t.Fatal("unknown order must be rejected", err)
+	}
+
+	wallet3, wallet4 := newWallet(), newWallet()
+	updateWalletBalance(wallet3, asset1, 10)
+	updateWalletBalance(wallet4, asset2, 100)
+
+	replayed := NewEngine(asset1, asset2)
+	assertErr(t, replayed.Replay(context.Background(), bytes.NewReader(buf.Bytes()),
+		&tOrderFactory{owners: map[string]*tWallet{"1": wallet3, "2": wallet3, "3": wallet4}}, nil))
+
+	if o, err := replayed.FindOrder("1"); err != nil || o.Quantity().Cmp(tFloat64(1)) != 0 {
+		t.Fatal("reduction must be replayed", o, err)
+	}
+
+	if walletBalance(wallet3, asset1) != 6 || walletBalance(wallet4, asset2) != 82 {
+		t.Fatal("replayed funds must be refunded", walletBalance(wallet3, asset1), walletBalance(wallet4, asset2))
+	}
+}
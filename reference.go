@@ -0,0 +1,153 @@
+package fastme
+
+import "time"
+
+// Average is a weighted average value equal to Sum / Weight. Value has no
+// division, so the caller performs it with the numeric type at hand
+type Average struct {
+	Sum    Value
+	Weight Value
+}
+
+// ReferencePrices configures rolling reference prices calculated from executed trades
+type ReferencePrices struct {
+	// Window is the rolling window length
+	Window time.Duration
+
+	// Weight converts time interval to the Value used as TWAP weight, e.g.
+	// number of seconds. TWAP is not calculated if nil
+	Weight func(time.Duration) Value
+}
+
+type tradePoint struct {
+	at       time.Time
+	price    Value
+	quantity Value
+}
+
+type referencePrices struct {
+	ReferencePrices
+	trades []tradePoint
+}
+
+// SetReferencePrices enables rolling VWAP and TWAP calculation, nil disables it
+func (e *Engine) SetReferencePrices(r *ReferencePrices) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if r == nil {
+		e.reference = nil
+		return
+	}
+
+	e.reference = &referencePrices{ReferencePrices: *r}
+}
+
+// VWAP returns rolling volume-weighted average price. Returns false if there
+// were no trades within the window or reference prices are not enabled
+func (e *Engine) VWAP() (Average, bool) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return e.vwap(e.now())
+}
+
+// TWAP returns rolling time-weighted average price. The price of the last
+// trade before the window is in effect from the window start. Returns false
+// if there were no trades or reference prices are not enabled
+func (e *Engine) TWAP() (Average, bool) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return e.twap(e.now())
+}
+
+// recordTrade registers executed trade
+func (e *Engine) recordTrade(now time.Time, price, quantity Value) {
+	e.lastPrice = price
+
+	if e.ticker != nil {
+		e.ticker.record(now, price, quantity)
+	}
+
+	if e.reference == nil {
+		return
+	}
+
+	e.reference.trades = append(e.reference.trades, tradePoint{
+		at:       now,
+		price:    price,
+		quantity: quantity,
+	})
+	e.reference.prune(now)
+}
+
+// prune removes trades which are out of the window except the last one
+// before the window start used for TWAP
+func (r *referencePrices) prune(now time.Time) {
+	var (
+		start = now.Add(-r.Window)
+		idx   int
+	)
+
+	for idx+1 < len(r.trades) && !r.trades[idx+1].at.After(start) {
+		idx++
+	}
+
+	if idx > 0 {
+		r.trades = append(r.trades[:0], r.trades[idx:]...)
+	}
+}
+
+func (e *Engine) vwap(now time.Time) (avg Average, ok bool) {
+	if e.reference == nil {
+		return
+	}
+
+	start := now.Add(-e.reference.Window)
+	for _, t := range e.reference.trades {
+		if t.at.Before(start) {
+			continue
+		}
+
+		avg.Sum = t.price.Mul(t.quantity).Add(avg.Sum)
+		avg.Weight = t.quantity.Add(avg.Weight)
+		ok = true
+	}
+
+	return
+}
+
+func (e *Engine) twap(now time.Time) (avg Average, ok bool) {
+	if e.reference == nil || e.reference.Weight == nil {
+		return
+	}
+
+	var (
+		start  = now.Add(-e.reference.Window)
+		trades = e.reference.trades
+	)
+
+	for i, t := range trades {
+		from := t.at
+		if from.Before(start) {
+			from = start
+		}
+
+		to := now
+		if i+1 < len(trades) {
+			to = trades[i+1].at
+		}
+
+		if !to.After(from) {
+			continue
+		}
+
+		weight := e.reference.Weight(to.Sub(from))
+		avg.Sum = t.price.Mul(weight).Add(avg.Sum)
+		avg.Weight = weight.Add(avg.Weight)
+		ok = true
+	}
+
+	return
+}
@@ -0,0 +1,90 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReferencePrices(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	if _, ok := engine.VWAP(); ok {
+		t.Fatal("reference prices must be disabled by default")
+	}
+
+	engine.SetReferencePrices(&ReferencePrices{
+		Window: time.Hour,
+		Weight: func(d time.Duration) Value { return tFloat64(d.Seconds()) },
+	})
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 20)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 4, 0)))
+
+	vwap, ok := engine.VWAP()
+	if !ok || vwap.Sum.(tFloat64) != 70 || vwap.Weight.(tFloat64) != 4 {
+		t.Fatal("invalid VWAP", vwap)
+	}
+
+	twap, ok := engine.TWAP()
+	if !ok {
+		t.Fatal("TWAP must be calculated")
+	}
+
+	if price := twap.Sum.(tFloat64) / twap.Weight.(tFloat64); price < 10 || price > 20 {
+		t.Fatal("invalid TWAP", price)
+	}
+
+	// Circuit breaker uses VWAP (17.5) as reference
+	engine.SetCircuitBreaker(&CircuitBreaker{
+		Source: ReferenceVWAP,
+		Low:    tFloat64(0.9),
+		High:   tFloat64(1.1),
+		Cancel: true,
+	})
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet1, true, 1, 19)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet1, true, 1, 25)))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("6", wallet2, false, 2, 0)); err != ErrCircuitBreaker {
+		t.Fatal("circuit breaker must trip")
+	}
+
+	if walletBalance(wallet2, asset1) != 5 {
+		t.Fatal("invalid result")
+	}
+}
+
+func TestReferencePricesShared(t *testing.T) {
+	engine := NewEngine("apples", "dollars")
+	engine.SetReferencePrices(&ReferencePrices{
+		Window: time.Minute,
+		Weight: func(d time.Duration) Value { return tFloat64(d.Seconds()) },
+	})
+
+	// Reference prices are read under the shared lock
+	engine.m.RLock()
+	defer engine.m.RUnlock()
+
+	done := make(chan struct{})
+	go func() {
+		engine.VWAP()
+		engine.TWAP()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reference prices must not take the exclusive lock")
+	}
+}
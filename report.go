@@ -0,0 +1,88 @@
+package fastme
+
+import "context"
+
+// OrderStatus describes the outcome of the incoming order processing
+type OrderStatus uint8
+
+// Order statuses
+const (
+	// StatusFilled means the order is executed completely
+	StatusFilled OrderStatus = iota
+
+	// StatusPlaced means the unfilled remainder rests in the order book
+	StatusPlaced
+
+	// StatusCanceled means the unfilled remainder is dropped
+	StatusCanceled
+
+	// StatusRejected means the order is not accepted
+	StatusRejected
+)
+
+var orderStatusNames = [...]string{
+	StatusFilled:   "filled",
+	StatusPlaced:   "placed",
+	StatusCanceled: "canceled",
+	StatusRejected: "rejected",
+}
+
+// String returns order status name
+func (s OrderStatus) String() string {
+	if int(s) < len(orderStatusNames) {
+		return orderStatusNames[s]
+	}
+	return "unknown"
+}
+
+// Report describes the result of the incoming order processing
+type Report struct {
+	// Fills contains matches against resting orders in execution order
+	Fills []Fill
+
+	// Volume is the total executed volume
+	Volume Volume
+
+	// Remaining is the unfilled quantity of the order
+	Remaining Value
+
+	// Status describes what happened with the order
+	Status OrderStatus
+}
+
+// PlaceOrderReport is the same as PlaceOrder, but returns the report about
+// the order processing. The report is filled also when the circuit breaker
+// interrupts matching
+func (e *Engine) PlaceOrderReport(
+	ctx context.Context,
+	listener EventListener,
+	o Order,
+) (r Report, err error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	err = e.placeOrder(ctx, listener, o, &r)
+	return r, err
+}
+
+// report fills the report of the processed order by its fills
+func (e *Engine) report(r *Report, o Order, fills []Fill) {
+	r.Fills = fills
+	r.Volume = Volume{}
+
+	for _, f := range fills {
+		r.Volume.Price = f.Price.Mul(f.Quantity).Add(r.Volume.Price)
+		r.Volume.Quantity = f.Quantity.Add(r.Volume.Quantity)
+	}
+
+	r.Remaining = o.Quantity()
+
+	switch _, ok := e.orders[o.ID()]; {
+	case ok:
+		r.Status = StatusPlaced
+	case r.Remaining.Sign() > 0:
+		r.Status = StatusCanceled
+	default:
+		r.Status = StatusFilled
+	}
+}
@@ -0,0 +1,96 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPlaceOrderReport(t *testing.T) {
+	var (
+		processor        = newEventListener()
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 2, 12)))
+
+	r, err := engine.PlaceOrderReport(context.Background(), processor, newOrder("3", wallet2, false, 4, 12))
+	assertErr(t, err)
+
+	if len(r.Fills) != 2 ||
+		r.Fills[0].MakerID != "1" ||
+		r.Fills[0].Price.(tFloat64) != 10 ||
+		r.Fills[0].Quantity.(tFloat64) != 1 ||
+		r.Fills[1].MakerID != "2" ||
+		r.Fills[1].Price.(tFloat64) != 12 ||
+		r.Fills[1].Quantity.(tFloat64) != 2 {
+		t.Fatal("invalid fills", r.Fills)
+	}
+
+	if r.Volume.Price.(tFloat64) != 34 ||
+		r.Volume.Quantity.(tFloat64) != 3 ||
+		r.Remaining.(tFloat64) != 1 ||
+		r.Status != StatusPlaced {
+		t.Fatal("invalid report", r)
+	}
+
+	r, err = engine.PlaceOrderReport(context.Background(), processor, newOrder("3", wallet2, false, 1, 12))
+	if err != ErrOrderExists || r.Status != StatusRejected || r.Remaining.(tFloat64) != 1 {
+		t.Fatal("order must be rejected", r)
+	}
+
+	updateWalletBalance(wallet1, asset1, 3)
+
+	r, err = engine.PlaceOrderReport(context.Background(), processor, newOrder("4", wallet1, true, 1, 12))
+	assertErr(t, err)
+
+	if len(r.Fills) != 1 || r.Remaining.Sign() != 0 || r.Status != StatusFilled {
+		t.Fatal("invalid report", r)
+	}
+
+	engine.SetTakerPolicy(TakerPolicyMarket)
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("5", wallet2, false, 1, 5)))
+
+	r, err = engine.PlaceOrderReport(context.Background(), processor, newOrder("6", wallet1, true, 2, 5))
+	assertErr(t, err)
+
+	if len(r.Fills) != 1 ||
+		r.Remaining.(tFloat64) != 1 ||
+		r.Status != StatusCanceled ||
+		r.Status.String() != "canceled" {
+		t.Fatal("invalid report", r)
+	}
+}
+
+func TestPlaceOrderReportRejected(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+		engine         = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil,
+		&tClientOrder{tOrder: newOrder("1", wallet1, true, 1, 10), clientID: "a"}))
+
+	// Reports share all checks of PlaceOrder
+	r, err := engine.PlaceOrderReport(context.Background(), nil,
+		&tClientOrder{tOrder: newOrder("2", wallet1, true, 2, 10), clientID: "a"})
+	if err != ErrClientOrderIDExists || r.Status != StatusRejected || r.Remaining.(tFloat64) != 2 || len(r.Fills) != 0 {
+		t.Fatal("invalid report of rejected order", r, err)
+	}
+
+	engine.KillSwitch(context.Background(), nil, wallet1)
+
+	r, err = engine.PlaceOrderReport(context.Background(), nil, newOrder("3", wallet1, true, 1, 10))
+	if err != ErrWalletBlocked || r.Status != StatusRejected {
+		t.Fatal("invalid report of rejected order", r, err)
+	}
+}
@@ -0,0 +1,72 @@
+package fastme
+
+import "context"
+
+// Reserver is an optional Wallet extension making balance updates atomic.
+// The engine reads Balance and writes UpdateBalance otherwise, which races
+// with withdrawals made outside of the engine. Reserver wallets are updated
+// by the calls below only, Balance and InOrder are read to report events
+type Reserver interface {
+	// Reserve moves v of the asset from the balance to the in-order amount.
+	// Returns false and changes nothing if the balance is insufficient
+	Reserve(ctx context.Context, a Asset, v Value) bool
+
+	// Commit executes the fill: debits dv of the debit asset from the
+	// in-order amount and credits cv of the credit asset to the balance
+	Commit(ctx context.Context, debit Asset, dv Value, credit Asset, cv Value)
+
+	// Release moves v of the asset from the in-order amount back to the balance
+	Release(ctx context.Context, a Asset, v Value)
+}
+
+// reserve reserves funds of the order remainder to be placed to the order
+// book. Returns false if the owner balance doesn't cover it
+func (e *Engine) reserve(ctx context.Context, o Order) bool {
+	if e.matchOnly {
+		return true
+	}
+
+	asset, value := e.reservation(o)
+	if r, ok := o.Owner().(Reserver); ok {
+		return r.Reserve(ctx, asset, value)
+	}
+	return balanceOf(ctx, o.Owner(), asset).Cmp(value) >= 0
+}
+
+// reserveFill reserves taker funds of the fill against the maker. Returns
+// false if the taker balance doesn't cover it. Sellers are checked by
+// Reserver wallets only, as sold quantity is validated on placement
+func (e *Engine) reserveFill(ctx context.Context, taker, maker Order) bool {
+	if e.matchOnly {
+		return true
+	}
+
+	quantity := taker.Quantity()
+	if maker.Quantity().Cmp(quantity) < 0 {
+		quantity = maker.Quantity()
+	}
+
+	asset, value := e.base, quantity
+	if !taker.Sell() {
+		asset, value = e.quote, maker.Price().Mul(quantity)
+	}
+
+	if r, ok := taker.Owner().(Reserver); ok {
+		return r.Reserve(ctx, asset, value)
+	}
+	return taker.Sell() || balanceOf(ctx, taker.Owner(), asset).Cmp(value) >= 0
+}
+
+// notifyBalance reports the wallet balance of the asset
+func (e *Engine) notifyBalance(ctx context.Context, listener EventListener, w Wallet, asset Asset) {
+	v := balanceOf(ctx, w, asset)
+	listener.OnBalanceChanged(ctx, w, asset, v)
+	e.checkBalance(ctx, w, asset, "balance", v)
+}
+
+// notifyInOrder reports the wallet in-order amount of the asset
+func (e *Engine) notifyInOrder(ctx context.Context, listener EventListener, w Wallet, asset Asset) {
+	v := inOrderOf(ctx, w, asset)
+	listener.OnInOrderChanged(ctx, w, asset, v)
+	e.checkBalance(ctx, w, asset, "amount in order", v)
+}
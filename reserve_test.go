@@ -0,0 +1,100 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tReserveWallet struct {
+	*tWallet
+	updates   int  // UpdateBalance and UpdateInOrder calls
+	withdrawn bool // Reserve fails as the balance is withdrawn concurrently
+}
+
+func (t *tReserveWallet) UpdateBalance(ctx context.Context, a Asset, v Value) {
+	t.updates++
+	t.tWallet.UpdateBalance(ctx, a, v)
+}
+
+func (t *tReserveWallet) UpdateInOrder(ctx context.Context, a Asset, v Value) {
+	t.updates++
+	t.tWallet.UpdateInOrder(ctx, a, v)
+}
+
+func (t *tReserveWallet) Reserve(ctx context.Context, a Asset, v Value) bool {
+	if t.withdrawn || t.balance[a] < v.(tFloat64) {
+		return false
+	}
+
+	t.balance[a] -= v.(tFloat64)
+	t.inOrder[a] += v.(tFloat64)
+	return true
+}
+
+func (t *tReserveWallet) Commit(ctx context.Context, debit Asset, dv Value, credit Asset, cv Value) {
+	t.inOrder[debit] -= dv.(tFloat64)
+	t.balance[credit] += cv.(tFloat64)
+}
+
+func (t *tReserveWallet) Release(ctx context.Context, a Asset, v Value) {
+	t.inOrder[a] -= v.(tFloat64)
+	t.balance[a] += v.(tFloat64)
+}
+
+type tReserveOrder struct {
+	*tOrder
+	wallet *tReserveWallet
+}
+
+func (t *tReserveOrder) Owner() Wallet {
+	return t.wallet
+}
+
+func newReserveOrder(id string, w *tReserveWallet, sell bool, qty, price float64) *tReserveOrder {
+	return &tReserveOrder{tOrder: newOrder(id, w.tWallet, sell, qty, price), wallet: w}
+}
+
+func TestReserver(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = &tReserveWallet{tWallet: newWallet()}, &tReserveWallet{tWallet: newWallet()}
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1.tWallet, asset1, 10)
+	updateWalletBalance(wallet2.tWallet, asset2, 100)
+
+	order := newReserveOrder("1", wallet1, true, 5, 10)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newReserveOrder("2", wallet2, false, 2, 10)))
+	assertErr(t, engine.AmendOrder(context.Background(), nil, order, newReserveOrder("1", wallet1, true, 4, 10)))
+
+	if walletBalance(wallet1.tWallet, asset1) != 4 || walletInOrder(wallet1.tWallet, asset1) != 4 ||
+		walletBalance(wallet1.tWallet, asset2) != 20 ||
+		walletBalance(wallet2.tWallet, asset1) != 2 || walletBalance(wallet2.tWallet, asset2) != 80 {
+		t.Fatal("invalid balances after fill and amend")
+	}
+
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+
+	if walletBalance(wallet1.tWallet, asset1) != 8 || walletInOrder(wallet1.tWallet, asset1) != 0 {
+		t.Fatal("invalid balances after cancel")
+	}
+
+	if wallet1.updates != 0 || wallet2.updates != 0 {
+		t.Fatal("Reserver wallets must not be updated directly", wallet1.updates, wallet2.updates)
+	}
+
+	// The balance is withdrawn after validation, the order is not placed
+	wallet2.withdrawn = true
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newReserveOrder("3", wallet2, false, 1, 9)))
+
+	if _, err := engine.FindOrder("3"); err != ErrOrderNotFound {
+		t.Fatal("unreserved order must not be placed", err)
+	}
+
+	if walletBalance(wallet2.tWallet, asset2) != 80 || walletInOrder(wallet2.tWallet, asset2) != 0 {
+		t.Fatal("balances must not be changed")
+	}
+}
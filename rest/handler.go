@@ -0,0 +1,309 @@
+// Package rest provides net/http handlers for order entry and order book
+// queries with JSON requests and responses:
+//
+//	POST   /orders       place order, returns the execution report
+//	GET    /orders       resting orders of the wallet
+//	DELETE /orders/{id}  cancel order of the wallet
+//	GET    /book?depth=N L2 order book, all levels if depth is omitted
+//	GET    /spread       best ask and best bid
+//
+// Wallets are resolved from requests by Config.Wallet, e.g. by the
+// authentication token. The package has no dependencies besides the engine
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/newity/fastme"
+)
+
+// ErrForbidden is returned when the order belongs to another wallet
+var ErrForbidden = errors.New("Order belongs to another wallet")
+
+// OrderFactory creates orders from requests
+type OrderFactory interface {
+	// Value parses price or quantity
+	Value(string) (fastme.Value, error)
+
+	// Order creates order of the wallet
+	Order(ctx context.Context, w fastme.Wallet, r fastme.OrderRecord) (fastme.Order, error)
+}
+
+// Config configures the handler
+type Config struct {
+	// Wallet resolves the wallet of the request, required for order entry
+	// and wallet orders. Returned error is reported as 401 Unauthorized
+	Wallet func(*http.Request) (fastme.Wallet, error)
+
+	// Factory creates orders, required for order entry
+	Factory OrderFactory
+
+	// Listener receives engine events of handler commands, optional
+	Listener fastme.EventListener
+
+	// Formatter renders values and must match the engine Formatter. Values
+	// are rendered by Hash if it's nil
+	Formatter fastme.Formatter
+}
+
+// OrderRequest is the body of POST /orders
+type OrderRequest struct {
+	ID       string `json:"id"`
+	Sell     bool   `json:"sell"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Order is the resting order
+type Order struct {
+	ID       string `json:"id"`
+	Sell     bool   `json:"sell"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Fill is the match against resting order
+type Fill struct {
+	MakerID  string `json:"maker_id"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Volume is the executed volume
+type Volume struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Report is the response of POST /orders. Error is set if the order is
+// accepted, but matching is interrupted, e.g. by the circuit breaker
+type Report struct {
+	Fills     []Fill `json:"fills"`
+	Volume    Volume `json:"volume"`
+	Remaining string `json:"remaining"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Spread is the response of GET /spread, empty prices mean the side is empty
+type Spread struct {
+	Ask string `json:"ask"`
+	Bid string `json:"bid"`
+}
+
+// Error is the error response
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the engine over HTTP
+type Handler struct {
+	engine *fastme.Engine
+	cfg    Config
+}
+
+// NewHandler creates handler of the engine
+func NewHandler(e *fastme.Engine, cfg Config) *Handler {
+	return &Handler{engine: e, cfg: cfg}
+}
+
+// ServeHTTP routes the request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch path := strings.Trim(r.URL.Path, "/"); {
+	case path == "orders" && r.Method == http.MethodPost:
+		h.placeOrder(w, r)
+	case path == "orders" && r.Method == http.MethodGet:
+		h.orders(w, r)
+	case strings.HasPrefix(path, "orders/") && r.Method == http.MethodDelete:
+		h.cancelOrder(w, r, strings.TrimPrefix(path, "orders/"))
+	case path == "book" && r.Method == http.MethodGet:
+		h.book(w, r)
+	case path == "spread" && r.Method == http.MethodGet:
+		h.spread(w)
+	case path == "orders" || strings.HasPrefix(path, "orders/") || path == "book" || path == "spread":
+		writeJSON(w, http.StatusMethodNotAllowed, Error{Error: http.StatusText(http.StatusMethodNotAllowed)})
+	default:
+		writeJSON(w, http.StatusNotFound, Error{Error: http.StatusText(http.StatusNotFound)})
+	}
+}
+
+func (h *Handler) placeOrder(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := h.wallet(w, r)
+	if !ok {
+		return
+	}
+
+	var req OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	price, err := h.cfg.Factory.Value(req.Price)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	quantity, err := h.cfg.Factory.Value(req.Quantity)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	o, err := h.cfg.Factory.Order(r.Context(), wallet, fastme.OrderRecord{
+		ID:       req.ID,
+		Sell:     req.Sell,
+		Price:    price,
+		Quantity: quantity,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.engine.PlaceOrderReport(r.Context(), h.cfg.Listener, o)
+	if err != nil && report.Status == fastme.StatusRejected {
+		writeError(w, status(err), err)
+		return
+	}
+
+	resp := Report{
+		Fills: make([]Fill, 0, len(report.Fills)),
+		Volume: Volume{
+			Price:    h.format(report.Volume.Price),
+			Quantity: h.format(report.Volume.Quantity),
+		},
+		Remaining: h.format(report.Remaining),
+		Status:    report.Status.String(),
+	}
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	for _, f := range report.Fills {
+		resp.Fills = append(resp.Fills, Fill{
+			MakerID:  f.MakerID,
+			Price:    h.format(f.Price),
+			Quantity: h.format(f.Quantity),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) orders(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := h.wallet(w, r)
+	if !ok {
+		return
+	}
+
+	orders := []Order{}
+	for _, o := range h.engine.Orders() {
+		if o.Owner() == wallet {
+			orders = append(orders, Order{
+				ID:       o.ID(),
+				Sell:     o.Sell(),
+				Price:    h.format(o.Price()),
+				Quantity: h.format(o.Quantity()),
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (h *Handler) cancelOrder(w http.ResponseWriter, r *http.Request, id string) {
+	wallet, ok := h.wallet(w, r)
+	if !ok {
+		return
+	}
+
+	o, err := h.engine.FindOrder(id)
+	if err == nil && o.Owner() != wallet {
+		err = ErrForbidden
+	}
+
+	if err == nil {
+		err = h.engine.CancelOrderByID(r.Context(), h.cfg.Listener, id)
+	}
+
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) book(w http.ResponseWriter, r *http.Request) {
+	var depth int
+	if s := r.URL.Query().Get("depth"); s != "" {
+		var err error
+		if depth, err = strconv.Atoi(s); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, h.engine.L2(depth))
+}
+
+func (h *Handler) spread(w http.ResponseWriter) {
+	ask, bid := h.engine.Spread()
+	writeJSON(w, http.StatusOK, Spread{Ask: h.format(ask), Bid: h.format(bid)})
+}
+
+func (h *Handler) wallet(w http.ResponseWriter, r *http.Request) (fastme.Wallet, bool) {
+	wallet, err := h.cfg.Wallet(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return nil, false
+	}
+	return wallet, true
+}
+
+func (h *Handler) format(v fastme.Value) string {
+	switch {
+	case v == nil:
+		return ""
+	case h.cfg.Formatter == nil:
+		return v.Hash()
+	default:
+		return h.cfg.Formatter.Format(v)
+	}
+}
+
+// status maps engine errors to HTTP status codes
+func status(err error) int {
+	switch {
+	case errors.Is(err, fastme.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, fastme.ErrOrderExists):
+		return http.StatusConflict
+	case errors.Is(err, fastme.ErrInsufficientFunds):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, fastme.ErrRateLimited):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, Error{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
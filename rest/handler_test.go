@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/newity/fastme"
+)
+
+type tValue float64
+
+func (t tValue) Add(n fastme.Value) fastme.Value { return t + t.checkNil(n) }
+func (t tValue) Sub(n fastme.Value) fastme.Value { return t - t.checkNil(n) }
+func (t tValue) Mul(n fastme.Value) fastme.Value { return t * t.checkNil(n) }
+func (t tValue) Hash() string                    { return strconv.FormatFloat(float64(t), 'f', -1, 64) }
+
+func (t tValue) Cmp(n fastme.Value) int {
+	switch v := t.checkNil(n); {
+	case t > v:
+		return 1
+	case t < v:
+		return -1
+	}
+	return 0
+}
+
+func (t tValue) Sign() int {
+	return t.Cmp(tValue(0))
+}
+
+func (t tValue) checkNil(v fastme.Value) tValue {
+	if v == nil {
+		return 0
+	}
+	return v.(tValue)
+}
+
+type tWallet struct {
+	values map[string]fastme.Value
+}
+
+func (t *tWallet) get(key string) fastme.Value {
+	if v, ok := t.values[key]; ok {
+		return v
+	}
+	return tValue(0)
+}
+
+func (t *tWallet) Balance(ctx context.Context, a fastme.Asset) fastme.Value {
+	return t.get("balance/" + string(a))
+}
+
+func (t *tWallet) UpdateBalance(ctx context.Context, a fastme.Asset, v fastme.Value) {
+	t.values["balance/"+string(a)] = v
+}
+
+func (t *tWallet) InOrder(ctx context.Context, a fastme.Asset) fastme.Value {
+	return t.get("in_order/" + string(a))
+}
+
+func (t *tWallet) UpdateInOrder(ctx context.Context, a fastme.Asset, v fastme.Value) {
+	t.values["in_order/"+string(a)] = v
+}
+
+type tOrder struct {
+	id              string
+	owner           fastme.Wallet
+	sell            bool
+	price, quantity fastme.Value
+}
+
+func (t *tOrder) ID() string                    { return t.id }
+func (t *tOrder) Owner() fastme.Wallet          { return t.owner }
+func (t *tOrder) Sell() bool                    { return t.sell }
+func (t *tOrder) Price() fastme.Value           { return t.price }
+func (t *tOrder) Quantity() fastme.Value        { return t.quantity }
+func (t *tOrder) UpdateQuantity(v fastme.Value) { t.quantity = v }
+
+type tFactory struct{}
+
+func (tFactory) Value(s string) (fastme.Value, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	return tValue(v), err
+}
+
+func (tFactory) Order(ctx context.Context, w fastme.Wallet, r fastme.OrderRecord) (fastme.Order, error) {
+	return &tOrder{id: r.ID, owner: w, sell: r.Sell, price: r.Price, quantity: r.Quantity}, nil
+}
+
+func TestHandler(t *testing.T) {
+	var (
+		wallets = map[string]*tWallet{
+			"seller": {values: map[string]fastme.Value{"balance/apples": tValue(2)}},
+			"buyer":  {values: map[string]fastme.Value{"balance/dollars": tValue(100)}},
+		}
+		handler = NewHandler(fastme.NewEngine("apples", "dollars"), Config{
+			Factory: tFactory{},
+			Wallet: func(r *http.Request) (fastme.Wallet, error) {
+				if w, ok := wallets[r.Header.Get("Authorization")]; ok {
+					return w, nil
+				}
+				return nil, errors.New("Unknown wallet")
+			},
+		})
+	)
+
+	do := func(method, path, wallet, body string, code int, resp interface{}) {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", wallet)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != code {
+			t.Fatalf("%s %s: %d != %d: %s", method, path, rec.Code, code, rec.Body)
+		}
+
+		if resp != nil {
+			if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var report Report
+	do("POST", "/orders", "seller", `{"id":"1","sell":true,"price":"10","quantity":"2"}`, http.StatusOK, &report)
+	if report.Status != "placed" || len(report.Fills) != 0 {
+		t.Fatal("order must be placed")
+	}
+
+	do("POST", "/orders", "buyer", `{"id":"2","price":"10","quantity":"1"}`, http.StatusOK, &report)
+	if report.Status != "filled" || len(report.Fills) != 1 || report.Volume.Price != "10" {
+		t.Fatal("order must be filled")
+	}
+
+	do("POST", "/orders", "buyer", `{"id":"1","price":"10","quantity":"1"}`, http.StatusConflict, nil)
+	do("POST", "/orders", "unknown", `{}`, http.StatusUnauthorized, nil)
+
+	var book fastme.L2Book
+	do("GET", "/book?depth=1", "", "", http.StatusOK, &book)
+	if len(book.Asks) != 1 || book.Asks[0] != [2]string{"10", "1"} || len(book.Bids) != 0 {
+		t.Fatal("invalid order book")
+	}
+
+	var spread Spread
+	do("GET", "/spread", "", "", http.StatusOK, &spread)
+	if spread.Ask != "10" || spread.Bid != "" {
+		t.Fatal("invalid spread")
+	}
+
+	var orders []Order
+	do("GET", "/orders", "buyer", "", http.StatusOK, &orders)
+	if len(orders) != 0 {
+		t.Fatal("buyer has no resting orders")
+	}
+
+	do("GET", "/orders", "seller", "", http.StatusOK, &orders)
+	if len(orders) != 1 || orders[0].Quantity != "1" {
+		t.Fatal("seller order must be listed")
+	}
+
+	do("DELETE", "/orders/1", "buyer", "", http.StatusForbidden, nil)
+	do("DELETE", "/orders/1", "seller", "", http.StatusNoContent, nil)
+	do("DELETE", "/orders/1", "seller", "", http.StatusNotFound, nil)
+	do("PUT", "/book", "", "", http.StatusMethodNotAllowed, nil)
+}
@@ -0,0 +1,28 @@
+package fastme
+
+import "context"
+
+// RiskChecker validates orders against the order book state, e.g. price
+// bands, position limits or fat-finger checks. CheckOrder is called within
+// the engine lock after built-in checks and before matching, for placed,
+// replacing and amending orders. The returned error rejects the order.
+// Journaled commands are replayed without checks, as they were accepted
+type RiskChecker interface {
+	CheckOrder(ctx context.Context, o Order) error
+}
+
+// SetRiskChecker sets the pre-trade risk checker, nil disables it
+func (e *Engine) SetRiskChecker(rc RiskChecker) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.risk = rc
+}
+
+// checkRisk calls the risk checker if it's set
+func (e *Engine) checkRisk(ctx context.Context, o Order) error {
+	if e.risk == nil || replaying(ctx) {
+		return nil
+	}
+	return e.risk.CheckOrder(ctx, o)
+}
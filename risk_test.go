@@ -0,0 +1,61 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errFatFinger = errors.New("fat finger")
+
+type tRiskChecker struct {
+	maxQuantity Value
+	checked     int
+}
+
+func (t *tRiskChecker) CheckOrder(ctx context.Context, o Order) error {
+	t.checked++
+	if o.Quantity().Cmp(t.maxQuantity) > 0 {
+		return errFatFinger
+	}
+	return nil
+}
+
+func TestRiskChecker(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = newWallet()
+		risk           = &tRiskChecker{maxQuantity: tFloat64(5)}
+
+		engine = NewEngine(asset1, asset2, WithRiskChecker(risk))
+	)
+
+	updateWalletBalance(wallet, asset1, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet, true, 5, 10)))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 6, 10)); !errors.Is(err, errFatFinger) {
+		t.Fatal("placed order must be checked", err)
+	}
+
+	if err := engine.AmendOrder(context.Background(), nil,
+		newOrder("1", wallet, true, 5, 10), newOrder("1", wallet, true, 6, 10)); !errors.Is(err, errFatFinger) {
+		t.Fatal("amending order must be checked", err)
+	}
+
+	if _, err := engine.ReplaceOrder(context.Background(), nil,
+		newOrder("1", wallet, true, 5, 10), newOrder("3", wallet, true, 6, 11)); !errors.Is(err, errFatFinger) {
+		t.Fatal("replacing order must be checked", err)
+	}
+
+	if risk.checked != 4 {
+		t.Fatal("risk checker must be called for every order", risk.checked)
+	}
+
+	if walletInOrder(wallet, asset1) != 5 {
+		t.Fatal("rejected orders must not change balances", walletInOrder(wallet, asset1))
+	}
+
+	engine.SetRiskChecker(nil)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet, true, 6, 10)))
+}
@@ -0,0 +1,84 @@
+// Command fastmed is the reference standalone server running the engine
+// over gRPC. Values are num.Decimal, wallets are identified by request
+// wallet_id only and the engine runs without wallet accounting, so balances
+// are expected to be settled from trades by the caller:
+//
+//	fastmed -addr :9090 -base BTC -quote USD
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/newity/fastme"
+	"github.com/newity/fastme/num"
+	"github.com/newity/fastme/rpc"
+	"google.golang.org/grpc"
+)
+
+// walletFactory creates limit orders of in-memory wallets created on demand
+type walletFactory struct {
+	m       sync.Mutex
+	wallets map[string]*fastme.MemoryWallet
+	ids     map[fastme.Wallet]string
+}
+
+func (f *walletFactory) Value(s string) (fastme.Value, error) {
+	return num.ParseDecimal(s)
+}
+
+func (f *walletFactory) Order(ctx context.Context, walletID string, r fastme.OrderRecord) (fastme.Order, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	w, ok := f.wallets[walletID]
+	if !ok {
+		w = fastme.NewMemoryWallet(num.Decimal{})
+		f.wallets[walletID], f.ids[w] = w, walletID
+	}
+
+	side := fastme.SideBuy
+	if r.Sell {
+		side = fastme.SideSell
+	}
+
+	return fastme.NewLimitOrder(r.ID, w, side, r.Price, r.Quantity), nil
+}
+
+func (f *walletFactory) WalletID(w fastme.Wallet) string {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.ids[w]
+}
+
+func main() {
+	var (
+		addr  = flag.String("addr", ":9090", "listen address")
+		base  = flag.String("base", "BTC", "base asset")
+		quote = flag.String("quote", "USD", "quote asset")
+	)
+	flag.Parse()
+
+	var (
+		engine  = fastme.NewEngine(fastme.Asset(*base), fastme.Asset(*quote), fastme.WithoutAccounting())
+		factory = &walletFactory{
+			wallets: make(map[string]*fastme.MemoryWallet),
+			ids:     make(map[fastme.Wallet]string),
+		}
+		server = grpc.NewServer(rpc.ServerOption())
+	)
+
+	rpc.NewServer(engine, rpc.Config{Factory: factory}).Register(server)
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("fastmed: serving %s/%s on %s", *base, *quote, lis.Addr())
+	log.Fatal(server.Serve(lis))
+}
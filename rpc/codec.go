@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/proto" // registers the fallback codec
+)
+
+// message is implemented by pb messages
+type message interface {
+	Marshal() []byte
+	Unmarshal([]byte) error
+}
+
+// Codec encodes pb messages on the protobuf wire, so it's compatible with
+// clients generated from fastme.proto. Other values are passed to the
+// registered proto codec, so services with generated messages may be served
+// by the same grpc.Server. Clients of this package pass it by
+// grpc.ForceCodec
+type Codec struct{}
+
+// Marshal returns the wire format of v
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(message); ok {
+		return m.Marshal(), nil
+	}
+	return fallback().Marshal(v)
+}
+
+// Unmarshal parses the wire format into v
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(message); ok {
+		return m.Unmarshal(data)
+	}
+	return fallback().Unmarshal(data, v)
+}
+
+// Name returns "proto", the content subtype of protobuf messages
+func (Codec) Name() string {
+	return "proto"
+}
+
+func fallback() encoding.Codec {
+	return encoding.GetCodec("proto")
+}
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/newity/fastme/pb"
+	"google.golang.org/grpc"
+)
+
+// Service descriptors written after fastme.proto, so no generated code is
+// needed
+
+type orderEntryServer interface {
+	PlaceOrder(context.Context, *pb.PlaceOrderRequest) (*pb.OrderReport, error)
+	ReplaceOrder(context.Context, *pb.ReplaceOrderRequest) (*pb.OrderReport, error)
+	CancelOrder(context.Context, *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error)
+}
+
+type marketDataServer interface {
+	Subscribe(*pb.SubscribeRequest, grpc.ServerStream) error
+}
+
+var orderEntryDesc = grpc.ServiceDesc{
+	ServiceName: "fastme.OrderEntry",
+	HandlerType: (*orderEntryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("PlaceOrder", func() message { return new(pb.PlaceOrderRequest) },
+			func(s *Server, ctx context.Context, req message) (interface{}, error) {
+				return s.PlaceOrder(ctx, req.(*pb.PlaceOrderRequest))
+			}),
+		unary("ReplaceOrder", func() message { return new(pb.ReplaceOrderRequest) },
+			func(s *Server, ctx context.Context, req message) (interface{}, error) {
+				return s.ReplaceOrder(ctx, req.(*pb.ReplaceOrderRequest))
+			}),
+		unary("CancelOrder", func() message { return new(pb.CancelOrderRequest) },
+			func(s *Server, ctx context.Context, req message) (interface{}, error) {
+				return s.CancelOrder(ctx, req.(*pb.CancelOrderRequest))
+			}),
+	},
+	Metadata: "fastme.proto",
+}
+
+var marketDataDesc = grpc.ServiceDesc{
+	ServiceName: "fastme.MarketData",
+	HandlerType: (*marketDataServer)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Subscribe",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			req := new(pb.SubscribeRequest)
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			return srv.(*Server).Subscribe(req, stream)
+		},
+	}},
+	Metadata: "fastme.proto",
+}
+
+// unary describes the unary method of OrderEntry
+func unary(
+	name string,
+	newRequest func() message,
+	call func(*Server, context.Context, message) (interface{}, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(
+			srv interface{},
+			ctx context.Context,
+			dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor,
+		) (interface{}, error) {
+			req := newRequest()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			if interceptor == nil {
+				return call(srv.(*Server), ctx, req)
+			}
+
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/fastme.OrderEntry/" + name,
+			}
+
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(*Server), ctx, req.(message))
+			})
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/newity/fastme"
+)
+
+// OnIncomingOrderPartial is ignored, trades are streamed by OnTrade
+func (s *Server) OnIncomingOrderPartial(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnIncomingOrderDone is ignored, trades are streamed by OnTrade
+func (s *Server) OnIncomingOrderDone(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnIncomingOrderPlaced is ignored
+func (s *Server) OnIncomingOrderPlaced(context.Context, fastme.Order) {}
+
+// OnExistingOrderPartial is ignored, trades are streamed by OnTrade
+func (s *Server) OnExistingOrderPartial(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnExistingOrderDone is ignored, trades are streamed by OnTrade
+func (s *Server) OnExistingOrderDone(context.Context, fastme.Order, fastme.Volume) {}
+
+// OnExistingOrderCanceled is ignored
+func (s *Server) OnExistingOrderCanceled(context.Context, fastme.Order) {}
+
+// OnBalanceChanged is ignored
+func (s *Server) OnBalanceChanged(context.Context, fastme.Wallet, fastme.Asset, fastme.Value) {}
+
+// OnInOrderChanged is ignored
+func (s *Server) OnInOrderChanged(context.Context, fastme.Wallet, fastme.Asset, fastme.Value) {}
@@ -0,0 +1,298 @@
+// Package rpc serves the engine over gRPC according to the OrderEntry and
+// MarketData services of fastme.proto. Messages are encoded by the pb
+// package, so there is no generated code: clients may be generated from
+// fastme.proto by protoc or use Codec. The package is the separate module,
+// so the engine module stays without dependencies
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/newity/fastme"
+	"github.com/newity/fastme/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrForbidden is returned when the order belongs to another wallet
+var ErrForbidden = errors.New("Order belongs to another wallet")
+
+// errSlowSubscriber closes the subscription which doesn't keep up with trades
+var errSlowSubscriber = status.Error(codes.ResourceExhausted, "Subscriber is too slow")
+
+// OrderFactory creates engine orders of wallets identified by request
+// wallet_id. Wallet IDs are trusted, authenticate them by an interceptor
+type OrderFactory interface {
+	// Value parses price or quantity
+	Value(string) (fastme.Value, error)
+
+	// Order creates order of the wallet
+	Order(ctx context.Context, walletID string, r fastme.OrderRecord) (fastme.Order, error)
+
+	// WalletID returns the ID of the wallet, it's checked on cancellation
+	// and replacement
+	WalletID(fastme.Wallet) string
+}
+
+// Config configures the server
+type Config struct {
+	// Factory creates orders, required
+	Factory OrderFactory
+
+	// Listener receives engine events of server commands, optional
+	Listener fastme.EventListener
+
+	// Formatter renders values and must match the engine Formatter. Values
+	// are rendered by Hash if it's nil
+	Formatter fastme.Formatter
+
+	// Buffer is the number of trades queued per subscription, slow
+	// subscriptions are closed when it's full. 256 by default
+	Buffer int
+}
+
+// Server implements OrderEntry and MarketData services of the engine. It's
+// the fastme.EventListener streaming trades to subscribers, so it must
+// receive events of all engine commands, including those not issued by the
+// server: use fastme.ListenerMux to combine it with other listeners
+type Server struct {
+	engine   *fastme.Engine
+	cfg      Config
+	listener fastme.EventListener
+
+	m           sync.Mutex
+	subscribers map[chan *pb.Trade]struct{}
+}
+
+// NewServer creates the server of the engine
+func NewServer(e *fastme.Engine, cfg Config) *Server {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 256
+	}
+
+	s := &Server{
+		engine:      e,
+		cfg:         cfg,
+		subscribers: make(map[chan *pb.Trade]struct{}),
+	}
+
+	s.listener = s
+	if cfg.Listener != nil {
+		s.listener = fastme.NewListenerMux(s, cfg.Listener)
+	}
+
+	return s
+}
+
+// Register registers the services on the gRPC server. The server must be
+// created with ServerOption
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	r.RegisterService(&orderEntryDesc, s)
+	r.RegisterService(&marketDataDesc, s)
+}
+
+// ServerOption makes the gRPC server encode pb messages by Codec
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(Codec{})
+}
+
+// PlaceOrder places the order of the wallet and returns the report
+func (s *Server) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.OrderReport, error) {
+	o, err := s.order(ctx, req.WalletID, &req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.engine.PlaceOrderReport(ctx, s.listener, o)
+	if err != nil && r.Status == fastme.StatusRejected {
+		return nil, statusOf(err)
+	}
+
+	report := pb.NewOrderReport(r, s.cfg.Formatter)
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	return &report, nil
+}
+
+// ReplaceOrder replaces resting order of the wallet by the new one and
+// returns the report of the new order
+func (s *Server) ReplaceOrder(ctx context.Context, req *pb.ReplaceOrderRequest) (*pb.OrderReport, error) {
+	resting, err := s.resting(req.WalletID, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.order(ctx, req.WalletID, &req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	fills, err := s.engine.ReplaceOrder(ctx, s.listener, resting, o)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+
+	r := fastme.Report{
+		Fills:     fills,
+		Remaining: o.Quantity(),
+		Status:    fastme.StatusFilled,
+	}
+
+	for _, f := range fills {
+		r.Volume.Price = f.Price.Mul(f.Quantity).Add(r.Volume.Price)
+		r.Volume.Quantity = f.Quantity.Add(r.Volume.Quantity)
+	}
+
+	// Same price replacement amends the resting order in place
+	if placed, err := s.engine.FindOrder(o.ID()); err == nil {
+		r.Remaining, r.Status = placed.Quantity(), fastme.StatusPlaced
+	} else if r.Remaining.Sign() > 0 {
+		r.Status = fastme.StatusCanceled
+	}
+
+	report := pb.NewOrderReport(r, s.cfg.Formatter)
+	return &report, nil
+}
+
+// CancelOrder cancels resting order of the wallet
+func (s *Server) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	if _, err := s.resting(req.WalletID, req.OrderID); err != nil {
+		return nil, err
+	}
+
+	if err := s.engine.CancelOrderByID(ctx, s.listener, req.OrderID); err != nil {
+		return nil, statusOf(err)
+	}
+
+	return &pb.CancelOrderResponse{}, nil
+}
+
+// Subscribe sends the order book followed by executed trades until the
+// stream is done. Trades executed while the book is taken may be already
+// reflected in it
+func (s *Server) Subscribe(req *pb.SubscribeRequest, stream grpc.ServerStream) error {
+	trades := make(chan *pb.Trade, s.cfg.Buffer)
+
+	s.m.Lock()
+	s.subscribers[trades] = struct{}{}
+	s.m.Unlock()
+
+	defer s.unsubscribe(trades)
+
+	asks, bids := pb.Levels(s.engine, s.cfg.Formatter)
+	if depth := int(req.Depth); depth > 0 {
+		if len(asks) > depth {
+			asks = asks[:depth]
+		}
+		if len(bids) > depth {
+			bids = bids[:depth]
+		}
+	}
+
+	if err := stream.SendMsg(&pb.MarketDataEvent{Book: &pb.Book{Asks: asks, Bids: bids}}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case t, ok := <-trades:
+			if !ok {
+				return errSlowSubscriber
+			}
+
+			if err := stream.SendMsg(&pb.MarketDataEvent{Trade: t}); err != nil {
+				return err
+			}
+
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *Server) unsubscribe(trades chan *pb.Trade) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	delete(s.subscribers, trades)
+}
+
+// OnTrade queues the trade to subscribers, full subscriptions are closed
+func (s *Server) OnTrade(ctx context.Context, t fastme.Trade) {
+	trade := pb.NewTrade(t, s.cfg.Formatter)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for trades := range s.subscribers {
+		select {
+		case trades <- &trade:
+		default:
+			delete(s.subscribers, trades)
+			close(trades)
+		}
+	}
+}
+
+// order creates the order of the wallet from the request
+func (s *Server) order(ctx context.Context, walletID string, req *pb.Order) (fastme.Order, error) {
+	r := fastme.OrderRecord{ID: req.ID, Sell: req.Sell}
+
+	price, err := s.cfg.Factory.Value(req.Price)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	quantity, err := s.cfg.Factory.Value(req.Quantity)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	r.Price, r.Quantity = price, quantity
+
+	o, err := s.cfg.Factory.Order(ctx, walletID, r)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return o, nil
+}
+
+// resting returns resting order of the wallet
+func (s *Server) resting(walletID, id string) (fastme.Order, error) {
+	o, err := s.engine.FindOrder(id)
+	if err == nil && s.cfg.Factory.WalletID(o.Owner()) != walletID {
+		err = ErrForbidden
+	}
+
+	if err != nil {
+		return nil, statusOf(err)
+	}
+
+	return o, nil
+}
+
+// statusOf maps engine errors to gRPC status codes
+func statusOf(err error) error {
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(err, fastme.ErrOrderNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, fastme.ErrOrderExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, fastme.ErrInsufficientFunds):
+		code = codes.FailedPrecondition
+	case errors.Is(err, fastme.ErrRateLimited):
+		code = codes.ResourceExhausted
+	case errors.Is(err, fastme.ErrEngineClosed):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
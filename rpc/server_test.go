@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/newity/fastme"
+	"github.com/newity/fastme/num"
+	"github.com/newity/fastme/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type tFactory struct {
+	wallets map[string]fastme.Wallet
+}
+
+func (f *tFactory) Value(s string) (fastme.Value, error) {
+	return num.ParseDecimal(s)
+}
+
+func (f *tFactory) Order(ctx context.Context, walletID string, r fastme.OrderRecord) (fastme.Order, error) {
+	side := fastme.SideBuy
+	if r.Sell {
+		side = fastme.SideSell
+	}
+	return fastme.NewLimitOrder(r.ID, f.wallets[walletID], side, r.Price, r.Quantity), nil
+}
+
+func (f *tFactory) WalletID(w fastme.Wallet) string {
+	for id, wallet := range f.wallets {
+		if wallet == w {
+			return id
+		}
+	}
+	return ""
+}
+
+func dial(t *testing.T, s *Server) *grpc.ClientConn {
+	var (
+		lis    = bufconn.Listen(1 << 20)
+		server = grpc.NewServer(ServerOption())
+	)
+
+	s.Register(server)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(Codec{})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		engine  = fastme.NewEngine("apples", "dollars", fastme.WithoutAccounting())
+		factory = &tFactory{wallets: map[string]fastme.Wallet{
+			"alice": fastme.NewMemoryWallet(num.Decimal{}),
+			"bob":   fastme.NewMemoryWallet(num.Decimal{}),
+		}}
+		conn   = dial(t, NewServer(engine, Config{Factory: factory}))
+		report pb.OrderReport
+	)
+
+	place := func(wallet string, o pb.Order) error {
+		return conn.Invoke(ctx, "/fastme.OrderEntry/PlaceOrder", &pb.PlaceOrderRequest{WalletID: wallet, Order: o}, &report)
+	}
+
+	if err := place("alice", pb.Order{ID: "1", Sell: true, Price: "10", Quantity: "2"}); err != nil ||
+		report.Status != "placed" || report.Remaining != "2" {
+		t.Fatal("order must be placed", report, err)
+	}
+
+	if err := place("alice", pb.Order{ID: "1", Sell: true, Price: "10", Quantity: "2"}); status.Code(err) != codes.AlreadyExists {
+		t.Fatal("duplicate order must be rejected", err)
+	}
+
+	if err := place("alice", pb.Order{ID: "2", Price: "x", Quantity: "2"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatal("invalid value must be rejected", err)
+	}
+
+	stream, err := conn.NewStream(ctx, &marketDataDesc.Streams[0], "/fastme.MarketData/Subscribe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stream.SendMsg(&pb.SubscribeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var event pb.MarketDataEvent
+	if err := stream.RecvMsg(&event); err != nil || event.Book == nil ||
+		len(event.Book.Asks) != 1 || event.Book.Asks[0].Volume != "2" {
+		t.Fatal("book must be sent first", event, err)
+	}
+
+	if err := place("bob", pb.Order{ID: "3", Price: "10", Quantity: "1"}); err != nil ||
+		report.Status != "filled" || len(report.Fills) != 1 || report.Fills[0].MakerOrderID != "1" ||
+		report.Volume.Price != "10" {
+		t.Fatal("order must be filled", report, err)
+	}
+
+	if err := stream.RecvMsg(&event); err != nil || event.Trade == nil ||
+		event.Trade.MakerOrderID != "1" || event.Trade.TakerOrderID != "3" || event.Trade.Quantity != "1" {
+		t.Fatal("trade must be streamed", event, err)
+	}
+
+	replace := &pb.ReplaceOrderRequest{WalletID: "bob", OrderID: "1", Order: pb.Order{ID: "4", Sell: true, Price: "11", Quantity: "1"}}
+	if err := conn.Invoke(ctx, "/fastme.OrderEntry/ReplaceOrder", replace, &report); status.Code(err) != codes.PermissionDenied {
+		t.Fatal("order of another wallet must not be replaced", err)
+	}
+
+	replace.WalletID = "alice"
+	if err := conn.Invoke(ctx, "/fastme.OrderEntry/ReplaceOrder", replace, &report); err != nil ||
+		report.Status != "placed" || report.Remaining != "1" {
+		t.Fatal("order must be replaced", report, err)
+	}
+
+	var canceled pb.CancelOrderResponse
+	if err := conn.Invoke(ctx, "/fastme.OrderEntry/CancelOrder", &pb.CancelOrderRequest{WalletID: "alice", OrderID: "4"}, &canceled); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Invoke(ctx, "/fastme.OrderEntry/CancelOrder", &pb.CancelOrderRequest{WalletID: "alice", OrderID: "4"}, &canceled); status.Code(err) != codes.NotFound {
+		t.Fatal("canceled order must not be found", err)
+	}
+
+	if orders := engine.Orders(); len(orders) != 0 {
+		t.Fatal("order book must be empty", orders)
+	}
+}
+
+func TestServerSlowSubscriber(t *testing.T) {
+	var (
+		engine  = fastme.NewEngine("apples", "dollars", fastme.WithoutAccounting())
+		factory = &tFactory{wallets: map[string]fastme.Wallet{"alice": fastme.NewMemoryWallet(num.Decimal{})}}
+		server  = NewServer(engine, Config{Factory: factory, Buffer: 1})
+		trades  = make(chan *pb.Trade, 1)
+	)
+
+	server.subscribers[trades] = struct{}{}
+
+	for i := 0; i < 2; i++ {
+		server.OnTrade(context.Background(), fastme.Trade{ID: uint64(i), Timestamp: time.Unix(0, 1)})
+	}
+
+	if t1, ok := <-trades; !ok || t1.ID != 0 {
+		t.Fatal("queued trade must be delivered", t1)
+	}
+
+	if _, ok := <-trades; ok || len(server.subscribers) != 0 {
+		t.Fatal("full subscription must be closed")
+	}
+}
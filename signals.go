@@ -0,0 +1,101 @@
+package fastme
+
+// MidPrice returns the average of the best ask and the best bid. Returns
+// ErrInsufficientQuantity if either side is empty. The value type must
+// implement DivValue
+func (e *Engine) MidPrice() (Value, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	ask, bid := e.asks.minPrice(), e.bids.maxPrice()
+	if ask == nil || bid == nil {
+		return nil, ErrInsufficientQuantity
+	}
+
+	// Value has no constants, so the sum is weighted by the same quantity
+	// instead of being divided by two
+	return Volume{
+		Price:    ask.price.Add(bid.price).Mul(ask.volume),
+		Quantity: ask.volume.Add(ask.volume),
+	}.AvgPrice()
+}
+
+// MicroPrice returns the best ask and the best bid weighted by the volume
+// of the opposite side, so the price leans towards the side which is about
+// to be depleted. Returns ErrInsufficientQuantity if either side is empty.
+// The value type must implement DivValue
+func (e *Engine) MicroPrice() (Value, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	ask, bid := e.asks.minPrice(), e.bids.maxPrice()
+	if ask == nil || bid == nil {
+		return nil, ErrInsufficientQuantity
+	}
+
+	return Volume{
+		Price:    ask.price.Mul(bid.volume).Add(bid.price.Mul(ask.volume)),
+		Quantity: ask.volume.Add(bid.volume),
+	}.AvgPrice()
+}
+
+// DepthVWAP returns volume-weighted average price of up to levels best
+// price levels of the given side, all levels are used if levels <= 0.
+// Returns ErrInsufficientQuantity if the side is empty. The value type must
+// implement DivValue
+func (e *Engine) DepthVWAP(asks bool, levels int) (Value, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	var v Volume
+	e.walkLevels(BookFilter{
+		SkipAsks: !asks,
+		SkipBids: asks,
+		Levels:   levels,
+	}, func(_ bool, q *queue) bool {
+		v.Price = q.price.Mul(q.volume).Add(v.Price)
+		v.Quantity = q.volume.Add(v.Quantity)
+		return true
+	})
+
+	if v.Quantity == nil {
+		return nil, ErrInsufficientQuantity
+	}
+
+	return v.AvgPrice()
+}
+
+// Imbalance returns (bids - asks) / (bids + asks) calculated from volumes of
+// up to levels best price levels of each side, all levels are used if
+// levels <= 0. The result is in [-1, 1], positive values mean buying
+// pressure. Returns ErrInsufficientQuantity if the book is empty. The value
+// type must implement DivValue
+func (e *Engine) Imbalance(levels int) (Value, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	var asks, bids Value
+	e.walkLevels(BookFilter{Levels: levels}, func(ask bool, q *queue) bool {
+		if ask {
+			asks = q.volume.Add(asks)
+		} else {
+			bids = q.volume.Add(bids)
+		}
+		return true
+	})
+
+	if asks == nil && bids == nil {
+		return nil, ErrInsufficientQuantity
+	}
+
+	if bids == nil {
+		bids = asks.Sub(asks)
+	}
+
+	d, ok := bids.Sub(asks).(DivValue)
+	if !ok {
+		return nil, ErrDivisionUnsupported
+	}
+
+	return d.Div(bids.Add(asks)), nil
+}
@@ -0,0 +1,111 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+// tDecimal is the floating point value supporting division
+type tDecimal float64
+
+func (t tDecimal) Add(n Value) Value { return t + t.checkNil(n) }
+func (t tDecimal) Sub(n Value) Value { return t - t.checkNil(n) }
+func (t tDecimal) Mul(n Value) Value { return t * t.checkNil(n) }
+func (t tDecimal) Div(n Value) Value { return t / t.checkNil(n) }
+func (t tDecimal) Hash() string      { return tFloat64(t).Hash() }
+func (t tDecimal) Sign() int         { return tFloat64(t).Sign() }
+
+func (t tDecimal) Cmp(n Value) int {
+	return tFloat64(t).Cmp(tFloat64(t.checkNil(n)))
+}
+
+func (t tDecimal) checkNil(v Value) tDecimal {
+	if v != nil {
+		return v.(tDecimal)
+	}
+	return 0
+}
+
+// tDecimalOrder is the order with tDecimal values
+type tDecimalOrder struct {
+	id              string
+	sell            bool
+	price, quantity Value
+}
+
+func newDecimalOrder(id string, sell bool, qty, price float64) *tDecimalOrder {
+	return &tDecimalOrder{id: id, sell: sell, price: tDecimal(price), quantity: tDecimal(qty)}
+}
+
+func (t *tDecimalOrder) ID() string             { return t.id }
+func (t *tDecimalOrder) Owner() Wallet          { return nil }
+func (t *tDecimalOrder) Sell() bool             { return t.sell }
+func (t *tDecimalOrder) Price() Value           { return t.price }
+func (t *tDecimalOrder) Quantity() Value        { return t.quantity }
+func (t *tDecimalOrder) UpdateQuantity(v Value) { t.quantity = v }
+
+func TestPriceSignals(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		engine         = NewEngine(asset1, asset2)
+		ctx            = context.Background()
+	)
+
+	if _, err := engine.MidPrice(); err != ErrInsufficientQuantity {
+		t.Fatal("empty book must be rejected", err)
+	}
+
+	engine.PushOrder(ctx, newDecimalOrder("1", true, 1, 12))
+	engine.PushOrder(ctx, newDecimalOrder("2", true, 3, 11))
+	engine.PushOrder(ctx, newDecimalOrder("3", false, 1, 9))
+	engine.PushOrder(ctx, newDecimalOrder("4", false, 2, 8))
+
+	if v, err := engine.MidPrice(); err != nil || v != tDecimal(10) {
+		t.Fatal("invalid mid price", v, err)
+	}
+
+	// (11 * 1 + 9 * 3) / 4
+	if v, err := engine.MicroPrice(); err != nil || v != tDecimal(9.5) {
+		t.Fatal("invalid micro price", v, err)
+	}
+
+	// (11 * 3 + 12 * 1) / 4
+	if v, err := engine.DepthVWAP(true, 0); err != nil || v != tDecimal(11.25) {
+		t.Fatal("invalid asks VWAP", v, err)
+	}
+
+	if v, err := engine.DepthVWAP(false, 1); err != nil || v != tDecimal(9) {
+		t.Fatal("invalid bids VWAP", v, err)
+	}
+}
+
+func TestImbalance(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		engine         = NewEngine(asset1, asset2)
+		ctx            = context.Background()
+	)
+
+	if _, err := engine.Imbalance(0); err != ErrInsufficientQuantity {
+		t.Fatal("empty book must be rejected", err)
+	}
+
+	engine.PushOrder(ctx, newDecimalOrder("1", true, 2, 12))
+
+	if v, err := engine.Imbalance(0); err != nil || v != tDecimal(-1) {
+		t.Fatal("one-sided book must be fully imbalanced", v, err)
+	}
+
+	engine.PushOrder(ctx, newDecimalOrder("2", true, 4, 16))
+	engine.PushOrder(ctx, newDecimalOrder("3", false, 2, 8))
+	engine.PushOrder(ctx, newDecimalOrder("4", false, 10, 7))
+
+	if v, err := engine.Imbalance(1); err != nil || v != tDecimal(0) {
+		t.Fatal("top levels must be balanced", v, err)
+	}
+
+	// (12 - 6) / 18
+	if v, err := engine.Imbalance(0); err != nil || v != tDecimal(6.0/18) {
+		t.Fatal("invalid imbalance", v, err)
+	}
+}
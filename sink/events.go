@@ -0,0 +1,244 @@
+package sink
+
+import (
+	"context"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+// Event types
+const (
+	EventIncomingPartial     = "incoming_partial"
+	EventIncomingDone        = "incoming_done"
+	EventIncomingPlaced      = "incoming_placed"
+	EventIncomingCanceled    = "incoming_canceled"
+	EventExistingPartial     = "existing_partial"
+	EventExistingDone        = "existing_done"
+	EventExistingCanceled    = "existing_canceled"
+	EventExistingExpired     = "existing_expired"
+	EventExistingUnderfunded = "existing_underfunded"
+	EventExistingReduced     = "existing_reduced"
+	EventRejected            = "rejected"
+	EventBalance             = "balance"
+	EventInOrder             = "in_order"
+	EventTrade               = "trade"
+	EventFee                 = "fee"
+	EventRebate              = "rebate"
+	EventState               = "state"
+	EventProtection          = "protection"
+)
+
+// Event is the serialized engine event. Only fields related to the event
+// type are set
+type Event struct {
+	Seq  uint64    `json:"seq"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time,omitempty"`
+
+	Order  *Order  `json:"order,omitempty"`
+	Volume *Volume `json:"volume,omitempty"`
+	Trade  *Trade  `json:"trade,omitempty"`
+
+	Wallet string `json:"wallet,omitempty"`
+	Asset  string `json:"asset,omitempty"`
+	Value  string `json:"value,omitempty"`
+
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Order is the order state at the event time
+type Order struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id,omitempty"`
+	Sell     bool   `json:"sell"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Volume is the executed volume
+type Volume struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Trade is the executed trade
+type Trade struct {
+	ID           uint64 `json:"id"`
+	MakerOrderID string `json:"maker_order_id"`
+	TakerOrderID string `json:"taker_order_id"`
+	Price        string `json:"price"`
+	Quantity     string `json:"quantity"`
+}
+
+func (s *Sink) format(v fastme.Value) string {
+	switch {
+	case v == nil:
+		return ""
+	case s.cfg.Formatter == nil:
+		return v.Hash()
+	default:
+		return s.cfg.Formatter.Format(v)
+	}
+}
+
+func (s *Sink) order(o fastme.Order) *Order {
+	return &Order{
+		ID:       o.ID(),
+		ClientID: fastme.OrderClientID(o),
+		Sell:     o.Sell(),
+		Price:    s.format(o.Price()),
+		Quantity: s.format(o.Quantity()),
+	}
+}
+
+func (s *Sink) volume(v fastme.Volume) *Volume {
+	return &Volume{
+		Price:    s.format(v.Price),
+		Quantity: s.format(v.Quantity),
+	}
+}
+
+func (s *Sink) orderEvent(ctx context.Context, typ string, o fastme.Order) {
+	s.publish(ctx, Event{Type: typ, Order: s.order(o)})
+}
+
+func (s *Sink) volumeEvent(ctx context.Context, typ string, o fastme.Order, v fastme.Volume) {
+	s.publish(ctx, Event{Type: typ, Order: s.order(o), Volume: s.volume(v)})
+}
+
+func (s *Sink) walletEvent(ctx context.Context, typ string, w fastme.Wallet, a fastme.Asset, v fastme.Value) {
+	if s.cfg.WalletID == nil {
+		return
+	}
+
+	s.publish(ctx, Event{
+		Type:   typ,
+		Wallet: s.cfg.WalletID(w),
+		Asset:  string(a),
+		Value:  s.format(v),
+	})
+}
+
+// OnIncomingOrderPartial publishes the event
+func (s *Sink) OnIncomingOrderPartial(ctx context.Context, o fastme.Order, v fastme.Volume) {
+	s.volumeEvent(ctx, EventIncomingPartial, o, v)
+}
+
+// OnIncomingOrderDone publishes the event
+func (s *Sink) OnIncomingOrderDone(ctx context.Context, o fastme.Order, v fastme.Volume) {
+	s.volumeEvent(ctx, EventIncomingDone, o, v)
+}
+
+// OnIncomingOrderPlaced publishes the event
+func (s *Sink) OnIncomingOrderPlaced(ctx context.Context, o fastme.Order) {
+	s.orderEvent(ctx, EventIncomingPlaced, o)
+}
+
+// OnIncomingOrderCanceled publishes the event
+func (s *Sink) OnIncomingOrderCanceled(ctx context.Context, o fastme.Order) {
+	s.orderEvent(ctx, EventIncomingCanceled, o)
+}
+
+// OnExistingOrderPartial publishes the event
+func (s *Sink) OnExistingOrderPartial(ctx context.Context, o fastme.Order, v fastme.Volume) {
+	s.volumeEvent(ctx, EventExistingPartial, o, v)
+}
+
+// OnExistingOrderDone publishes the event
+func (s *Sink) OnExistingOrderDone(ctx context.Context, o fastme.Order, v fastme.Volume) {
+	s.volumeEvent(ctx, EventExistingDone, o, v)
+}
+
+// OnExistingOrderCanceled publishes the event
+func (s *Sink) OnExistingOrderCanceled(ctx context.Context, o fastme.Order) {
+	s.orderEvent(ctx, EventExistingCanceled, o)
+}
+
+// OnExistingOrderExpired publishes the event
+func (s *Sink) OnExistingOrderExpired(ctx context.Context, o fastme.Order) {
+	s.orderEvent(ctx, EventExistingExpired, o)
+}
+
+// OnExistingOrderUnderfunded publishes the event
+func (s *Sink) OnExistingOrderUnderfunded(ctx context.Context, o fastme.Order) {
+	s.orderEvent(ctx, EventExistingUnderfunded, o)
+}
+
+// OnExistingOrderReduced publishes the event, Value is the reduced quantity
+func (s *Sink) OnExistingOrderReduced(ctx context.Context, o fastme.Order, reduced fastme.Value) {
+	s.publish(ctx, Event{Type: EventExistingReduced, Order: s.order(o), Value: s.format(reduced)})
+}
+
+// OnOrderRejected publishes the event
+func (s *Sink) OnOrderRejected(ctx context.Context, o fastme.Order, err error) {
+	s.publish(ctx, Event{Type: EventRejected, Order: s.order(o), Error: err.Error()})
+}
+
+// OnBalanceChanged publishes the event if Config.WalletID is set
+func (s *Sink) OnBalanceChanged(ctx context.Context, w fastme.Wallet, a fastme.Asset, v fastme.Value) {
+	s.walletEvent(ctx, EventBalance, w, a, v)
+}
+
+// OnInOrderChanged publishes the event if Config.WalletID is set
+func (s *Sink) OnInOrderChanged(ctx context.Context, w fastme.Wallet, a fastme.Asset, v fastme.Value) {
+	s.walletEvent(ctx, EventInOrder, w, a, v)
+}
+
+// OnTrade publishes the event
+func (s *Sink) OnTrade(ctx context.Context, t fastme.Trade) {
+	s.publish(ctx, Event{
+		Type: EventTrade,
+		Trade: &Trade{
+			ID:           t.ID,
+			MakerOrderID: t.MakerOrder.ID(),
+			TakerOrderID: t.TakerOrder.ID(),
+			Price:        s.format(t.Price),
+			Quantity:     s.format(t.Quantity),
+		},
+	})
+}
+
+// OnFeeCharged publishes the event, the fee receiver is reported if
+// Config.WalletID is set
+func (s *Sink) OnFeeCharged(ctx context.Context, o fastme.Order, f fastme.Fee) {
+	ev := Event{
+		Type:  EventFee,
+		Order: s.order(o),
+		Asset: string(f.Asset),
+		Value: s.format(f.Amount),
+	}
+
+	if s.cfg.WalletID != nil {
+		ev.Wallet = s.cfg.WalletID(f.To)
+	}
+
+	s.publish(ctx, ev)
+}
+
+// OnRebatePaid publishes the event
+func (s *Sink) OnRebatePaid(ctx context.Context, o fastme.Order, a fastme.Asset, v fastme.Value) {
+	s.publish(ctx, Event{
+		Type:  EventRebate,
+		Order: s.order(o),
+		Asset: string(a),
+		Value: s.format(v),
+	})
+}
+
+// OnTradingStateChanged publishes the event
+func (s *Sink) OnTradingStateChanged(ctx context.Context, from, to fastme.TradingState) {
+	s.publish(ctx, Event{Type: EventState, From: from.String(), To: to.String()})
+}
+
+// OnMakerProtectionTriggered publishes the event if Config.WalletID is set
+func (s *Sink) OnMakerProtectionTriggered(ctx context.Context, w fastme.Wallet) {
+	if s.cfg.WalletID == nil {
+		return
+	}
+
+	s.publish(ctx, Event{Type: EventProtection, Wallet: s.cfg.WalletID(w)})
+}
@@ -0,0 +1,193 @@
+// Package sink publishes engine events to message brokers such as Kafka or
+// NATS JetStream. Broker clients are plugged in through the Publisher
+// interface, so the package has no dependencies besides the engine
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+// Message is the serialized event
+type Message struct {
+	// Seq is the event sequence number, it grows by one for every event
+	Seq uint64
+
+	// Key is the partitioning key, all events of the sink share it to keep
+	// ordering within a single partition or subject
+	Key string
+
+	// Data is JSON encoded Event
+	Data []byte
+}
+
+// Publisher delivers messages to the broker. Batch must be published in
+// order, the batch is retried as a whole on error
+type Publisher interface {
+	Publish(ctx context.Context, batch []Message) error
+}
+
+// PublisherFunc is an adapter to allow the use of ordinary functions as Publisher
+type PublisherFunc func(context.Context, []Message) error
+
+// Publish calls f(ctx, batch)
+func (f PublisherFunc) Publish(ctx context.Context, batch []Message) error {
+	return f(ctx, batch)
+}
+
+// Config configures the sink
+type Config struct {
+	// Key is the partitioning key of messages, e.g. the trading pair
+	Key string
+
+	// BatchSize is the maximum number of messages in the batch, 100 by default
+	BatchSize int
+
+	// FlushInterval is the maximum delay of the message, 100ms by default
+	FlushInterval time.Duration
+
+	// Retries is the number of publish retries of the failed batch
+	Retries int
+
+	// Backoff is the delay before the first retry, doubled on every next one
+	Backoff time.Duration
+
+	// Buffer is the size of the queue between the engine and the publisher,
+	// the engine is blocked when it's full. 1024 by default
+	Buffer int
+
+	// Formatter renders values, values are rendered by Hash if it's nil
+	Formatter fastme.Formatter
+
+	// WalletID identifies wallets of balance events. Balance events are not
+	// published if it's nil
+	WalletID func(fastme.Wallet) string
+
+	// OnError is called with the batch dropped after all retries
+	OnError func([]Message, error)
+}
+
+// Sink is the fastme.EventListener publishing events in batches. Events are
+// serialized when they are received, so published orders reflect the state
+// at the event time
+type Sink struct {
+	cfg       Config
+	publisher Publisher
+	messages  chan Message
+	done      chan struct{}
+
+	seq uint64
+	m   sync.Mutex
+}
+
+// New creates the sink and starts publishing. Close must be called to flush
+// queued messages
+func New(p Publisher, cfg Config) *Sink {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 100 * time.Millisecond
+	}
+
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1024
+	}
+
+	s := &Sink{
+		cfg:       cfg,
+		publisher: p,
+		messages:  make(chan Message, cfg.Buffer),
+		done:      make(chan struct{}),
+	}
+
+	go s.run()
+	return s
+}
+
+// Close publishes queued messages and stops the sink. The sink must not be
+// passed to the engine after Close
+func (s *Sink) Close() {
+	close(s.messages)
+	<-s.done
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+
+	var (
+		batch  = make([]Message, 0, s.cfg.BatchSize)
+		ticker = time.NewTicker(s.cfg.FlushInterval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-s.messages:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+
+			if batch = append(batch, msg); len(batch) >= s.cfg.BatchSize {
+				s.flush(batch)
+				batch = make([]Message, 0, s.cfg.BatchSize)
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = make([]Message, 0, s.cfg.BatchSize)
+			}
+		}
+	}
+}
+
+func (s *Sink) flush(batch []Message) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var (
+		backoff = s.cfg.Backoff
+		err     error
+	)
+
+	for attempt := 0; attempt <= s.cfg.Retries; attempt++ {
+		if attempt > 0 && backoff > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if err = s.publisher.Publish(context.Background(), batch); err == nil {
+			return
+		}
+	}
+
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(batch, err)
+	}
+}
+
+// publish assigns the sequence number to the event and queues it
+func (s *Sink) publish(ctx context.Context, ev Event) {
+	if t, ok := fastme.EventTime(ctx); ok {
+		ev.Time = t
+	}
+
+	// Sequence assignment and queueing must be atomic to keep ordering
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.seq++
+	ev.Seq = s.seq
+
+	// Event contains strings only, so encoding never fails
+	data, _ := json.Marshal(ev)
+	s.messages <- Message{Seq: ev.Seq, Key: s.cfg.Key, Data: data}
+}
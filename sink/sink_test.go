@@ -0,0 +1,151 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+type tValue float64
+
+func (t tValue) Add(n fastme.Value) fastme.Value { return t + t.checkNil(n) }
+func (t tValue) Sub(n fastme.Value) fastme.Value { return t - t.checkNil(n) }
+func (t tValue) Mul(n fastme.Value) fastme.Value { return t * t.checkNil(n) }
+func (t tValue) Hash() string                    { return strconv.FormatFloat(float64(t), 'f', -1, 64) }
+
+func (t tValue) Cmp(n fastme.Value) int {
+	switch v := t.checkNil(n); {
+	case t > v:
+		return 1
+	case t < v:
+		return -1
+	}
+	return 0
+}
+
+func (t tValue) Sign() int {
+	return t.Cmp(tValue(0))
+}
+
+func (t tValue) checkNil(v fastme.Value) tValue {
+	if v == nil {
+		return 0
+	}
+	return v.(tValue)
+}
+
+type tOrder struct {
+	id              string
+	sell            bool
+	price, quantity fastme.Value
+}
+
+func (t *tOrder) ID() string                    { return t.id }
+func (t *tOrder) Owner() fastme.Wallet          { return nil }
+func (t *tOrder) Sell() bool                    { return t.sell }
+func (t *tOrder) Price() fastme.Value           { return t.price }
+func (t *tOrder) Quantity() fastme.Value        { return t.quantity }
+func (t *tOrder) UpdateQuantity(v fastme.Value) { t.quantity = v }
+
+type tPublisher struct {
+	batches [][]Message
+	fails   int
+	m       sync.Mutex
+}
+
+func (t *tPublisher) Publish(ctx context.Context, batch []Message) error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.fails > 0 {
+		t.fails--
+		return errors.New("Broker is unavailable")
+	}
+
+	t.batches = append(t.batches, batch)
+	return nil
+}
+
+func TestSinkBatches(t *testing.T) {
+	var (
+		publisher = &tPublisher{}
+		sink      = New(publisher, Config{Key: "apples/dollars", BatchSize: 2, FlushInterval: time.Hour})
+		order     = &tOrder{id: "1", sell: true, price: tValue(10), quantity: tValue(2)}
+		now       = time.Unix(100, 0).UTC()
+	)
+
+	sink.OnIncomingOrderPlaced(fastme.WithEventTime(context.Background(), now), order)
+	sink.OnExistingOrderPartial(context.Background(), order, fastme.Volume{Price: tValue(10), Quantity: tValue(1)})
+	sink.OnTrade(context.Background(), fastme.Trade{ID: 1, MakerOrder: order, TakerOrder: &tOrder{id: "2"}})
+	sink.OnBalanceChanged(context.Background(), nil, "apples", tValue(1))
+	sink.Close()
+
+	if len(publisher.batches) != 2 || len(publisher.batches[0]) != 2 || len(publisher.batches[1]) != 1 {
+		t.Fatal("events must be published in batches, balance events are skipped")
+	}
+
+	var seq uint64
+	for _, batch := range publisher.batches {
+		for _, msg := range batch {
+			var ev Event
+			if err := json.Unmarshal(msg.Data, &ev); err != nil {
+				t.Fatal(err)
+			}
+
+			if seq++; msg.Seq != seq || ev.Seq != seq || msg.Key != "apples/dollars" {
+				t.Fatal("events must be published in sequence")
+			}
+
+			switch seq {
+			case 1:
+				if ev.Type != EventIncomingPlaced || ev.Order.Price != "10" || !ev.Time.Equal(now) {
+					t.Fatal("invalid placed event")
+				}
+
+			case 2:
+				if ev.Type != EventExistingPartial || ev.Volume.Quantity != "1" {
+					t.Fatal("invalid partial event")
+				}
+
+			case 3:
+				if ev.Type != EventTrade || ev.Trade.MakerOrderID != "1" || ev.Trade.TakerOrderID != "2" {
+					t.Fatal("invalid trade event")
+				}
+			}
+		}
+	}
+}
+
+func TestSinkRetries(t *testing.T) {
+	var (
+		publisher = &tPublisher{fails: 2}
+		dropped   []Message
+		sink      = New(publisher, Config{
+			Retries: 1,
+			OnError: func(batch []Message, err error) { dropped = batch },
+		})
+	)
+
+	// The first batch fails twice and is dropped
+	sink.OnTradingStateChanged(context.Background(), fastme.StateOpen, fastme.StateHalted)
+	sink.Close()
+
+	if len(dropped) != 1 || len(publisher.batches) != 0 {
+		t.Fatal("batch must be dropped after retries")
+	}
+
+	publisher.fails = 1
+	sink = New(publisher, Config{Retries: 1, WalletID: func(fastme.Wallet) string { return "w" }})
+	sink.OnMakerProtectionTriggered(context.Background(), nil)
+	sink.Close()
+
+	if len(publisher.batches) != 1 || publisher.batches[0][0].Seq != 1 {
+		t.Fatal("batch must be published on retry")
+	}
+}
@@ -0,0 +1,225 @@
+package fastme
+
+import "sync/atomic"
+
+// ----------------------------------------------------------
+// Price index selection
+// ----------------------------------------------------------
+
+// IndexType is the data structure keeping price levels sorted
+type IndexType int
+
+// Available index types
+const (
+	// IndexRBTree is the red-black tree, the default
+	IndexRBTree IndexType = iota
+
+	// IndexSkipList is the skip list. Walking price levels in order takes
+	// constant time per level, which suits books scanned by the matching loop,
+	// Quantity, Price and market data queries
+	IndexSkipList
+)
+
+// SetPriceIndex switches both order book sides to the given index type.
+// Resting orders are kept
+func (e *Engine) SetPriceIndex(t IndexType) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.indexType = t
+	e.reindex()
+}
+
+// newIndex creates price index according to the engine configuration
+func (e *Engine) newIndex() priceIndex {
+	switch {
+	case e.ladder != nil:
+		return newLadderIndex(e.ladder)
+	case e.indexType == IndexSkipList:
+		return newSkipListIndex()
+	default:
+		return newTreeIndex()
+	}
+}
+
+// reindex moves price levels of both sides to the configured index
+func (e *Engine) reindex() {
+	e.asks.reindex(e.newIndex())
+	e.bids.reindex(e.newIndex())
+}
+
+// ----------------------------------------------------------
+// Skip list implementation
+// ----------------------------------------------------------
+
+const (
+	skipListMaxLevel = 24
+	skipListBranch   = 4 // 1/4 of nodes on level i are promoted to level i+1
+)
+
+type skipNode struct {
+	q    *queue
+	prev *skipNode // nil for the first node
+	next []*skipNode
+}
+
+// skipListIndex is the price index backed by the skip list. The node found
+// by the last walk is kept, so walking the levels in order doesn't search.
+// Walks run under the read lock, so the node is swapped atomically
+type skipListIndex struct {
+	head   skipNode
+	level  int
+	seed   uint64
+	finger atomic.Value // *skipNode
+}
+
+func newSkipListIndex() *skipListIndex {
+	s := &skipListIndex{
+		head:  skipNode{next: make([]*skipNode, skipListMaxLevel)},
+		level: 1,
+		seed:  0x9e3779b97f4a7c15,
+	}
+
+	s.finger.Store((*skipNode)(nil))
+	return s
+}
+
+// randomLevel returns level of the new node. Xorshift keeps the list shape
+// deterministic
+func (s *skipListIndex) randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel {
+		s.seed ^= s.seed << 13
+		s.seed ^= s.seed >> 7
+		s.seed ^= s.seed << 17
+
+		if s.seed%skipListBranch != 0 {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+// search returns the last node with price below the given one on each level
+func (s *skipListIndex) search(price Value, update []*skipNode) *skipNode {
+	x := &s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i].q.price.Cmp(price) < 0 {
+			x = x.next[i]
+		}
+
+		if update != nil {
+			update[i] = x
+		}
+	}
+	return x
+}
+
+// fingerAt returns the last walked node if it has the given price
+func (s *skipListIndex) fingerAt(price Value) *skipNode {
+	if f := s.finger.Load().(*skipNode); f != nil && f.q.price.Cmp(price) == 0 {
+		return f
+	}
+	return nil
+}
+
+// at returns the node with exactly the given price
+func (s *skipListIndex) at(price Value) *skipNode {
+	if f := s.fingerAt(price); f != nil {
+		return f
+	}
+
+	if n := s.search(price, nil).next[0]; n != nil && n.q.price.Cmp(price) == 0 {
+		return n
+	}
+	return nil
+}
+
+func (s *skipListIndex) get(price Value) *queue {
+	if n := s.at(price); n != nil {
+		return n.q
+	}
+	return nil
+}
+
+func (s *skipListIndex) put(q *queue) {
+	var update [skipListMaxLevel]*skipNode
+	prev := s.search(q.price, update[:])
+
+	level := s.randomLevel()
+	for ; s.level < level; s.level++ {
+		update[s.level] = &s.head
+	}
+
+	n := &skipNode{q: q, next: make([]*skipNode, level)}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+	}
+
+	if prev != &s.head {
+		n.prev = prev
+	}
+
+	if n.next[0] != nil {
+		n.next[0].prev = n
+	}
+}
+
+func (s *skipListIndex) remove(price Value) {
+	var update [skipListMaxLevel]*skipNode
+	n := s.search(price, update[:]).next[0]
+	if n == nil || n.q.price.Cmp(price) != 0 {
+		return
+	}
+
+	for i := range n.next {
+		update[i].next[i] = n.next[i]
+	}
+
+	if n.next[0] != nil {
+		n.next[0].prev = n.prev
+	}
+
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+
+	if s.finger.Load().(*skipNode) == n {
+		s.finger.Store((*skipNode)(nil))
+	}
+}
+
+func (s *skipListIndex) greaterThan(price Value) *queue {
+	var n *skipNode
+	if f := s.fingerAt(price); f != nil {
+		n = f.next[0]
+	} else {
+		n = s.search(price, nil).next[0]
+		if n != nil && n.q.price.Cmp(price) == 0 {
+			n = n.next[0]
+		}
+	}
+	return s.walk(n)
+}
+
+func (s *skipListIndex) lessThan(price Value) *queue {
+	var n *skipNode
+	if f := s.fingerAt(price); f != nil {
+		n = f.prev
+	} else if x := s.search(price, nil); x != &s.head {
+		n = x
+	}
+	return s.walk(n)
+}
+
+// walk moves the finger to the node
+func (s *skipListIndex) walk(n *skipNode) *queue {
+	if n == nil {
+		return nil
+	}
+
+	s.finger.Store(n)
+	return n.q
+}
@@ -0,0 +1,87 @@
+package fastme
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestSkipListIndex(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		tree           = NewEngine(asset1, asset2)
+		skipList       = NewEngine(asset1, asset2, WithPriceIndex(IndexSkipList))
+		wallets        = []*tWallet{newWallet(), newWallet()}
+		rnd            = rand.New(rand.NewSource(1))
+		ids            []string
+	)
+
+	for _, w := range wallets {
+		updateWalletBalance(w, asset1, 1e6)
+		updateWalletBalance(w, asset2, 1e6)
+	}
+
+	for i := 0; i < 2000; i++ {
+		if len(ids) > 0 && rnd.Intn(4) == 0 {
+			j := rnd.Intn(len(ids))
+			for _, e := range []*Engine{tree, skipList} {
+				err := e.CancelOrderByID(context.Background(), nil, ids[j])
+				if err != nil && err != ErrOrderNotFound {
+					t.Fatal(err)
+				}
+			}
+			ids = append(ids[:j], ids[j+1:]...)
+			continue
+		}
+
+		var (
+			id    = strconv.Itoa(i)
+			sell  = rnd.Intn(2) == 0
+			qty   = float64(1 + rnd.Intn(5))
+			price = float64(1 + rnd.Intn(100))
+		)
+
+		for k, e := range []*Engine{tree, skipList} {
+			assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder(id, wallets[k], sell, qty, price)))
+		}
+		ids = append(ids, id)
+
+		if bookString(tree) != bookString(skipList) {
+			t.Fatal("skip list order book must match the tree", bookString(tree), bookString(skipList))
+		}
+
+		limit := tFloat64(1 + rnd.Intn(100))
+		if tree.Quantity(sell, limit) != skipList.Quantity(sell, limit) {
+			t.Fatal("skip list quantity must match the tree")
+		}
+	}
+
+	skipList.SetPriceIndex(IndexRBTree)
+
+	if _, ok := skipList.asks.index.(*treeIndex); !ok || bookString(tree) != bookString(skipList) {
+		t.Fatal("resting orders must be moved to the tree")
+	}
+}
+
+func BenchmarkPriceIndexWalk(b *testing.B) {
+	for name, index := range map[string]IndexType{
+		"RBTree":   IndexRBTree,
+		"SkipList": IndexSkipList,
+	} {
+		engine := NewEngine("apples", "dollars", WithPriceIndex(index))
+		wallet := newWallet()
+		updateWalletBalance(wallet, "apples", 1e6)
+
+		for i := 0; i < 1000; i++ {
+			engine.PushOrder(context.Background(), newOrder(strconv.Itoa(i), wallet, true, 1, float64(1+i)))
+		}
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				engine.Quantity(false, tFloat64(1000))
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package fastme
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Snapshot errors
+var (
+	ErrInvalidSnapshot = errors.New("Invalid order book snapshot")
+
+	ErrSnapshotMismatch = errors.New("Snapshot assets don't match the engine")
+)
+
+const snapshotVersion = 1
+
+// OrderRecord describes resting order stored in the snapshot
+type OrderRecord struct {
+	ID       string
+	Sell     bool
+	Price    Value
+	Quantity Value
+
+	// ClientOrderID is the client order ID of ClientOrder orders
+	ClientOrderID string
+}
+
+// OrderFactory restores values and orders from the snapshot. Wallets are
+// not stored in the snapshot, so the factory is responsible for resolving
+// the owner of the order
+type OrderFactory interface {
+	// Value parses value rendered by the engine Formatter
+	Value(string) (Value, error)
+
+	// Order creates resting order from the record
+	Order(context.Context, OrderRecord) (Order, error)
+}
+
+type snapshot struct {
+	Version   int             `json:"version"`
+	Base      Asset           `json:"base"`
+	Quote     Asset           `json:"quote"`
+	State     TradingState    `json:"state"`
+	TradeSeq  uint64          `json:"trade_seq"`
+	LastPrice *string         `json:"last_price,omitempty"`
+	Asks      []snapshotLevel `json:"asks"`
+	Bids      []snapshotLevel `json:"bids"`
+}
+
+type snapshotLevel struct {
+	Price  string          `json:"price"`
+	Orders []snapshotOrder `json:"orders"`
+}
+
+type snapshotOrder struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id,omitempty"`
+	Quantity string `json:"quantity"`
+}
+
+// Snapshot writes the order book state as JSON: resting orders grouped by
+// price levels in queue order, trading state and trade sequence. Values are
+// rendered by the engine Formatter. Wallet balances are not included.
+// Commands are executed while the snapshot is taken: price levels are read
+// one by one and levels changed in the meantime are written as they were
+// when the snapshot started, so the snapshot is consistent and doesn't stall
+// matching on large order books
+func (e *Engine) Snapshot(ctx context.Context, w io.Writer) error {
+	c := e.capture()
+	defer e.endCapture(c)
+
+	s := snapshot{
+		Version:  snapshotVersion,
+		Base:     e.base,
+		Quote:    e.quote,
+		State:    c.state,
+		TradeSeq: c.tradeSeq,
+		Asks:     e.snapshotLevels(c, c.asks),
+		Bids:     e.snapshotLevels(c, c.bids),
+	}
+
+	if c.lastPrice != nil {
+		price := c.formatter.Format(c.lastPrice)
+		s.LastPrice = &price
+	}
+
+	return json.NewEncoder(w).Encode(s)
+}
+
+// snapshotLevels reads captured levels, values are formatted outside the lock
+func (e *Engine) snapshotLevels(c *bookCapture, captured []*capturedLevel) []snapshotLevel {
+	levels := make([]snapshotLevel, 0, len(captured))
+	for _, l := range captured {
+		l = e.read(c, l, true)
+
+		level := snapshotLevel{
+			Price:  c.formatter.Format(l.price),
+			Orders: make([]snapshotOrder, 0, len(l.orders)),
+		}
+
+		for _, o := range l.orders {
+			level.Orders = append(level.Orders, snapshotOrder{
+				ID:       o.id,
+				ClientID: o.clientID,
+				Quantity: c.formatter.Format(o.quantity),
+			})
+		}
+
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// Restore replaces the order book with the snapshot written by Snapshot.
+// Orders are pushed without any calculations keeping the queue order, wallet
+// balances are not changed. The order book is left untouched on error.
+// Successful restore clears the corrupted state, see PanicError
+func (e *Engine) Restore(ctx context.Context, r io.Reader, factory OrderFactory) error {
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return err
+	}
+
+	if s.Version != snapshotVersion || int(s.State) >= len(tradingStateNames) {
+		return ErrInvalidSnapshot
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if s.Base != e.base || s.Quote != e.quote {
+		return ErrSnapshotMismatch
+	}
+
+	var (
+		orders = make(map[string]*list.Element)
+		asks   = newSide(e.newIndex())
+		bids   = newSide(e.newIndex())
+	)
+
+	restore := func(levels []snapshotLevel, sell bool, sd *side) error {
+		for _, level := range levels {
+			price, err := factory.Value(level.Price)
+			if err != nil {
+				return err
+			}
+
+			if price == nil || price.Sign() < 0 {
+				return ErrInvalidSnapshot
+			}
+
+			for _, so := range level.Orders {
+				quantity, err := factory.Value(so.Quantity)
+				if err != nil {
+					return err
+				}
+
+				if quantity == nil || quantity.Sign() <= 0 {
+					return ErrInvalidSnapshot
+				}
+
+				if _, ok := orders[so.ID]; ok {
+					return ErrOrderExists
+				}
+
+				o, err := factory.Order(ctx, OrderRecord{
+					ID:            so.ID,
+					Sell:          sell,
+					Price:         price,
+					Quantity:      quantity,
+					ClientOrderID: so.ClientID,
+				})
+				if err != nil {
+					return err
+				}
+
+				if o.ID() != so.ID || o.Sell() != sell || o.Price().Cmp(price) != 0 {
+					return ErrInvalidOrder
+				}
+
+				// Zero price level may hold typed limit orders only
+				if isMarket(o) || checkKind(o) != nil {
+					return ErrInvalidSnapshot
+				}
+
+				orders[so.ID] = sd.append(ctx, o)
+			}
+		}
+		return nil
+	}
+
+	if err := restore(s.Asks, true, asks); err != nil {
+		return err
+	}
+
+	if err := restore(s.Bids, false, bids); err != nil {
+		return err
+	}
+
+	var lastPrice Value
+	if s.LastPrice != nil {
+		price, err := factory.Value(*s.LastPrice)
+		if err != nil {
+			return err
+		}
+		lastPrice = price
+	}
+
+	e.orders = orders
+	e.reown()
+	e.corrupted = nil
+	e.asks = asks
+	e.bids = bids
+	e.hookDepth()
+	e.state = s.State
+	e.tradeSeq = s.TradeSeq
+	e.lastPrice = lastPrice
+
+	return nil
+}
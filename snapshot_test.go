@@ -0,0 +1,98 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+)
+
+type tOrderFactory struct {
+	owners map[string]*tWallet
+}
+
+func (t *tOrderFactory) Value(s string) (Value, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	return tFloat64(v), err
+}
+
+func (t *tOrderFactory) Order(ctx context.Context, r OrderRecord) (Order, error) {
+	return newOrder(
+		r.ID,
+		t.owners[r.ID],
+		r.Sell,
+		float64(r.Quantity.(tFloat64)),
+		float64(r.Price.(tFloat64)),
+	), nil
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	var (
+		processor        = &tTradeListener{tEventListener: newEventListener()}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine   = NewEngine(asset1, asset2)
+		restored = NewEngine(asset1, asset2)
+		factory  = &tOrderFactory{owners: map[string]*tWallet{
+			"1": wallet1, "2": wallet1, "3": wallet1, "4": wallet2,
+		}}
+
+		buf bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet2, false, 1, 5)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("5", wallet2, false, 1, 10)))
+
+	assertErr(t, engine.Snapshot(context.Background(), &buf))
+	assertErr(t, restored.Restore(context.Background(), bytes.NewReader(buf.Bytes()), factory))
+
+	if r, err := restored.Audit(context.Background()); err != nil || !r.OK() {
+		t.Fatal("restored order book must pass the audit", r.Violations)
+	}
+
+	var expected, actual []string
+	for _, o := range engine.Orders() {
+		expected = append(expected, o.ID()+":"+o.Quantity().Hash())
+	}
+	for _, o := range restored.Orders() {
+		actual = append(actual, o.ID()+":"+o.Quantity().Hash())
+	}
+
+	if len(actual) != 4 || len(expected) != len(actual) {
+		t.Fatal("invalid restored orders", actual)
+	}
+
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Fatal("invalid restored orders", actual)
+		}
+	}
+
+	if vol, _ := restored.Quantity(false, tFloat64(10)).(tFloat64); vol != 2 {
+		t.Fatal("invalid restored volume", vol)
+	}
+
+	processor.trades = nil
+	assertErr(t, restored.PlaceOrder(context.Background(), processor, newOrder("6", wallet2, false, 1, 10)))
+
+	if len(processor.trades) != 1 ||
+		processor.trades[0].ID != 2 ||
+		processor.trades[0].MakerOrder.ID() != "1" {
+		t.Fatal("trade sequence and queue order must be restored", processor.trades)
+	}
+
+	if err := NewEngine(asset2, asset1).Restore(
+		context.Background(),
+		bytes.NewReader(buf.Bytes()),
+		factory,
+	); err != ErrSnapshotMismatch {
+		t.Fatal("snapshot of another instrument must be rejected")
+	}
+}
@@ -0,0 +1,82 @@
+package fastme
+
+import "errors"
+
+// Symbol specification errors
+var (
+	ErrInvalidTick = errors.New("Order price is not a multiple of tick size")
+
+	ErrInvalidLot = errors.New("Order quantity is not a multiple of lot size")
+
+	ErrQuantityTooLarge = errors.New("Order quantity exceeds maximum quantity")
+
+	ErrPriceTooHigh = errors.New("Order price exceeds maximum price")
+)
+
+// SymbolSpec describes trading restrictions of the instrument
+type SymbolSpec struct {
+	// Tick is the minimum price increment, nil disables the check
+	Tick Value
+
+	// Lot is the minimum quantity increment, nil disables the check
+	Lot Value
+
+	// MaxQuantity is the maximum order quantity, nil disables the check
+	MaxQuantity Value
+
+	// MaxPrice is the maximum limit order price, nil disables the check
+	MaxPrice Value
+}
+
+// ModValue is an optional Value extension required to check tick and lot sizes.
+// Values which don't implement it are rejected when SymbolSpec is set
+type ModValue interface {
+	Value
+
+	// Mod is a "%" operation
+	Mod(Value) Value
+}
+
+// SetSymbolSpec updates instrument restrictions, nil removes them
+func (e *Engine) SetSymbolSpec(spec *SymbolSpec) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if spec == nil {
+		e.spec = nil
+		return
+	}
+
+	s := *spec
+	e.spec = &s
+}
+
+// checkSpec returns an error if price or quantity violates the symbol specification
+func (e *Engine) checkSpec(quantity, price Value) error {
+	if e.spec == nil {
+		return nil
+	}
+
+	if e.spec.MaxQuantity != nil && quantity.Cmp(e.spec.MaxQuantity) > 0 {
+		return invalidQuantity(quantity, ErrQuantityTooLarge)
+	}
+
+	if e.spec.MaxPrice != nil && price.Cmp(e.spec.MaxPrice) > 0 {
+		return invalidPrice(price, ErrPriceTooHigh)
+	}
+
+	if e.spec.Tick != nil && price.Sign() > 0 && !multipleOf(price, e.spec.Tick) {
+		return invalidPrice(price, ErrInvalidTick)
+	}
+
+	if e.spec.Lot != nil && !multipleOf(quantity, e.spec.Lot) {
+		return invalidQuantity(quantity, ErrInvalidLot)
+	}
+
+	return nil
+}
+
+func multipleOf(v, step Value) bool {
+	m, ok := v.(ModValue)
+	return ok && m.Mod(step).Sign() == 0
+}
@@ -0,0 +1,65 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSymbolSpec(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		order1 = newOrder("1", wallet1, true, 2, 10.5)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+
+	engine.SetSymbolSpec(&SymbolSpec{Tick: tFloat64(0.5), Lot: tFloat64(2)})
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 2, 10.25)); !errors.Is(err, ErrInvalidTick) {
+		t.Fatal("price must be a multiple of tick")
+	}
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 3, 10.5)); !errors.Is(err, ErrInvalidLot) {
+		t.Fatal("quantity must be a multiple of lot")
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, order1))
+
+	if _, err := engine.ReplaceOrder(context.Background(), nil, order1, newOrder("1", wallet1, true, 1, 10.5)); !errors.Is(err, ErrInvalidLot) {
+		t.Fatal("quantity must be a multiple of lot")
+	}
+
+	engine.SetSymbolSpec(nil)
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet1, true, 3, 10.25)))
+}
+
+func TestSymbolSpecBounds(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet1        = newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 100)
+
+	engine.SetSymbolSpec(&SymbolSpec{MaxQuantity: tFloat64(10), MaxPrice: tFloat64(1000)})
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 11, 10)); !errors.Is(err, ErrQuantityTooLarge) {
+		t.Fatal("quantity must not exceed maximum")
+	}
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 1, 1001)); !errors.Is(err, ErrPriceTooHigh) {
+		t.Fatal("price must not exceed maximum")
+	}
+
+	if err := engine.CanPlace(context.Background(), wallet1, true, tFloat64(11), tFloat64(10)); !errors.Is(err, ErrQuantityTooLarge) {
+		t.Fatal("quantity must not exceed maximum")
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 10, 1000)))
+}
@@ -0,0 +1,182 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+)
+
+// Trading state errors
+var (
+	ErrTradingHalted = errors.New("Trading is halted")
+
+	ErrCancelOnly = errors.New("Only cancellations are accepted")
+
+	ErrPostOnly = errors.New("Order would match in post-only state")
+
+	ErrInvalidState = errors.New("Invalid trading state")
+)
+
+// TradingState describes which operations the engine accepts
+type TradingState uint8
+
+// Trading states
+const (
+	// StateOpen is the continuous trading
+	StateOpen TradingState = iota
+
+	// StateHalted rejects order placement and replacement, cancellations are accepted
+	StateHalted
+
+	// StateCancelOnly rejects order placement, cancellations and same-price
+	// replacements are accepted
+	StateCancelOnly
+
+	// StatePostOnly rejects orders which would match immediately
+	StatePostOnly
+
+	// StateAuction accumulates orders without matching until the auction is uncrossed
+	StateAuction
+)
+
+var tradingStateNames = [...]string{
+	StateOpen:       "open",
+	StateHalted:     "halted",
+	StateCancelOnly: "cancel-only",
+	StatePostOnly:   "post-only",
+	StateAuction:    "auction",
+}
+
+// String returns trading state name
+func (s TradingState) String() string {
+	if int(s) < len(tradingStateNames) {
+		return tradingStateNames[s]
+	}
+	return "unknown"
+}
+
+// StateListener is an optional EventListener extension informing about trading state changes
+type StateListener interface {
+	OnTradingStateChanged(ctx context.Context, from, to TradingState)
+}
+
+// TradingState returns current trading state
+func (e *Engine) TradingState() TradingState {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return e.state
+}
+
+// SetTradingState switches engine to the given trading state. Leaving the
+// StateAuction state or resuming continuous trading uncrosses the order book
+// first, as orders may have been placed without matching (see CircuitBreaker)
+func (e *Engine) SetTradingState(
+	ctx context.Context,
+	listener EventListener,
+	state TradingState,
+) (err error) {
+	if int(state) >= len(tradingStateNames) {
+		return ErrInvalidState
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	defer e.guard(ctx, &err)
+
+	ctx = e.stamp(ctx)
+
+	if err := e.unusable(); err != nil {
+		return err
+	}
+
+	if err := e.record(ctx, journalState, "", nil, &state); err != nil {
+		return err
+	}
+
+	listener = e.listenerOf(listener)
+
+	if e.feeHandler == nil {
+		e.feeHandler = emptyFeeHandlerValue
+	}
+
+	if state != StateAuction &&
+		(e.state == StateAuction || state == StateOpen || state == StatePostOnly) {
+		e.uncrossBook(ctx, listener)
+	}
+
+	e.setState(ctx, listener, state)
+	return nil
+}
+
+func (e *Engine) setState(
+	ctx context.Context,
+	listener EventListener,
+	state TradingState,
+) {
+	if e.state == state {
+		return
+	}
+
+	from := e.state
+	e.state = state
+
+	e.log(ctx, LogInfo, "trading state changed",
+		LogField{"from", from},
+		LogField{"to", state},
+	)
+
+	if from == StateAuction {
+		e.expireAuctionOnly(ctx, listener)
+	}
+
+	if l, ok := listener.(StateListener); ok {
+		l.OnTradingStateChanged(ctx, from, state)
+	}
+}
+
+// checkPlace returns an error if incoming order is not accepted in current state
+func (e *Engine) checkPlace(o Order) error {
+	if e.state != StateAuction && isAuctionOnly(o) {
+		return ErrAuctionOnly
+	}
+
+	switch e.state {
+	case StateHalted:
+		return ErrTradingHalted
+
+	case StateCancelOnly:
+		return ErrCancelOnly
+
+	case StatePostOnly:
+		if e.crosses(o) {
+			return ErrPostOnly
+		}
+
+	case StateAuction:
+		if isMarket(o) {
+			return ErrAuctionMarketOrder
+		}
+	}
+
+	return nil
+}
+
+// crosses returns true if the order would match immediately
+func (e *Engine) crosses(o Order) bool {
+	if isMarket(o) {
+		return true
+	}
+
+	price := o.Price()
+	if price == nil {
+		return false
+	}
+
+	if o.Sell() {
+		best := e.bids.maxPrice()
+		return best != nil && best.price.Cmp(price) >= 0
+	}
+
+	best := e.asks.minPrice()
+	return best != nil && best.price.Cmp(price) <= 0
+}
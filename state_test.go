@@ -0,0 +1,85 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tStateListener struct {
+	*tEventListener
+	states []TradingState
+}
+
+func (t *tStateListener) OnTradingStateChanged(ctx context.Context, from, to TradingState) {
+	t.states = append(t.states, to)
+}
+
+func TestTradingStates(t *testing.T) {
+	var (
+		processor        = &tStateListener{tEventListener: newEventListener()}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		order1 = newOrder("1", wallet1, true, 2, 10)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, order1))
+
+	// Halted
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StateHalted))
+	if err := engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 10)); err != ErrTradingHalted {
+		t.Fatal("order must be rejected in halted state")
+	}
+	if _, err := engine.ReplaceOrder(context.Background(), processor, order1, newOrder("1", wallet1, true, 1, 10)); err != ErrTradingHalted {
+		t.Fatal("replace must be rejected in halted state")
+	}
+
+	// Cancel only
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StateCancelOnly))
+	if err := engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet1, true, 1, 10)); err != ErrCancelOnly {
+		t.Fatal("order must be rejected in cancel-only state")
+	}
+	_, err := engine.ReplaceOrder(context.Background(), processor, order1, newOrder("1", wallet1, true, 1, 10))
+	assertErr(t, err)
+
+	// Post only
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StatePostOnly))
+	if err := engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet2, false, 1, 10)); err != ErrPostOnly {
+		t.Fatal("crossing order must be rejected in post-only state")
+	}
+	if err := engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet2, false, 1, 0)); err != ErrPostOnly {
+		t.Fatal("market order must be rejected in post-only state")
+	}
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("5", wallet2, false, 1, 9)))
+
+	// Auction and back to open
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StateAuction))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("6", wallet2, false, 1, 11)))
+	assertErr(t, engine.SetTradingState(context.Background(), processor, StateOpen))
+
+	if engine.TradingState() != StateOpen || len(engine.Orders()) != 1 {
+		t.Fatal("auction must be uncrossed on leaving auction state")
+	}
+
+	if err := engine.SetTradingState(context.Background(), processor, TradingState(100)); err != ErrInvalidState {
+		t.Fatal("invalid state must be rejected")
+	}
+
+	want := []TradingState{StateHalted, StateCancelOnly, StatePostOnly, StateAuction, StateOpen}
+	if len(processor.states) != len(want) {
+		t.Fatal("invalid state events", processor.states)
+	}
+	for i := range want {
+		if processor.states[i] != want[i] {
+			t.Fatal("invalid state events", processor.states)
+		}
+	}
+
+	if StateCancelOnly.String() != "cancel-only" || TradingState(100).String() != "unknown" {
+		t.Fatal("invalid state name")
+	}
+}
@@ -0,0 +1,62 @@
+package fastme
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+)
+
+// StateHash returns the hex encoded SHA-256 digest of the state included in
+// Snapshot: resting orders with their prices and quantities in the
+// price-time order, trading state, last price and trade sequence. Values are
+// rendered by the engine Formatter. Replicas which executed the same
+// commands produce the same hash, so comparing hashes after every command
+// batch detects diverged replicas. Wallet balances are not included
+func (e *Engine) StateHash() string {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	h := sha256.New()
+
+	writeHashField(h, string(e.base))
+	writeHashField(h, string(e.quote))
+	writeHashUint(h, uint64(e.state))
+	writeHashUint(h, e.tradeSeq)
+
+	if e.lastPrice != nil {
+		writeHashField(h, e.format(e.lastPrice))
+	} else {
+		writeHashUint(h, 0)
+	}
+
+	for _, s := range []*side{e.asks, e.bids} {
+		writeHashUint(h, uint64(s.depth))
+		for _, q := range s.ascending() {
+			writeHashField(h, e.format(q.price))
+			writeHashUint(h, uint64(q.orders.Len()))
+
+			for el := q.orders.Front(); el != nil; el = el.Next() {
+				o := el.Value.(Order)
+				writeHashField(h, o.ID())
+				writeHashField(h, e.format(o.Quantity()))
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashField writes the length prefixed string, so adjacent fields
+// can't be confused. The length is offset by one to tell the empty string
+// from the absent value
+func writeHashField(h hash.Hash, s string) {
+	writeHashUint(h, uint64(len(s))+1)
+	h.Write([]byte(s))
+}
+
+func writeHashUint(h hash.Hash, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	h.Write(b[:])
+}
@@ -0,0 +1,51 @@
+package fastme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestStateHash(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine1  = NewEngine(asset1, asset2)
+		engine2  = NewEngine(asset1, asset2)
+		restored = NewEngine(asset1, asset2)
+
+		buf bytes.Buffer
+	)
+
+	updateWalletBalance(wallet1, asset1, 20)
+	updateWalletBalance(wallet2, asset2, 200)
+
+	if engine1.StateHash() != engine2.StateHash() {
+		t.Fatal("empty engines must have equal hashes")
+	}
+
+	for _, e := range []*Engine{engine1, engine2} {
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 2, 10)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 11)))
+		assertErr(t, e.PlaceOrder(context.Background(), nil, newOrder("3", wallet2, false, 1, 10)))
+	}
+
+	hash := engine1.StateHash()
+	if hash != engine2.StateHash() {
+		t.Fatal("replicas must have equal hashes")
+	}
+
+	assertErr(t, engine1.Snapshot(context.Background(), &buf))
+	assertErr(t, restored.Restore(context.Background(), bytes.NewReader(buf.Bytes()),
+		&tOrderFactory{owners: map[string]*tWallet{"1": wallet1, "2": wallet1}}))
+
+	if restored.StateHash() != hash {
+		t.Fatal("restored engine must have equal hash")
+	}
+
+	assertErr(t, engine2.ReduceOrder(context.Background(), nil, "2", tFloat64(2)))
+	if engine2.StateHash() == hash {
+		t.Fatal("diverged replica must have different hash")
+	}
+}
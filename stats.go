@@ -0,0 +1,74 @@
+package fastme
+
+// Stats describes the order book and the engine activity
+type Stats struct {
+	// Orders is the number of resting orders
+	Orders int
+
+	Asks SideStats
+	Bids SideStats
+
+	// Placed is the number of orders accepted for placement since start,
+	// including replacing orders
+	Placed uint64
+
+	// Canceled is the number of orders canceled since start, including
+	// canceled remainders of incoming orders
+	Canceled uint64
+
+	// Matched is the number of trades executed since start
+	Matched uint64
+
+	// Seq is the ID of the last trade, it's kept by snapshots unlike Matched
+	Seq uint64
+
+	// DepthSeq is the sequence number of the last DepthUpdate, it's advanced
+	// only while the depth listener is set
+	DepthSeq uint64
+}
+
+// SideStats describes the order book side
+type SideStats struct {
+	// Orders is the number of resting orders
+	Orders int
+
+	// Depth is the number of price levels
+	Depth int
+
+	// Volume is the total quantity of resting orders, nil if the side is empty
+	Volume Value
+}
+
+// activity counts engine events since start
+type activity struct {
+	placed   uint64
+	canceled uint64
+	matched  uint64
+}
+
+// Stats returns the order book and the engine activity counters
+func (e *Engine) Stats() Stats {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	return Stats{
+		Orders:   len(e.orders),
+		Asks:     e.asks.stats(),
+		Bids:     e.bids.stats(),
+		Placed:   e.activity.placed,
+		Canceled: e.activity.canceled,
+		Matched:  e.activity.matched,
+		Seq:      e.tradeSeq,
+		DepthSeq: e.depthSeq,
+	}
+}
+
+func (s *side) stats() SideStats {
+	st := SideStats{Orders: s.numOrders, Depth: s.depth}
+
+	for _, q := range s.ascending() {
+		st.Volume = q.volume.Add(st.Volume)
+	}
+
+	return st
+}
@@ -0,0 +1,80 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2)
+		feed             = NewDepthFeed(engine)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	if s := engine.Stats(); s.Orders != 0 || s.Asks.Volume != nil || s.Bids.Volume != nil {
+		t.Fatal("invalid stats of the empty engine", s)
+	}
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 2, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 3, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet2, false, 1, 5)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("6", wallet2, false, 2, 4)))
+	assertErr(t, engine.CancelOrderByID(context.Background(), nil, "6"))
+
+	if err := engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet2, false, 1, 5)); err != ErrOrderExists {
+		t.Fatal("duplicate order must be rejected", err)
+	}
+
+	s := engine.Stats()
+
+	if s.Orders != 4 || s.Placed != 6 || s.Canceled != 1 || s.Matched != 1 || s.Seq != 1 {
+		t.Fatal("invalid stats", s)
+	}
+
+	if s.Asks.Orders != 3 || s.Asks.Depth != 2 || s.Asks.Volume.Cmp(tFloat64(5)) != 0 {
+		t.Fatal("invalid asks stats", s.Asks)
+	}
+
+	if s.Bids.Orders != 1 || s.Bids.Depth != 1 || s.Bids.Volume.Cmp(tFloat64(1)) != 0 {
+		t.Fatal("invalid bids stats", s.Bids)
+	}
+
+	if sub := feed.Subscribe(1); s.DepthSeq == 0 || sub.Snapshot.Seq != s.DepthSeq {
+		t.Fatal("depth sequence must match the depth feed", s, sub.Snapshot.Seq)
+	}
+}
+
+func TestStatsCanceled(t *testing.T) {
+	for _, archived := range []bool{false, true} {
+		var (
+			asset1, asset2 = Asset("apples"), Asset("dollars")
+			wallet1        = newWallet()
+			engine         = NewEngine(asset1, asset2)
+		)
+
+		if archived {
+			engine.SetArchive(&Archive{Size: 1})
+		}
+
+		updateWalletBalance(wallet1, asset1, 10)
+
+		assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+		assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 1, 11)))
+		assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 12)))
+		assertErr(t, engine.CancelOrderByID(context.Background(), nil, "1"))
+
+		_, err := engine.Clear(context.Background(), nil, false)
+		assertErr(t, err)
+
+		if s := engine.Stats(); s.Canceled != 3 {
+			t.Fatal("canceled counter must not depend on the archive", archived, s)
+		}
+	}
+}
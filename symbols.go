@@ -0,0 +1,192 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Exchange errors
+var (
+	ErrSymbolExists = errors.New("Symbol is already registered")
+
+	ErrUnknownSymbol = errors.New("Unknown symbol")
+)
+
+// Symbol describes the trading pair
+type Symbol struct {
+	Base  Asset
+	Quote Asset
+
+	// Spec contains tick and lot sizes, nil disables the checks
+	Spec *SymbolSpec
+}
+
+// Name returns symbol name in the BASE/QUOTE form
+func (s Symbol) Name() string {
+	return string(s.Base) + "/" + string(s.Quote)
+}
+
+type eventSymbolKey struct{}
+
+// EventSymbol returns the symbol of the event passed by Exchange to the
+// listener within the context
+func EventSymbol(ctx context.Context) (string, bool) {
+	s, ok := ctx.Value(eventSymbolKey{}).(string)
+	return s, ok
+}
+
+// Exchange owns engines of multiple trading pairs and routes orders to them
+// by symbol name. Events of all engines are delivered to the single listener,
+// use EventSymbol to get the symbol of the event
+type Exchange struct {
+	engines  map[string]*Engine
+	listener EventListener
+	m        sync.RWMutex
+}
+
+// NewExchange creates exchange reporting events of all symbols to the
+// listener, nil listener is allowed
+func NewExchange(listener EventListener) *Exchange {
+	return &Exchange{
+		engines:  make(map[string]*Engine),
+		listener: listener,
+	}
+}
+
+// Register creates engine of the symbol configured by the options. The
+// symbol spec is applied after the options
+func (x *Exchange) Register(s Symbol, opts ...Option) (*Engine, error) {
+	x.m.Lock()
+	defer x.m.Unlock()
+
+	name := s.Name()
+	if _, ok := x.engines[name]; ok {
+		return nil, ErrSymbolExists
+	}
+
+	e := NewEngine(s.Base, s.Quote, opts...)
+	if s.Spec != nil {
+		e.SetSymbolSpec(s.Spec)
+	}
+
+	x.engines[name] = e
+	return e, nil
+}
+
+// Engine returns engine of the symbol
+func (x *Exchange) Engine(symbol string) (*Engine, error) {
+	x.m.RLock()
+	defer x.m.RUnlock()
+
+	e, ok := x.engines[symbol]
+	if !ok {
+		return nil, ErrUnknownSymbol
+	}
+	return e, nil
+}
+
+// Symbols returns sorted names of registered symbols
+func (x *Exchange) Symbols() []string {
+	x.m.RLock()
+	defer x.m.RUnlock()
+
+	symbols := make([]string, 0, len(x.engines))
+	for name := range x.engines {
+		symbols = append(symbols, name)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// route returns engine of the symbol and the event context
+func (x *Exchange) route(ctx context.Context, symbol string) (context.Context, *Engine, error) {
+	e, err := x.Engine(symbol)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return context.WithValue(ctx, eventSymbolKey{}, symbol), e, nil
+}
+
+// PlaceOrder places the order to the engine of the symbol
+func (x *Exchange) PlaceOrder(ctx context.Context, symbol string, o Order) error {
+	ctx, e, err := x.route(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	return e.PlaceOrder(ctx, x.listener, o)
+}
+
+// PlaceOrderReport places the order to the engine of the symbol and returns
+// the report
+func (x *Exchange) PlaceOrderReport(ctx context.Context, symbol string, o Order) (Report, error) {
+	ctx, e, err := x.route(ctx, symbol)
+	if err != nil {
+		return Report{Remaining: o.Quantity(), Status: StatusRejected}, err
+	}
+	return e.PlaceOrderReport(ctx, x.listener, o)
+}
+
+// ReplaceOrder replaces the order in the engine of the symbol
+func (x *Exchange) ReplaceOrder(ctx context.Context, symbol string, o, n Order) ([]Fill, error) {
+	ctx, e, err := x.route(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return e.ReplaceOrder(ctx, x.listener, o, n)
+}
+
+// CancelOrder removes the order with given ID from the engine of the symbol
+func (x *Exchange) CancelOrder(ctx context.Context, symbol, id string) error {
+	ctx, e, err := x.route(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	return e.CancelOrderByID(ctx, x.listener, id)
+}
+
+// CancelAll removes resting orders of the wallet from all engines. Returns
+// canceled orders by symbol, symbols without orders of the wallet are omitted
+func (x *Exchange) CancelAll(ctx context.Context, w Wallet) map[string][]Order {
+	canceled := make(map[string][]Order)
+	for _, symbol := range x.Symbols() {
+		ctx, e, err := x.route(ctx, symbol)
+		if err != nil {
+			continue
+		}
+
+		if orders := e.CancelAll(ctx, x.listener, w); len(orders) > 0 {
+			canceled[symbol] = orders
+		}
+	}
+	return canceled
+}
+
+// KillSwitch blocks the wallet and cancels its resting orders in all
+// registered engines, see Engine.KillSwitch. Returns canceled orders by
+// symbol, symbols without orders of the wallet are omitted
+func (x *Exchange) KillSwitch(ctx context.Context, w Wallet) map[string][]Order {
+	canceled := make(map[string][]Order)
+	for _, symbol := range x.Symbols() {
+		ctx, e, err := x.route(ctx, symbol)
+		if err != nil {
+			continue
+		}
+
+		if orders := e.KillSwitch(ctx, x.listener, w); len(orders) > 0 {
+			canceled[symbol] = orders
+		}
+	}
+	return canceled
+}
+
+// Unblock accepts orders of the wallet blocked by KillSwitch in all engines
+func (x *Exchange) Unblock(w Wallet) {
+	for _, symbol := range x.Symbols() {
+		if e, err := x.Engine(symbol); err == nil {
+			e.Unblock(w)
+		}
+	}
+}
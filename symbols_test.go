@@ -0,0 +1,73 @@
+package fastme
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tSymbolListener struct {
+	*tEventListener
+	symbols []string
+}
+
+func (t *tSymbolListener) OnExistingOrderCanceled(ctx context.Context, o Order) {
+	symbol, _ := EventSymbol(ctx)
+	t.symbols = append(t.symbols, symbol)
+	t.tEventListener.OnExistingOrderCanceled(ctx, o)
+}
+
+func TestExchange(t *testing.T) {
+	var (
+		wallet   = newWallet()
+		listener = &tSymbolListener{tEventListener: newEventListener()}
+		exchange = NewExchange(listener)
+	)
+
+	updateWalletBalance(wallet, "apples", 10)
+	updateWalletBalance(wallet, "pears", 10)
+
+	if _, err := exchange.Register(Symbol{Base: "apples", Quote: "dollars"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := exchange.Register(Symbol{
+		Base:  "pears",
+		Quote: "dollars",
+		Spec:  &SymbolSpec{Lot: tFloat64(2)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := exchange.Register(Symbol{Base: "apples", Quote: "dollars"}); err != ErrSymbolExists {
+		t.Fatal("symbol must be registered once")
+	}
+
+	if symbols := exchange.Symbols(); len(symbols) != 2 || symbols[0] != "apples/dollars" {
+		t.Fatal("invalid symbols", symbols)
+	}
+
+	assertErr(t, exchange.PlaceOrder(context.Background(), "apples/dollars", newOrder("1", wallet, true, 1, 10)))
+	assertErr(t, exchange.PlaceOrder(context.Background(), "pears/dollars", newOrder("1", wallet, true, 2, 10)))
+
+	if err := exchange.PlaceOrder(context.Background(), "pears/dollars", newOrder("2", wallet, true, 1, 10)); !errors.Is(err, ErrInvalidLot) {
+		t.Fatal("symbol spec must be applied")
+	}
+
+	if err := exchange.PlaceOrder(context.Background(), "plums/dollars", newOrder("3", wallet, true, 1, 10)); err != ErrUnknownSymbol {
+		t.Fatal("unknown symbol must be rejected")
+	}
+
+	canceled := exchange.CancelAll(context.Background(), wallet)
+	if len(canceled) != 2 || len(canceled["apples/dollars"]) != 1 || len(canceled["pears/dollars"]) != 1 {
+		t.Fatal("orders of all symbols must be canceled")
+	}
+
+	if len(listener.symbols) != 2 || listener.symbols[0] != "apples/dollars" || listener.symbols[1] != "pears/dollars" {
+		t.Fatal("events must carry the symbol", listener.symbols)
+	}
+
+	if walletBalance(wallet, "apples") != 10 || walletBalance(wallet, "pears") != 10 {
+		t.Fatal("assets must be refunded")
+	}
+}
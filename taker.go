@@ -0,0 +1,27 @@
+package fastme
+
+// TakerPolicy controls whether marketable limit orders, i.e. limit orders
+// matching on arrival, are handled as market orders
+type TakerPolicy uint8
+
+// Taker policies
+const (
+	// TakerPolicyLimit keeps limit order semantics: the unfilled remainder of
+	// the marketable limit order rests in the order book, also when the
+	// circuit breaker interrupts matching unless CircuitBreaker.Cancel is set.
+	// It's the default policy
+	TakerPolicyLimit TakerPolicy = iota
+
+	// TakerPolicyMarket handles marketable limit orders as market orders
+	// protected by the limit price: the unfilled remainder is dropped after
+	// partial execution or circuit breaker interruption and never rests in
+	// the order book
+	TakerPolicyMarket
+)
+
+// SetTakerPolicy updates marketable limit orders handling policy
+func (e *Engine) SetTakerPolicy(p TakerPolicy) {
+	e.m.Lock()
+	e.policy = p
+	e.m.Unlock()
+}
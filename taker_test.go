@@ -0,0 +1,48 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTakerPolicy(t *testing.T) {
+	var (
+		processor = &tCanceledListener{
+			tStateListener: &tStateListener{tEventListener: newEventListener()},
+		}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	engine.SetTakerPolicy(TakerPolicyMarket)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("1", wallet1, true, 1, 10)))
+
+	// Marketable limit order remainder is dropped
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("2", wallet2, false, 3, 12)))
+	if len(processor.canceled) != 1 ||
+		len(engine.Orders()) != 0 ||
+		walletBalance(wallet2, asset2) != 990 ||
+		walletInOrder(wallet2, asset2) != 0 {
+		t.Fatal("invalid result")
+	}
+
+	// Not marketable limit order rests in the order book
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("3", wallet2, false, 1, 9)))
+	if len(engine.Orders()) != 1 {
+		t.Fatal("invalid result")
+	}
+
+	engine.SetTakerPolicy(TakerPolicyLimit)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("4", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, newOrder("5", wallet2, false, 2, 11)))
+	if len(processor.canceled) != 1 || len(engine.Orders()) != 2 {
+		t.Fatal("invalid result")
+	}
+}
@@ -0,0 +1,169 @@
+package fastme
+
+import "time"
+
+// Default ticker statistics settings
+const (
+	DefaultTickerWindow = 24 * time.Hour
+	DefaultTickerBucket = time.Minute
+)
+
+// TickerStats configures rolling ticker statistics calculated from executed trades
+type TickerStats struct {
+	// Window is the rolling window length, DefaultTickerWindow if zero
+	Window time.Duration
+
+	// Bucket is the granularity of the window, DefaultTickerBucket if zero.
+	// Trades are aggregated by buckets, so memory and Ticker time don't depend
+	// on the number of trades. The window start is rounded down to the bucket
+	Bucket time.Duration
+}
+
+// Ticker is the trading statistics of the instrument. Window fields are nil
+// if there were no trades within the window or ticker statistics are not
+// enabled
+type Ticker struct {
+	// LastPrice is the price of the last trade, nil if there were no trades
+	LastPrice Value
+
+	// Open is the price of the first trade within the window
+	Open Value
+
+	// High and Low are the extreme prices within the window
+	High, Low Value
+
+	// Volume is the traded base asset quantity within the window
+	Volume Value
+
+	// Turnover is the traded quote asset amount within the window
+	Turnover Value
+
+	// Trades is the number of trades within the window
+	Trades int
+}
+
+type tickerBucket struct {
+	start            time.Time
+	open, high, low  Value
+	volume, turnover Value
+	trades           int
+}
+
+type tickerStats struct {
+	TickerStats
+	buckets []tickerBucket
+}
+
+// SetTickerStats enables rolling ticker statistics, nil disables them.
+// LastPrice of the Ticker is tracked regardless
+func (e *Engine) SetTickerStats(s *TickerStats) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if s == nil {
+		e.ticker = nil
+		return
+	}
+
+	t := &tickerStats{TickerStats: *s}
+	if t.Window <= 0 {
+		t.Window = DefaultTickerWindow
+	}
+
+	if t.Bucket <= 0 {
+		t.Bucket = DefaultTickerBucket
+	}
+
+	e.ticker = t
+}
+
+// Ticker returns the last trade price and rolling statistics
+func (e *Engine) Ticker() Ticker {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	t := Ticker{LastPrice: e.lastPrice}
+	if e.ticker == nil {
+		return t
+	}
+
+	start := e.ticker.windowStart(e.now())
+	for _, b := range e.ticker.buckets {
+		if b.start.Before(start) {
+			continue
+		}
+
+		if t.Open == nil {
+			t.Open = b.open
+		}
+
+		if t.High == nil || b.high.Cmp(t.High) > 0 {
+			t.High = b.high
+		}
+
+		if t.Low == nil || b.low.Cmp(t.Low) < 0 {
+			t.Low = b.low
+		}
+
+		t.Volume = b.volume.Add(t.Volume)
+		t.Turnover = b.turnover.Add(t.Turnover)
+		t.Trades += b.trades
+	}
+
+	return t
+}
+
+// windowStart returns start of the first bucket within the window
+func (t *tickerStats) windowStart(now time.Time) time.Time {
+	return now.Add(-t.Window).Truncate(t.Bucket)
+}
+
+// record adds executed trade to the current bucket
+func (t *tickerStats) record(now time.Time, price, quantity Value) {
+	var (
+		start = now.Truncate(t.Bucket)
+		last  = len(t.buckets) - 1
+	)
+
+	// Trades with event time before the current bucket are added to it
+	if last < 0 || t.buckets[last].start.Before(start) {
+		t.buckets = append(t.buckets, tickerBucket{
+			start: start,
+			open:  price,
+			high:  price,
+			low:   price,
+		})
+		last++
+	}
+
+	b := &t.buckets[last]
+	if price.Cmp(b.high) > 0 {
+		b.high = price
+	}
+
+	if price.Cmp(b.low) < 0 {
+		b.low = price
+	}
+
+	b.volume = quantity.Add(b.volume)
+	b.turnover = price.Mul(quantity).Add(b.turnover)
+	b.trades++
+
+	t.prune(now)
+}
+
+// prune removes buckets which are out of the window
+func (t *tickerStats) prune(now time.Time) {
+	var (
+		start = t.windowStart(now)
+		idx   int
+	)
+
+	for idx < len(t.buckets) && t.buckets[idx].start.Before(start) {
+		idx++
+	}
+
+	if idx > 0 {
+		t.buckets = append(t.buckets[:0], t.buckets[idx:]...)
+	}
+}
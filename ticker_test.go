@@ -0,0 +1,65 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTicker(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		now              = time.Date(2020, 1, 1, 0, 0, 30, 0, time.UTC)
+
+		engine = NewEngine(asset1, asset2,
+			WithClock(ClockFunc(func() time.Time { return now })),
+		)
+	)
+
+	updateWalletBalance(wallet1, asset1, 10)
+	updateWalletBalance(wallet2, asset2, 1000)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet2, false, 1, 10)))
+
+	if tk := engine.Ticker(); tk.LastPrice != tFloat64(10) || tk.Open != nil || tk.Trades != 0 {
+		t.Fatal("only the last price must be tracked by default", tk)
+	}
+
+	engine.SetTickerStats(&TickerStats{Window: time.Hour})
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 2, 12)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 2, 12)))
+
+	now = now.Add(30 * time.Minute)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("5", wallet1, true, 1, 9)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("6", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("7", wallet2, false, 2, 11)))
+
+	tk := engine.Ticker()
+	if tk.LastPrice != tFloat64(11) ||
+		tk.Open != tFloat64(12) ||
+		tk.High != tFloat64(12) ||
+		tk.Low != tFloat64(9) ||
+		tk.Volume != tFloat64(4) ||
+		tk.Turnover != tFloat64(44) ||
+		tk.Trades != 3 {
+		t.Fatal("invalid ticker", tk)
+	}
+
+	// The first bucket leaves the window
+	now = now.Add(31 * time.Minute)
+
+	tk = engine.Ticker()
+	if tk.Open != tFloat64(9) || tk.High != tFloat64(11) || tk.Volume != tFloat64(2) || tk.Trades != 2 {
+		t.Fatal("expired trades must be excluded", tk)
+	}
+
+	now = now.Add(time.Hour)
+
+	if tk = engine.Ticker(); tk.LastPrice != tFloat64(11) || tk.Open != nil || tk.Volume != nil {
+		t.Fatal("window must be empty", tk)
+	}
+}
@@ -0,0 +1,73 @@
+package fastme
+
+import "context"
+
+// Traced operations
+const (
+	SpanPlaceOrder   = "fastme.PlaceOrder"
+	SpanReplaceOrder = "fastme.ReplaceOrder"
+	SpanCancelOrder  = "fastme.CancelOrder"
+)
+
+// Tracer starts spans around matching operations. The span context is
+// passed to the listener and wallets, so nested spans are linked to the
+// operation. OpenTelemetry tracer is plugged in by a small adapter starting
+// trace.Span in Start and setting attributes from SpanInfo in End
+type Tracer interface {
+	Start(ctx context.Context, op string) (context.Context, Span)
+}
+
+// Span is the started operation span
+type Span interface {
+	End(SpanInfo)
+}
+
+// SpanInfo describes the traced operation
+type SpanInfo struct {
+	// OrderID is the incoming or canceled order ID
+	OrderID string
+
+	// Sell is the side of the order
+	Sell bool
+
+	// Fills is the number of matches
+	Fills int
+
+	// Levels is the number of price levels matched
+	Levels int
+
+	// Err is the operation error
+	Err error
+}
+
+type emptySpan struct{}
+
+func (emptySpan) End(SpanInfo) {}
+
+// SetTracer enables operation tracing, nil disables it
+func (e *Engine) SetTracer(t Tracer) {
+	e.m.Lock()
+	e.tracer = t
+	e.m.Unlock()
+}
+
+// trace starts span of the operation if the tracer is set
+func (e *Engine) trace(ctx context.Context, op string) (context.Context, Span) {
+	if e.tracer == nil {
+		return ctx, emptySpan{}
+	}
+	return e.tracer.Start(ctx, op)
+}
+
+// spanInfo describes the operation on the order
+func spanInfo(o Order, fills []Fill, err error) SpanInfo {
+	info := SpanInfo{OrderID: o.ID(), Sell: o.Sell(), Fills: len(fills), Err: err}
+
+	for i, f := range fills {
+		if i == 0 || f.Price.Cmp(fills[i-1].Price) != 0 {
+			info.Levels++
+		}
+	}
+
+	return info
+}
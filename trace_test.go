@@ -0,0 +1,58 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tSpanKey struct{}
+
+type tTracer struct {
+	ops   []string
+	infos []SpanInfo
+}
+
+func (t *tTracer) Start(ctx context.Context, op string) (context.Context, Span) {
+	t.ops = append(t.ops, op)
+	return context.WithValue(ctx, tSpanKey{}, op), t
+}
+
+func (t *tTracer) End(info SpanInfo) {
+	t.infos = append(t.infos, info)
+}
+
+func TestTracer(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		tracer = &tTracer{}
+	)
+
+	engine.SetTracer(tracer)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("1", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("2", wallet1, true, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("3", wallet1, true, 1, 11)))
+	assertErr(t, engine.PlaceOrder(context.Background(), nil, newOrder("4", wallet2, false, 3, 11)))
+
+	if err := engine.CancelOrderByID(context.Background(), nil, "1"); err != ErrOrderNotFound {
+		t.Fatal("filled order must not be found")
+	}
+
+	if len(tracer.infos) != 5 || tracer.ops[4] != SpanCancelOrder {
+		t.Fatal("all operations must be traced")
+	}
+
+	if info := tracer.infos[3]; info.OrderID != "4" || info.Sell || info.Fills != 3 || info.Levels != 2 {
+		t.Fatal("invalid span info", info)
+	}
+
+	if tracer.infos[4].Err != ErrOrderNotFound {
+		t.Fatal("span must record the error")
+	}
+}
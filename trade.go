@@ -0,0 +1,59 @@
+package fastme
+
+import (
+	"context"
+	"time"
+)
+
+// Trade describes single execution between two orders
+type Trade struct {
+	// ID is the sequence number of the trade unique within the engine
+	ID uint64
+
+	// MakerOrder is the resting order. Both orders are resting in the auction
+	// uncross, the sell order is reported as maker there
+	MakerOrder Order
+
+	// TakerOrder is the incoming order
+	TakerOrder Order
+
+	// Price is the execution price
+	Price Value
+
+	// Quantity is the executed quantity
+	Quantity Value
+
+	// Timestamp is the execution time
+	Timestamp time.Time
+}
+
+// TradeListener is an optional EventListener extension informing about
+// executed trades. OnTrade is called after the maker and taker events of the
+// trade, so orders quantities reflect the state after the execution
+type TradeListener interface {
+	OnTrade(ctx context.Context, t Trade)
+}
+
+// trade registers executed trade and notifies the listener
+func (e *Engine) trade(
+	ctx context.Context,
+	listener EventListener,
+	maker, taker Order,
+	price, quantity Value,
+) {
+	now := e.eventTime(ctx)
+	e.recordTrade(now, price, quantity)
+	e.tradeSeq++
+	e.activity.matched++
+
+	if l, ok := listener.(TradeListener); ok {
+		l.OnTrade(ctx, Trade{
+			ID:         e.tradeSeq,
+			MakerOrder: maker,
+			TakerOrder: taker,
+			Price:      price,
+			Quantity:   quantity,
+			Timestamp:  now,
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tTradeListener struct {
+	*tEventListener
+	trades []Trade
+}
+
+func (t *tTradeListener) OnTrade(ctx context.Context, tr Trade) {
+	t.trades = append(t.trades, tr)
+}
+
+func TestOnTrade(t *testing.T) {
+	var (
+		processor        = &tTradeListener{tEventListener: newEventListener()}
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+
+		engine = NewEngine(asset1, asset2)
+		order1 = newOrder("1", wallet1, true, 1, 10)
+		order2 = newOrder("2", wallet1, true, 2, 11)
+		order3 = newOrder("3", wallet2, false, 2, 11)
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 100)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, order1))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, order2))
+	assertErr(t, engine.PlaceOrder(context.Background(), processor, order3))
+
+	if len(processor.trades) != 2 {
+		t.Fatal("invalid trades count", len(processor.trades))
+	}
+
+	for i, tr := range processor.trades {
+		if tr.ID != uint64(i+1) || tr.TakerOrder != order3 || tr.Timestamp.IsZero() {
+			t.Fatal("invalid trade", tr)
+		}
+	}
+
+	if processor.trades[0].MakerOrder != order1 ||
+		processor.trades[0].Price.(tFloat64) != 10 ||
+		processor.trades[0].Quantity.(tFloat64) != 1 ||
+		processor.trades[1].MakerOrder != order2 ||
+		processor.trades[1].Price.(tFloat64) != 11 ||
+		processor.trades[1].Quantity.(tFloat64) != 1 {
+		t.Fatal("invalid trades", processor.trades)
+	}
+}
@@ -0,0 +1,218 @@
+package fastme
+
+import "context"
+
+// OrderView is the read-only copy of the order taken at the event time. It's
+// passed to listeners instead of the live order when order views are
+// enabled, see SetOrderViews. UpdateQuantity panics, so listeners can't
+// change the order book. Client order ID and metadata of the order are kept,
+// the order itself is not reachable from the view
+type OrderView struct {
+	id       string
+	owner    Wallet
+	side     Side
+	kind     OrderKind
+	price    Value
+	quantity Value
+	clientID string
+	meta     interface{}
+}
+
+// ViewOf returns the read-only copy of the order
+func ViewOf(o Order) *OrderView {
+	if v, ok := o.(*OrderView); ok {
+		return v
+	}
+
+	v := &OrderView{
+		id:       o.ID(),
+		owner:    o.Owner(),
+		price:    o.Price(),
+		quantity: o.Quantity(),
+		clientID: OrderClientID(o),
+		meta:     OrderMetadata(o),
+	}
+
+	if o.Sell() {
+		v.side = SideSell
+	}
+
+	if isMarket(o) {
+		v.kind = OrderKindMarket
+	}
+
+	return v
+}
+
+// ID returns the order ID
+func (v *OrderView) ID() string {
+	return v.id
+}
+
+// Owner returns the wallet of the order
+func (v *OrderView) Owner() Wallet {
+	return v.owner
+}
+
+// Sell returns true for sell orders
+func (v *OrderView) Sell() bool {
+	return v.side == SideSell
+}
+
+// Price returns the order price
+func (v *OrderView) Price() Value {
+	return v.price
+}
+
+// Quantity returns the remaining quantity at the event time
+func (v *OrderView) Quantity() Value {
+	return v.quantity
+}
+
+// UpdateQuantity panics, the view is read-only
+func (v *OrderView) UpdateQuantity(Value) {
+	panic("fastme: UpdateQuantity called on read-only OrderView " + v.id)
+}
+
+// Side returns the order side
+func (v *OrderView) Side() Side {
+	return v.side
+}
+
+// Kind returns the order kind
+func (v *OrderView) Kind() OrderKind {
+	return v.kind
+}
+
+// ClientOrderID returns the client order ID of the order
+func (v *OrderView) ClientOrderID() string {
+	return v.clientID
+}
+
+// Metadata returns the metadata of the order
+func (v *OrderView) Metadata() interface{} {
+	return v.meta
+}
+
+// SetOrderViews enables passing OrderView copies to listeners instead of
+// live orders, so listeners can't corrupt the order book and asynchronous
+// listeners see the order as it was at the event time. Every event
+// allocates the copy
+func (e *Engine) SetOrderViews(enabled bool) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.views = enabled
+}
+
+// listenerOf returns the listener of the command: the given one or the
+// default one, wrapped to pass order views if enabled
+func (e *Engine) listenerOf(listener EventListener) EventListener {
+	if listener == nil {
+		listener = e.listener
+	}
+
+	if _, ok := listener.(*viewListener); ok || !e.views {
+		return listener
+	}
+	return &viewListener{listener}
+}
+
+// viewListener passes order views to the listener. Optional EventListener
+// extensions are forwarded if the listener implements them
+type viewListener struct {
+	l EventListener
+}
+
+func (v *viewListener) OnIncomingOrderPartial(ctx context.Context, o Order, vol Volume) {
+	v.l.OnIncomingOrderPartial(ctx, ViewOf(o), vol)
+}
+
+func (v *viewListener) OnIncomingOrderDone(ctx context.Context, o Order, vol Volume) {
+	v.l.OnIncomingOrderDone(ctx, ViewOf(o), vol)
+}
+
+func (v *viewListener) OnIncomingOrderPlaced(ctx context.Context, o Order) {
+	v.l.OnIncomingOrderPlaced(ctx, ViewOf(o))
+}
+
+func (v *viewListener) OnExistingOrderPartial(ctx context.Context, o Order, vol Volume) {
+	v.l.OnExistingOrderPartial(ctx, ViewOf(o), vol)
+}
+
+func (v *viewListener) OnExistingOrderDone(ctx context.Context, o Order, vol Volume) {
+	v.l.OnExistingOrderDone(ctx, ViewOf(o), vol)
+}
+
+func (v *viewListener) OnExistingOrderCanceled(ctx context.Context, o Order) {
+	v.l.OnExistingOrderCanceled(ctx, ViewOf(o))
+}
+
+func (v *viewListener) OnBalanceChanged(ctx context.Context, w Wallet, a Asset, val Value) {
+	v.l.OnBalanceChanged(ctx, w, a, val)
+}
+
+func (v *viewListener) OnInOrderChanged(ctx context.Context, w Wallet, a Asset, val Value) {
+	v.l.OnInOrderChanged(ctx, w, a, val)
+}
+
+func (v *viewListener) OnIncomingOrderCanceled(ctx context.Context, o Order) {
+	if l, ok := v.l.(IncomingCanceledListener); ok {
+		l.OnIncomingOrderCanceled(ctx, ViewOf(o))
+	}
+}
+
+func (v *viewListener) OnExistingOrderExpired(ctx context.Context, o Order) {
+	if l, ok := v.l.(ExpiredListener); ok {
+		l.OnExistingOrderExpired(ctx, ViewOf(o))
+	}
+}
+
+func (v *viewListener) OnExistingOrderUnderfunded(ctx context.Context, o Order) {
+	if l, ok := v.l.(UnderfundedListener); ok {
+		l.OnExistingOrderUnderfunded(ctx, ViewOf(o))
+	}
+}
+
+func (v *viewListener) OnExistingOrderReduced(ctx context.Context, o Order, reduced Value) {
+	if l, ok := v.l.(ReducedListener); ok {
+		l.OnExistingOrderReduced(ctx, ViewOf(o), reduced)
+	}
+}
+
+func (v *viewListener) OnTradingStateChanged(ctx context.Context, from, to TradingState) {
+	if l, ok := v.l.(StateListener); ok {
+		l.OnTradingStateChanged(ctx, from, to)
+	}
+}
+
+func (v *viewListener) OnMakerProtectionTriggered(ctx context.Context, w Wallet) {
+	if l, ok := v.l.(ProtectionListener); ok {
+		l.OnMakerProtectionTriggered(ctx, w)
+	}
+}
+
+func (v *viewListener) OnOrderRejected(ctx context.Context, o Order, err error) {
+	if l, ok := v.l.(RejectedListener); ok {
+		l.OnOrderRejected(ctx, ViewOf(o), err)
+	}
+}
+
+func (v *viewListener) OnFeeCharged(ctx context.Context, o Order, f Fee) {
+	if l, ok := v.l.(FeeListener); ok {
+		l.OnFeeCharged(ctx, ViewOf(o), f)
+	}
+}
+
+func (v *viewListener) OnRebatePaid(ctx context.Context, o Order, a Asset, val Value) {
+	if l, ok := v.l.(RebateListener); ok {
+		l.OnRebatePaid(ctx, ViewOf(o), a, val)
+	}
+}
+
+func (v *viewListener) OnTrade(ctx context.Context, t Trade) {
+	if l, ok := v.l.(TradeListener); ok {
+		t.MakerOrder, t.TakerOrder = ViewOf(t.MakerOrder), ViewOf(t.TakerOrder)
+		l.OnTrade(ctx, t)
+	}
+}
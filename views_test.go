@@ -0,0 +1,77 @@
+package fastme
+
+import (
+	"context"
+	"testing"
+)
+
+type tViewListener struct {
+	tEventListener
+	existing []Order
+	trades   []Trade
+}
+
+func (t *tViewListener) OnExistingOrderPartial(ctx context.Context, o Order, v Volume) {
+	t.existing = append(t.existing, o)
+}
+
+func (t *tViewListener) OnTrade(ctx context.Context, tr Trade) {
+	t.trades = append(t.trades, tr)
+}
+
+func TestOrderViews(t *testing.T) {
+	var (
+		asset1, asset2   = Asset("apples"), Asset("dollars")
+		wallet1, wallet2 = newWallet(), newWallet()
+		engine           = NewEngine(asset1, asset2, WithOrderViews())
+		listener         = new(tViewListener)
+		maker            = &tClientOrder{tOrder: newOrder("1", wallet1, true, 3, 10), clientID: "a", meta: 42}
+	)
+
+	updateWalletBalance(wallet1, asset1, 3)
+	updateWalletBalance(wallet2, asset2, 30)
+
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, maker))
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("2", wallet2, false, 1, 10)))
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("3", wallet2, false, 1, 10)))
+
+	if len(listener.existing) != 2 || len(listener.trades) != 2 {
+		t.Fatal("invalid number of events", listener.existing, listener.trades)
+	}
+
+	for _, o := range append(listener.existing, listener.trades[0].MakerOrder, listener.trades[0].TakerOrder) {
+		if _, ok := o.(*OrderView); !ok {
+			t.Fatalf("listener must receive order view, got %T", o)
+		}
+	}
+
+	v := listener.existing[0]
+	if v.Quantity().Cmp(tFloat64(2)) != 0 || maker.Quantity().Cmp(tFloat64(1)) != 0 {
+		t.Fatal("view quantity must be frozen at the event time", v.Quantity(), maker.Quantity())
+	}
+
+	if v.ID() != "1" || v.Owner() != wallet1 || !v.Sell() || OrderClientID(v) != "a" || OrderMetadata(v) != 42 {
+		t.Fatal("view must copy the order", v)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("UpdateQuantity of the view must panic")
+			}
+		}()
+		v.UpdateQuantity(tFloat64(100))
+	}()
+
+	if o, err := engine.FindOrder("1"); err != nil || o != maker || o.Quantity().Cmp(tFloat64(1)) != 0 {
+		t.Fatal("order book must be intact", o, err)
+	}
+
+	engine.SetOrderViews(false)
+	listener.existing = nil
+
+	assertErr(t, engine.PlaceOrder(context.Background(), listener, newOrder("4", wallet2, false, 0.5, 10)))
+	if len(listener.existing) != 1 || listener.existing[0] != maker {
+		t.Fatal("listener must receive live order if views are disabled", listener.existing)
+	}
+}
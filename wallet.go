@@ -0,0 +1,124 @@
+package fastme
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryWallet is the ready to use thread-safe in-memory wallet of any
+// number of assets. It implements Reserver, so balance changes made by the
+// engine are atomic and Deposit and Withdraw may be called concurrently
+// with commands
+type MemoryWallet struct {
+	zero    Value
+	balance map[Asset]Value
+	inOrder map[Asset]Value
+	m       sync.RWMutex
+}
+
+// NewMemoryWallet creates the empty wallet. Zero is the zero value of the
+// Value implementation used by the engine, it's returned for absent assets
+func NewMemoryWallet(zero Value) *MemoryWallet {
+	return &MemoryWallet{
+		zero:    zero,
+		balance: make(map[Asset]Value),
+		inOrder: make(map[Asset]Value),
+	}
+}
+
+// Balance returns the balance of the asset available for new orders
+func (w *MemoryWallet) Balance(ctx context.Context, a Asset) Value {
+	w.m.RLock()
+	defer w.m.RUnlock()
+
+	return w.get(w.balance, a)
+}
+
+// UpdateBalance sets the balance of the asset
+func (w *MemoryWallet) UpdateBalance(ctx context.Context, a Asset, v Value) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.balance[a] = v
+}
+
+// InOrder returns the amount of the asset reserved by resting orders
+func (w *MemoryWallet) InOrder(ctx context.Context, a Asset) Value {
+	w.m.RLock()
+	defer w.m.RUnlock()
+
+	return w.get(w.inOrder, a)
+}
+
+// UpdateInOrder sets the amount of the asset reserved by resting orders
+func (w *MemoryWallet) UpdateInOrder(ctx context.Context, a Asset, v Value) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.inOrder[a] = v
+}
+
+// Reserve moves v of the asset from the balance to the in-order amount.
+// Returns false if the balance is insufficient
+func (w *MemoryWallet) Reserve(ctx context.Context, a Asset, v Value) bool {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	balance := w.get(w.balance, a)
+	if balance.Cmp(v) < 0 {
+		return false
+	}
+
+	w.balance[a] = balance.Sub(v)
+	w.inOrder[a] = w.get(w.inOrder, a).Add(v)
+	return true
+}
+
+// Commit debits dv of the debit asset from the in-order amount and credits
+// cv of the credit asset to the balance
+func (w *MemoryWallet) Commit(ctx context.Context, debit Asset, dv Value, credit Asset, cv Value) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.inOrder[debit] = w.get(w.inOrder, debit).Sub(dv)
+	w.balance[credit] = w.get(w.balance, credit).Add(cv)
+}
+
+// Release moves v of the asset from the in-order amount back to the balance
+func (w *MemoryWallet) Release(ctx context.Context, a Asset, v Value) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.inOrder[a] = w.get(w.inOrder, a).Sub(v)
+	w.balance[a] = w.get(w.balance, a).Add(v)
+}
+
+// Deposit adds v to the balance of the asset
+func (w *MemoryWallet) Deposit(a Asset, v Value) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.balance[a] = w.get(w.balance, a).Add(v)
+}
+
+// Withdraw subtracts v from the balance of the asset. Returns false if the
+// balance is insufficient, the amount in order can't be withdrawn
+func (w *MemoryWallet) Withdraw(a Asset, v Value) bool {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	balance := w.get(w.balance, a)
+	if balance.Cmp(v) < 0 {
+		return false
+	}
+
+	w.balance[a] = balance.Sub(v)
+	return true
+}
+
+func (w *MemoryWallet) get(values map[Asset]Value, a Asset) Value {
+	if v, ok := values[a]; ok {
+		return v
+	}
+	return w.zero
+}
@@ -0,0 +1,73 @@
+package fastme
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestMemoryWallet(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = NewMemoryWallet(tFloat64(0))
+		ctx            = context.Background()
+	)
+
+	if wallet.Balance(ctx, asset1) != tFloat64(0) || wallet.InOrder(ctx, asset1) != tFloat64(0) {
+		t.Fatal("absent asset must be zero")
+	}
+
+	wallet.Deposit(asset1, tFloat64(10))
+
+	if wallet.Reserve(ctx, asset1, tFloat64(11)) {
+		t.Fatal("reservation must not exceed the balance")
+	}
+
+	if !wallet.Reserve(ctx, asset1, tFloat64(4)) {
+		t.Fatal("reservation must be accepted")
+	}
+
+	wallet.Commit(ctx, asset1, tFloat64(3), asset2, tFloat64(30))
+	wallet.Release(ctx, asset1, tFloat64(1))
+
+	if wallet.Balance(ctx, asset1) != tFloat64(7) || wallet.InOrder(ctx, asset1) != tFloat64(0) ||
+		wallet.Balance(ctx, asset2) != tFloat64(30) {
+		t.Fatal("invalid balances", wallet.Balance(ctx, asset1), wallet.InOrder(ctx, asset1), wallet.Balance(ctx, asset2))
+	}
+
+	if wallet.Withdraw(asset2, tFloat64(31)) || !wallet.Withdraw(asset2, tFloat64(30)) {
+		t.Fatal("withdrawal must not exceed the balance")
+	}
+}
+
+func TestMemoryWalletConcurrent(t *testing.T) {
+	var (
+		asset1, asset2 = Asset("apples"), Asset("dollars")
+		wallet         = NewMemoryWallet(tFloat64(0))
+		engine         = NewEngine(asset1, asset2)
+		wg             sync.WaitGroup
+	)
+
+	wallet.Deposit(asset1, tFloat64(100))
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			wallet.Deposit(asset2, tFloat64(1))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		o := NewLimitOrder(strconv.Itoa(i), wallet, SideSell, tFloat64(10), tFloat64(1))
+		assertErr(t, engine.PlaceOrder(context.Background(), nil, o))
+	}
+
+	wg.Wait()
+
+	if wallet.Balance(context.Background(), asset2) != tFloat64(100) ||
+		wallet.InOrder(context.Background(), asset1) != tFloat64(100) {
+		t.Fatal("concurrent deposits must not be lost")
+	}
+}
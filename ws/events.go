@@ -0,0 +1,81 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/newity/fastme"
+)
+
+// OnIncomingOrderPartial schedules depth update
+func (f *Feed) OnIncomingOrderPartial(context.Context, fastme.Order, fastme.Volume) {
+	f.touch(nil)
+}
+
+// OnIncomingOrderDone schedules depth update
+func (f *Feed) OnIncomingOrderDone(context.Context, fastme.Order, fastme.Volume) {
+	f.touch(nil)
+}
+
+// OnIncomingOrderPlaced schedules depth update
+func (f *Feed) OnIncomingOrderPlaced(context.Context, fastme.Order) {
+	f.touch(nil)
+}
+
+// OnExistingOrderPartial schedules depth update
+func (f *Feed) OnExistingOrderPartial(context.Context, fastme.Order, fastme.Volume) {
+	f.touch(nil)
+}
+
+// OnExistingOrderDone schedules depth update
+func (f *Feed) OnExistingOrderDone(context.Context, fastme.Order, fastme.Volume) {
+	f.touch(nil)
+}
+
+// OnExistingOrderCanceled schedules depth update
+func (f *Feed) OnExistingOrderCanceled(context.Context, fastme.Order) {
+	f.touch(nil)
+}
+
+// OnExistingOrderExpired schedules depth update
+func (f *Feed) OnExistingOrderExpired(context.Context, fastme.Order) {
+	f.touch(nil)
+}
+
+// OnExistingOrderReduced schedules depth update
+func (f *Feed) OnExistingOrderReduced(context.Context, fastme.Order, fastme.Value) {
+	f.touch(nil)
+}
+
+// OnBalanceChanged schedules depth update, amended orders are reported by
+// wallet events only
+func (f *Feed) OnBalanceChanged(context.Context, fastme.Wallet, fastme.Asset, fastme.Value) {
+	f.touch(nil)
+}
+
+// OnInOrderChanged schedules depth update
+func (f *Feed) OnInOrderChanged(context.Context, fastme.Wallet, fastme.Asset, fastme.Value) {
+	f.touch(nil)
+}
+
+// OnTrade broadcasts the trade
+func (f *Feed) OnTrade(ctx context.Context, t fastme.Trade) {
+	msg := TradeMessage{
+		Type:     TypeTrade,
+		ID:       t.ID,
+		Price:    f.format(t.Price),
+		Quantity: f.format(t.Quantity),
+		Time:     t.Timestamp,
+	}
+
+	if t.MakerOrder != nil {
+		msg.MakerOrderID = t.MakerOrder.ID()
+	}
+
+	if t.TakerOrder != nil {
+		msg.TakerOrderID = t.TakerOrder.ID()
+	}
+
+	data, _ := json.Marshal(msg)
+	f.touch(data)
+}
@@ -0,0 +1,388 @@
+// Package ws serves live market data of the engine over WebSocket. Clients
+// subscribe to L2 depth and trades per connection. Depth subscription starts
+// with the order book snapshot followed by sequenced deltas of changed levels.
+// The package has no dependencies besides the engine
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+// Channels
+const (
+	ChannelDepth  = "depth"
+	ChannelTrades = "trades"
+)
+
+// Request operations
+const (
+	OpSubscribe   = "subscribe"
+	OpUnsubscribe = "unsubscribe"
+)
+
+// Message types
+const (
+	TypeSnapshot = "snapshot"
+	TypeDepth    = "depth"
+	TypeTrade    = "trade"
+	TypeError    = "error"
+)
+
+// Request is the client message, e.g. {"op": "subscribe", "channel": "depth"}
+type Request struct {
+	Op      string `json:"op"`
+	Channel string `json:"channel"`
+}
+
+// DepthMessage is the order book snapshot or delta. Snapshot contains all
+// levels up to the feed depth sorted from the best price. Delta contains
+// changed levels only, removed levels have "0" quantity. Delta Seq follows
+// the previous message Seq without gaps. Checksum is fastme.L2Book.Checksum
+// of all levels of the book after the message is applied
+type DepthMessage struct {
+	Type     string      `json:"type"`
+	Seq      uint64      `json:"seq"`
+	Asks     [][2]string `json:"asks"`
+	Bids     [][2]string `json:"bids"`
+	Checksum uint32      `json:"checksum"`
+}
+
+// TradeMessage is the executed trade
+type TradeMessage struct {
+	Type         string    `json:"type"`
+	ID           uint64    `json:"id"`
+	MakerOrderID string    `json:"maker_order_id"`
+	TakerOrderID string    `json:"taker_order_id"`
+	Price        string    `json:"price"`
+	Quantity     string    `json:"quantity"`
+	Time         time.Time `json:"time"`
+}
+
+// ErrorMessage reports invalid request
+type ErrorMessage struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// Config configures the feed
+type Config struct {
+	// Depth is the number of levels per side, all levels are sent if it's 0
+	Depth int
+
+	// Buffer is the number of messages queued per connection, slow
+	// connections are closed when it's full. 256 by default
+	Buffer int
+
+	// Formatter renders trade values and must match the engine Formatter.
+	// Values are rendered by Hash if it's nil
+	Formatter fastme.Formatter
+}
+
+type frame struct {
+	op   byte
+	data []byte
+}
+
+type client struct {
+	conn   *conn
+	out    chan frame
+	depth  bool
+	trades bool
+}
+
+// Feed is the fastme.EventListener and http.Handler serving market data of
+// the engine. The feed must receive events of all engine commands, use
+// fastme.ListenerMux to combine it with other listeners. Messages are built
+// outside of the engine lock, so the engine is never blocked by clients
+type Feed struct {
+	engine *fastme.Engine
+	cfg    Config
+
+	pm      sync.Mutex
+	pending [][]byte
+	dirty   bool
+	signal  chan struct{}
+
+	m       sync.Mutex
+	clients map[*client]struct{}
+	book    fastme.L2Book
+	seq     uint64
+	closed  bool
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewFeed creates the feed of the engine and starts broadcasting
+func NewFeed(e *fastme.Engine, cfg Config) *Feed {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 256
+	}
+
+	f := &Feed{
+		engine:  e,
+		cfg:     cfg,
+		signal:  make(chan struct{}, 1),
+		clients: make(map[*client]struct{}),
+		book:    e.L2(cfg.Depth),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go f.run()
+	return f
+}
+
+// Close stops broadcasting and closes all connections
+func (f *Feed) Close() {
+	close(f.done)
+	<-f.stopped
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.closed = true
+	for c := range f.clients {
+		f.remove(c)
+	}
+}
+
+// Refresh schedules depth update. It must be called after the order book is
+// changed without events, e.g. by Engine.Restore or Engine.PushOrder
+func (f *Feed) Refresh() {
+	f.touch(nil)
+}
+
+// touch marks the order book changed and queues the message
+func (f *Feed) touch(msg []byte) {
+	f.pm.Lock()
+	f.dirty = true
+	if msg != nil {
+		f.pending = append(f.pending, msg)
+	}
+	f.pm.Unlock()
+
+	select {
+	case f.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (f *Feed) run() {
+	defer close(f.stopped)
+
+	for {
+		select {
+		case <-f.signal:
+		case <-f.done:
+			return
+		}
+
+		f.pm.Lock()
+		pending, dirty := f.pending, f.dirty
+		f.pending, f.dirty = nil, false
+		f.pm.Unlock()
+
+		var book fastme.L2Book
+		if dirty {
+			book = f.engine.L2(f.cfg.Depth)
+		}
+
+		f.m.Lock()
+		for _, msg := range pending {
+			f.broadcast(func(c *client) bool { return c.trades }, msg)
+		}
+
+		if dirty {
+			delta := DepthMessage{
+				Type: TypeDepth,
+				Asks: diff(f.book.Asks, book.Asks),
+				Bids: diff(f.book.Bids, book.Bids),
+			}
+
+			if len(delta.Asks) > 0 || len(delta.Bids) > 0 {
+				f.seq++
+				f.book = book
+
+				delta.Seq = f.seq
+				delta.Checksum = book.Checksum(0)
+				msg, _ := json.Marshal(delta)
+				f.broadcast(func(c *client) bool { return c.depth }, msg)
+			}
+		}
+		f.m.Unlock()
+	}
+}
+
+// diff returns levels of b changed since a
+func diff(a, b [][2]string) [][2]string {
+	prev := make(map[string]string, len(a))
+	for _, level := range a {
+		prev[level[0]] = level[1]
+	}
+
+	levels := [][2]string{}
+	for _, level := range b {
+		if quantity, ok := prev[level[0]]; !ok || quantity != level[1] {
+			levels = append(levels, level)
+		}
+		delete(prev, level[0])
+	}
+
+	for _, level := range a {
+		if _, ok := prev[level[0]]; ok {
+			levels = append(levels, [2]string{level[0], "0"})
+		}
+	}
+
+	return levels
+}
+
+// broadcast must be called with f.m locked
+func (f *Feed) broadcast(subscribed func(*client) bool, msg []byte) {
+	for c := range f.clients {
+		if subscribed(c) {
+			f.send(c, frame{op: opText, data: msg})
+		}
+	}
+}
+
+// send must be called with f.m locked. Slow client is closed
+func (f *Feed) send(c *client, fr frame) {
+	if _, ok := f.clients[c]; !ok {
+		return
+	}
+
+	select {
+	case c.out <- fr:
+	default:
+		f.remove(c)
+		c.conn.close()
+	}
+}
+
+// remove must be called with f.m locked
+func (f *Feed) remove(c *client) {
+	if _, ok := f.clients[c]; ok {
+		delete(f.clients, c)
+		close(c.out)
+	}
+}
+
+func (f *Feed) sendJSON(c *client, v interface{}) {
+	msg, _ := json.Marshal(v)
+	f.send(c, frame{op: opText, data: msg})
+}
+
+// ServeHTTP upgrades the connection and serves client subscriptions
+func (f *Feed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+
+	c := &client{conn: conn, out: make(chan frame, f.cfg.Buffer)}
+
+	f.m.Lock()
+	if f.closed {
+		f.m.Unlock()
+		conn.close()
+		return
+	}
+	f.clients[c] = struct{}{}
+	f.m.Unlock()
+
+	go write(c)
+
+	defer func() {
+		f.m.Lock()
+		f.remove(c)
+		f.m.Unlock()
+	}()
+
+	for {
+		op, payload, err := conn.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch op {
+		case opText:
+			f.handle(c, payload)
+		case opPing:
+			f.m.Lock()
+			f.send(c, frame{op: opPong, data: payload})
+			f.m.Unlock()
+		case opPong:
+		default:
+			return
+		}
+	}
+}
+
+func (f *Feed) handle(c *client, payload []byte) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	var req Request
+	if err := json.Unmarshal(payload, &req); err != nil {
+		f.sendJSON(c, ErrorMessage{Type: TypeError, Error: err.Error()})
+		return
+	}
+
+	subscribe := req.Op == OpSubscribe
+	if !subscribe && req.Op != OpUnsubscribe {
+		f.sendJSON(c, ErrorMessage{Type: TypeError, Error: "Unknown operation"})
+		return
+	}
+
+	switch req.Channel {
+	case ChannelDepth:
+		if subscribe && !c.depth {
+			f.sendJSON(c, DepthMessage{
+				Type:     TypeSnapshot,
+				Seq:      f.seq,
+				Asks:     f.book.Asks,
+				Bids:     f.book.Bids,
+				Checksum: f.book.Checksum(0),
+			})
+		}
+		c.depth = subscribe
+
+	case ChannelTrades:
+		c.trades = subscribe
+
+	default:
+		f.sendJSON(c, ErrorMessage{Type: TypeError, Error: "Unknown channel"})
+	}
+}
+
+// write delivers queued frames and closes the connection when the client
+// is removed
+func write(c *client) {
+	defer c.conn.close()
+
+	for fr := range c.out {
+		if err := c.conn.writeFrame(fr.op, fr.data); err != nil {
+			return
+		}
+	}
+
+	_ = c.conn.writeFrame(opClose, nil)
+}
+
+func (f *Feed) format(v fastme.Value) string {
+	switch {
+	case v == nil:
+		return ""
+	case f.cfg.Formatter == nil:
+		return v.Hash()
+	default:
+		return f.cfg.Formatter.Format(v)
+	}
+}
@@ -0,0 +1,289 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/newity/fastme"
+)
+
+type tValue float64
+
+func (t tValue) Add(n fastme.Value) fastme.Value { return t + t.checkNil(n) }
+func (t tValue) Sub(n fastme.Value) fastme.Value { return t - t.checkNil(n) }
+func (t tValue) Mul(n fastme.Value) fastme.Value { return t * t.checkNil(n) }
+func (t tValue) Hash() string                    { return strconv.FormatFloat(float64(t), 'f', -1, 64) }
+
+func (t tValue) Cmp(n fastme.Value) int {
+	switch v := t.checkNil(n); {
+	case t > v:
+		return 1
+	case t < v:
+		return -1
+	}
+	return 0
+}
+
+func (t tValue) Sign() int {
+	return t.Cmp(tValue(0))
+}
+
+func (t tValue) checkNil(v fastme.Value) tValue {
+	if v == nil {
+		return 0
+	}
+	return v.(tValue)
+}
+
+type tWallet struct {
+	values map[string]fastme.Value
+}
+
+func (t *tWallet) get(key string) fastme.Value {
+	if v, ok := t.values[key]; ok {
+		return v
+	}
+	return tValue(0)
+}
+
+func (t *tWallet) Balance(ctx context.Context, a fastme.Asset) fastme.Value {
+	return t.get("balance/" + string(a))
+}
+
+func (t *tWallet) UpdateBalance(ctx context.Context, a fastme.Asset, v fastme.Value) {
+	t.values["balance/"+string(a)] = v
+}
+
+func (t *tWallet) InOrder(ctx context.Context, a fastme.Asset) fastme.Value {
+	return t.get("in_order/" + string(a))
+}
+
+func (t *tWallet) UpdateInOrder(ctx context.Context, a fastme.Asset, v fastme.Value) {
+	t.values["in_order/"+string(a)] = v
+}
+
+type tOrder struct {
+	id              string
+	owner           fastme.Wallet
+	sell            bool
+	price, quantity fastme.Value
+}
+
+func (t *tOrder) ID() string                    { return t.id }
+func (t *tOrder) Owner() fastme.Wallet          { return t.owner }
+func (t *tOrder) Sell() bool                    { return t.sell }
+func (t *tOrder) Price() fastme.Value           { return t.price }
+func (t *tOrder) Quantity() fastme.Value        { return t.quantity }
+func (t *tOrder) UpdateQuantity(v fastme.Value) { t.quantity = v }
+
+type tClient struct {
+	c net.Conn
+	r *bufio.Reader
+}
+
+func dial(t *testing.T, url string) *tClient {
+	c, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.WriteString(c, "GET / HTTP/1.1\r\n"+
+		"Host: localhost\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(c)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Accept key of the sample nonce from RFC 6455
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("invalid handshake response")
+	}
+
+	return &tClient{c: c, r: r}
+}
+
+func (c *tClient) send(t *testing.T, req Request) {
+	payload, _ := json.Marshal(req)
+	mask := []byte{1, 2, 3, 4}
+
+	frame := []byte{0x80 | opText, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	if _, err := c.c.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *tClient) read(t *testing.T) map[string]interface{} {
+	_ = c.c.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	size := int(header[1] & 0x7F)
+	if size == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			t.Fatal(err)
+		}
+		size = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestFeed(t *testing.T) {
+	var (
+		engine = fastme.NewEngine("apples", "dollars")
+		feed   = NewFeed(engine, Config{})
+		server = httptest.NewServer(feed)
+		seller = &tWallet{values: map[string]fastme.Value{"balance/apples": tValue(2)}}
+		buyer  = &tWallet{values: map[string]fastme.Value{"balance/dollars": tValue(100)}}
+	)
+	defer server.Close()
+	defer feed.Close()
+
+	assertErr := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertErr(engine.PlaceOrder(context.Background(), feed, &tOrder{
+		id: "1", owner: seller, sell: true, price: tValue(10), quantity: tValue(2),
+	}))
+
+	client := dial(t, server.URL)
+	client.send(t, Request{Op: OpSubscribe, Channel: ChannelTrades})
+	client.send(t, Request{Op: OpSubscribe, Channel: ChannelDepth})
+
+	// Snapshot is consistent with the following deltas
+	var (
+		snapshot = client.read(t)
+		seq      = snapshot["seq"].(float64)
+		asks     = snapshot["asks"].([]interface{})
+	)
+
+	if snapshot["type"] != TypeSnapshot || len(asks) != 1 || asks[0].([]interface{})[1] != "2" {
+		t.Fatal("snapshot must contain resting order")
+	}
+
+	assertErr(engine.PlaceOrder(context.Background(), feed, &tOrder{
+		id: "2", owner: buyer, sell: false, price: tValue(10), quantity: tValue(1),
+	}))
+
+	var traded, updated bool
+	for !traded || !updated {
+		switch msg := client.read(t); msg["type"] {
+		case TypeTrade:
+			if msg["maker_order_id"] != "1" || msg["quantity"] != "1" {
+				t.Fatal("invalid trade message")
+			}
+			traded = true
+
+		case TypeDepth:
+			if seq++; msg["seq"].(float64) != seq {
+				t.Fatal("depth deltas must be sequenced")
+			}
+
+			asks := msg["asks"].([]interface{})
+			updated = len(asks) == 1 && asks[0].([]interface{})[1] == "1"
+
+			if updated && uint32(msg["checksum"].(float64)) != engine.Checksum(0) {
+				t.Fatal("depth checksum must match the engine book")
+			}
+
+		default:
+			t.Fatal("unexpected message", msg)
+		}
+	}
+
+	client.send(t, Request{Op: OpSubscribe, Channel: "orders"})
+	if msg := client.read(t); msg["type"] != TypeError {
+		t.Fatal("unknown channel must be rejected")
+	}
+}
+
+func TestFeedReduced(t *testing.T) {
+	var (
+		engine = fastme.NewEngine("apples", "dollars", fastme.WithoutAccounting())
+		feed   = NewFeed(engine, Config{})
+		server = httptest.NewServer(feed)
+		seller = &tWallet{values: map[string]fastme.Value{}}
+	)
+	defer server.Close()
+	defer feed.Close()
+
+	if err := engine.PlaceOrder(context.Background(), feed, &tOrder{
+		id: "1", owner: seller, sell: true, price: tValue(10), quantity: tValue(2),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := dial(t, server.URL)
+	client.send(t, Request{Op: OpSubscribe, Channel: ChannelDepth})
+
+	if msg := client.read(t); msg["type"] != TypeSnapshot {
+		t.Fatal("snapshot must be sent first", msg)
+	}
+
+	// Reductions without wallet accounting are reported by the reduce event only
+	if err := engine.ReduceOrder(context.Background(), feed, "1", tValue(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := client.read(t)
+	asks, _ := msg["asks"].([]interface{})
+	if msg["type"] != TypeDepth || len(asks) != 1 || asks[0].([]interface{})[1] != "1" {
+		t.Fatal("reduced order must be published", msg)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	levels := diff(
+		[][2]string{{"10", "1"}, {"11", "2"}},
+		[][2]string{{"10", "1"}, {"11", "3"}, {"12", "1"}},
+	)
+
+	if len(levels) != 2 || levels[0] != [2]string{"11", "3"} || levels[1] != [2]string{"12", "1"} {
+		t.Fatal("changed levels must be returned")
+	}
+
+	levels = diff([][2]string{{"10", "1"}}, [][2]string{})
+	if len(levels) != 1 || levels[0] != [2]string{"10", "0"} {
+		t.Fatal("removed levels must have zero quantity")
+	}
+}